@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -47,6 +48,46 @@ type Config struct {
 	PTDefaultChunkSize    int    `json:"pt_default_chunk_size"`
 	PTDefaultMaxLoad      string `json:"pt_default_max_load"`
 	PTDefaultCriticalLoad string `json:"pt_default_critical_load"`
+	PTHistory             bool   `json:"pt_history"` // 生成命令时启用 --history，记录复制进度以便续跑（需 pt-osc 3.6+ 及 percona 库写权限，默认关闭）
+
+	// 旧表保留配置
+	OldTableRetention       time.Duration `json:"old_table_retention"`        // 默认保留时长
+	OldTableJanitorInterval time.Duration `json:"old_table_janitor_interval"` // 过期旧表清理间隔
+
+	// 删除列数据备份目录
+	BackupDir string `json:"backup_dir"`
+
+	// 上传的迁移包解压目录
+	MigrationDir string `json:"migration_dir"`
+	// 允许登记为迁移来源的服务器目录（为空时仅允许 MigrationDir 下的目录）
+	MigrationSourceRoots []string `json:"migration_source_roots"`
+
+	// 变更发布的环境顺序（如 dev → test → prod）
+	PromotionEnvironments []string `json:"promotion_environments"`
+
+	// 执行健康检查（停滞/降速告警）
+	WatchdogInterval     time.Duration `json:"watchdog_interval"`      // 检查间隔
+	StallLogTimeout      time.Duration `json:"stall_log_timeout"`      // 无日志输出超过该时长视为停滞
+	StallProgressTimeout time.Duration `json:"stall_progress_timeout"` // 进度不变超过该时长视为卡住
+	SlowdownRatio        float64       `json:"slowdown_ratio"`         // 速度低于移动平均的该比例视为降速
+
+	// 执行期间目标实例负载采样间隔（0 关闭）
+	LoadSampleInterval time.Duration `json:"load_sample_interval"`
+
+	// 长事务保护：长事务判定时长与切换前最长等待时间
+	LongTransactionThreshold time.Duration `json:"long_transaction_threshold"`
+	CutoverWaitTimeout       time.Duration `json:"cutover_wait_timeout"`
+
+	// 执行后校验：新旧表校验和比较的分块行数
+	VerifyChecksumChunkSize int `json:"verify_checksum_chunk_size"`
+
+	// 执行日志保留：超过 LogCompressAfter 的日志分块压缩，超过 LogRetention 的删除（0 不删除）
+	LogCompressAfter   time.Duration `json:"log_compress_after"`
+	LogRetention       time.Duration `json:"log_retention"`
+	LogJanitorInterval time.Duration `json:"log_janitor_interval"`
+
+	// 告警通知 Webhook（为空时不发送）
+	AlertWebhookURL string `json:"alert_webhook_url"`
 }
 
 // Load 加载配置
@@ -85,6 +126,35 @@ func Load() *Config {
 		PTDefaultChunkSize:    getEnvAsInt("PT_DEFAULT_CHUNK_SIZE", 1000),
 		PTDefaultMaxLoad:      getEnv("PT_DEFAULT_MAX_LOAD", "Threads_running=25"),
 		PTDefaultCriticalLoad: getEnv("PT_DEFAULT_CRITICAL_LOAD", "Threads_running=50"),
+		PTHistory:             getEnvAsBool("PT_HISTORY", false),
+
+		OldTableRetention:       getEnvAsDuration("OLD_TABLE_RETENTION", 72*time.Hour),
+		OldTableJanitorInterval: getEnvAsDuration("OLD_TABLE_JANITOR_INTERVAL", 10*time.Minute),
+
+		BackupDir: getEnv("BACKUP_DIR", "./data/backups"),
+
+		MigrationDir:         getEnv("MIGRATION_DIR", "./data/migrations"),
+		MigrationSourceRoots: getEnvAsSlice("MIGRATION_SOURCE_ROOTS", nil),
+
+		PromotionEnvironments: getEnvAsSlice("PROMOTION_ENVIRONMENTS", []string{"dev", "test", "prod"}),
+
+		WatchdogInterval:     getEnvAsDuration("WATCHDOG_INTERVAL", 30*time.Second),
+		StallLogTimeout:      getEnvAsDuration("STALL_LOG_TIMEOUT", 10*time.Minute),
+		StallProgressTimeout: getEnvAsDuration("STALL_PROGRESS_TIMEOUT", 15*time.Minute),
+		SlowdownRatio:        getEnvAsFloat("SLOWDOWN_RATIO", 0.3),
+
+		LoadSampleInterval: getEnvAsDuration("LOAD_SAMPLE_INTERVAL", 5*time.Second),
+
+		LongTransactionThreshold: getEnvAsDuration("LONG_TRANSACTION_THRESHOLD", time.Minute),
+		CutoverWaitTimeout:       getEnvAsDuration("CUTOVER_WAIT_TIMEOUT", 10*time.Minute),
+
+		VerifyChecksumChunkSize: getEnvAsInt("VERIFY_CHECKSUM_CHUNK_SIZE", 10000),
+
+		LogCompressAfter:   getEnvAsDuration("LOG_COMPRESS_AFTER", 7*24*time.Hour),
+		LogRetention:       getEnvAsDuration("LOG_RETENTION", 180*24*time.Hour),
+		LogJanitorInterval: getEnvAsDuration("LOG_JANITOR_INTERVAL", time.Hour),
+
+		AlertWebhookURL: getEnv("ALERT_WEBHOOK_URL", ""),
 	}
 
 	return config
@@ -118,6 +188,16 @@ func getEnvAsBool(key string, defaultValue bool) bool {
 	return defaultValue
 }
 
+// getEnvAsFloat 获取环境变量并转换为浮点数
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
 // getEnvAsDuration 获取环境变量并转换为时间间隔
 func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
@@ -127,3 +207,19 @@ func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	}
 	return defaultValue
 }
+
+// getEnvAsSlice 获取环境变量并按逗号拆分
+func getEnvAsSlice(key string, defaultValue []string) []string {
+	if value := os.Getenv(key); value != "" {
+		var result []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				result = append(result, item)
+			}
+		}
+		if len(result) > 0 {
+			return result
+		}
+	}
+	return defaultValue
+}
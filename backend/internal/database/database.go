@@ -2,6 +2,7 @@ package database
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/fengzhencai/MySQLer/backend/internal/config"
@@ -59,6 +60,11 @@ func Initialize(cfg *config.Config) (*gorm.DB, error) {
 		return nil, fmt.Errorf("failed to fix schema: %w", err)
 	}
 
+	// 扩展执行状态枚举（AutoMigrate 不会修改已有的 enum 定义）
+	if err := ensureExecutionStatusEnum(db, cfg.DBName); err != nil {
+		return nil, fmt.Errorf("failed to extend execution status: %w", err)
+	}
+
 	return db, nil
 }
 
@@ -69,6 +75,28 @@ func AutoMigrate(db *gorm.DB) error {
 		&models.Connection{},
 		&models.ExecutionRecord{},
 		&models.AuditLog{},
+		&models.ConnectionGroup{},
+		&models.ConnectionGroupMember{},
+		&models.BatchExecution{},
+		&models.SchemaSnapshot{},
+		&models.ColumnBackup{},
+		&models.ColumnRestoreJob{},
+		&models.DriftCheck{},
+		&models.DriftReport{},
+		&models.SchemaChange{},
+		&models.ChangeStage{},
+		&models.MigrationSource{},
+		&models.MigrationLedger{},
+		&models.LintRuleSetting{},
+		&models.ExecutionProgressSample{},
+		&models.ExecutionLoadSample{},
+		&models.Permission{},
+		&models.RolePermission{},
+		&models.UserPermission{},
+		&models.RetryPolicy{},
+		&models.ExecutionAttempt{},
+		&models.ExecutionEvent{},
+		&models.ExecutionLogChunk{},
 	)
 }
 
@@ -107,3 +135,19 @@ func fixExecutionRecordsSchema(db *gorm.DB, dbName string) error {
 	}
 	return nil
 }
+
+// ensureExecutionStatusEnum 为旧库的 execution_records.status 补充 completed_with_warnings
+func ensureExecutionStatusEnum(db *gorm.DB, dbName string) error {
+	var columnType string
+	if err := db.Raw(
+		"SELECT COLUMN_TYPE FROM information_schema.COLUMNS WHERE TABLE_SCHEMA=? AND TABLE_NAME='execution_records' AND COLUMN_NAME='status'",
+		dbName,
+	).Scan(&columnType).Error; err != nil {
+		return err
+	}
+	if columnType == "" || strings.Contains(columnType, "'completed_with_warnings'") {
+		return nil
+	}
+	return db.Exec("ALTER TABLE execution_records MODIFY COLUMN status " +
+		"enum('pending','running','completed','completed_with_warnings','failed','cancelled') DEFAULT 'pending'").Error
+}
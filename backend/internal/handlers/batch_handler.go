@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/fengzhencai/MySQLer/backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// BatchHandler 连接分组与批量执行处理器
+type BatchHandler struct {
+	batchService *services.BatchService
+	auditService *services.AuditService
+}
+
+// NewBatchHandler 创建批量执行处理器
+func NewBatchHandler(batchService *services.BatchService, auditService *services.AuditService) *BatchHandler {
+	return &BatchHandler{
+		batchService: batchService,
+		auditService: auditService,
+	}
+}
+
+// ListGroups 获取分组列表
+func (h *BatchHandler) ListGroups(c *gin.Context) {
+	groups, err := h.batchService.ListGroups()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "Failed to get connection groups",
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Success",
+		"data":    groups,
+	})
+}
+
+// CreateGroup 创建分组
+func (h *BatchHandler) CreateGroup(c *gin.Context) {
+	var req services.CreateGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request parameters",
+			"data":    nil,
+		})
+		return
+	}
+
+	group, err := h.batchService.CreateGroup(&req, c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Connection group created successfully",
+		"data":    group,
+	})
+}
+
+// GetGroup 根据ID获取分组
+func (h *BatchHandler) GetGroup(c *gin.Context) {
+	group, err := h.batchService.GetGroup(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Success",
+		"data":    group,
+	})
+}
+
+// UpdateGroup 更新分组
+func (h *BatchHandler) UpdateGroup(c *gin.Context) {
+	var req services.CreateGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request parameters",
+			"data":    nil,
+		})
+		return
+	}
+
+	group, err := h.batchService.UpdateGroup(c.Param("id"), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Connection group updated successfully",
+		"data":    group,
+	})
+}
+
+// DeleteGroup 删除分组
+func (h *BatchHandler) DeleteGroup(c *gin.Context) {
+	if err := h.batchService.DeleteGroup(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Connection group deleted successfully",
+		"data":    nil,
+	})
+}
+
+// ListBatches 获取批量执行列表
+func (h *BatchHandler) ListBatches(c *gin.Context) {
+	batches, err := h.batchService.ListBatches()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "Failed to get batch executions",
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Success",
+		"data":    batches,
+	})
+}
+
+// CreateBatch 创建批量执行
+func (h *BatchHandler) CreateBatch(c *gin.Context) {
+	var req services.CreateBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request parameters",
+			"data":    nil,
+		})
+		return
+	}
+
+	batch, err := h.batchService.CreateBatch(&req, c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Batch execution created successfully",
+		"data":    batch,
+	})
+}
+
+// CreateFragmentBatch 根据碎片扫描结果创建批量重建
+func (h *BatchHandler) CreateFragmentBatch(c *gin.Context) {
+	var req services.CreateFragmentBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request parameters",
+			"data":    nil,
+		})
+		return
+	}
+
+	batch, err := h.batchService.CreateFragmentBatch(&req, c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Fragment batch created successfully",
+		"data":    batch,
+	})
+}
+
+// GetBatch 获取批量执行详情（含子执行与聚合进度）
+func (h *BatchHandler) GetBatch(c *gin.Context) {
+	batch, err := h.batchService.GetBatch(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Success",
+		"data":    batch,
+	})
+}
+
+// StartBatch 启动批量执行
+func (h *BatchHandler) StartBatch(c *gin.Context) {
+	if err := h.batchService.StartBatch(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Batch execution started successfully",
+		"data":    nil,
+	})
+}
+
+// CancelBatch 取消批量执行
+func (h *BatchHandler) CancelBatch(c *gin.Context) {
+	if err := h.batchService.CancelBatch(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Batch execution cancelled successfully",
+		"data":    nil,
+	})
+}
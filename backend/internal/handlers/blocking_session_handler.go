@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/fengzhencai/MySQLer/backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// BlockingSessionHandler 阻塞会话处理器
+type BlockingSessionHandler struct {
+	blockingSessionService *services.BlockingSessionService
+}
+
+// NewBlockingSessionHandler 创建阻塞会话处理器
+func NewBlockingSessionHandler(blockingSessionService *services.BlockingSessionService) *BlockingSessionHandler {
+	return &BlockingSessionHandler{
+		blockingSessionService: blockingSessionService,
+	}
+}
+
+// List 列出阻塞执行目标表切换的会话
+func (h *BlockingSessionHandler) List(c *gin.Context) {
+	sessions, err := h.blockingSessionService.List(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Success",
+		"data":    sessions,
+	})
+}
+
+// Kill 终止选中的阻塞会话（需危险操作权限并填写原因）
+func (h *BlockingSessionHandler) Kill(c *gin.Context) {
+	var req services.KillSessionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请选择要终止的会话并填写原因: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	results, err := h.blockingSessionService.Kill(c.Param("id"), &req, c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Kill requests processed",
+		"data":    results,
+	})
+}
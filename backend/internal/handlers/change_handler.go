@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fengzhencai/MySQLer/backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// ChangeHandler 跨环境变更发布处理器
+type ChangeHandler struct {
+	changeService *services.ChangeService
+}
+
+// NewChangeHandler 创建变更发布处理器
+func NewChangeHandler(changeService *services.ChangeService) *ChangeHandler {
+	return &ChangeHandler{
+		changeService: changeService,
+	}
+}
+
+// ListChanges 获取变更列表
+func (h *ChangeHandler) ListChanges(c *gin.Context) {
+	changes, err := h.changeService.ListChanges()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "Failed to get changes",
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Success",
+		"data":    changes,
+	})
+}
+
+// CreateChange 创建变更
+func (h *ChangeHandler) CreateChange(c *gin.Context) {
+	var req services.CreateChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request parameters",
+			"data":    nil,
+		})
+		return
+	}
+
+	change, err := h.changeService.CreateChange(&req, c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Change created successfully",
+		"data":    change,
+	})
+}
+
+// GetChange 获取变更详情
+func (h *ChangeHandler) GetChange(c *gin.Context) {
+	change, err := h.changeService.GetChange(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Success",
+		"data":    change,
+	})
+}
+
+// Promote 启动下一个发布阶段
+func (h *ChangeHandler) Promote(c *gin.Context) {
+	change, err := h.changeService.Promote(c.Param("id"), c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Stage started successfully",
+		"data":    change,
+	})
+}
+
+// VerifyStage 校验阶段表结构
+func (h *ChangeHandler) VerifyStage(c *gin.Context) {
+	order, err := strconv.Atoi(c.Param("order"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid stage order",
+			"data":    nil,
+		})
+		return
+	}
+
+	stage, err := h.changeService.VerifyStage(c.Param("id"), order)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Success",
+		"data":    stage,
+	})
+}
+
+// Cancel 取消变更
+func (h *ChangeHandler) Cancel(c *gin.Context) {
+	if err := h.changeService.Cancel(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Change cancelled successfully",
+		"data":    nil,
+	})
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/fengzhencai/MySQLer/backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// ColumnBackupHandler 删除列数据备份处理器
+type ColumnBackupHandler struct {
+	backupService *services.ColumnBackupService
+}
+
+// NewColumnBackupHandler 创建列备份处理器
+func NewColumnBackupHandler(backupService *services.ColumnBackupService) *ColumnBackupHandler {
+	return &ColumnBackupHandler{
+		backupService: backupService,
+	}
+}
+
+// GetBackup 获取执行的列数据备份信息
+func (h *ColumnBackupHandler) GetBackup(c *gin.Context) {
+	backup, err := h.backupService.GetBackup(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Success",
+		"data":    backup,
+	})
+}
+
+// Restore 创建列数据恢复任务
+func (h *ColumnBackupHandler) Restore(c *gin.Context) {
+	var req services.RestoreColumnsRequest
+	if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request parameters",
+			"data":    nil,
+		})
+		return
+	}
+
+	job, err := h.backupService.StartRestore(c.Param("id"), &req, c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Restore job created successfully",
+		"data":    job,
+	})
+}
+
+// ListRestoreJobs 获取恢复任务列表
+func (h *ColumnBackupHandler) ListRestoreJobs(c *gin.Context) {
+	jobs, err := h.backupService.ListRestoreJobs(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "Failed to get restore jobs",
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Success",
+		"data":    jobs,
+	})
+}
@@ -263,3 +263,51 @@ func (h *ConnectionHandler) GetTableSchema(c *gin.Context) {
 		"data":    schema,
 	})
 }
+
+// AnalyzeIndexes 分析指定表的索引（重复、冗余、未使用）
+func (h *ConnectionHandler) AnalyzeIndexes(c *gin.Context) {
+	analysis, err := h.connectionService.AnalyzeIndexes(c.Param("id"), c.Param("database"), c.Param("table"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Success",
+		"data":    analysis,
+	})
+}
+
+// ScanFragmentation 扫描连接下的表碎片，按可回收空间排序
+func (h *ConnectionHandler) ScanFragmentation(c *gin.Context) {
+	var req services.FragmentScanRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request parameters",
+			"data":    nil,
+		})
+		return
+	}
+
+	result, err := h.connectionService.ScanFragmentation(c.Param("id"), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Success",
+		"data":    result,
+	})
+}
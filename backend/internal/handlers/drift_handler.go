@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fengzhencai/MySQLer/backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// DriftHandler 结构漂移检测处理器
+type DriftHandler struct {
+	driftService *services.DriftService
+}
+
+// NewDriftHandler 创建漂移检测处理器
+func NewDriftHandler(driftService *services.DriftService) *DriftHandler {
+	return &DriftHandler{
+		driftService: driftService,
+	}
+}
+
+// Compare 临时对比两个库的结构
+func (h *DriftHandler) Compare(c *gin.Context) {
+	var req services.DriftCompareRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request parameters",
+			"data":    nil,
+		})
+		return
+	}
+
+	result, err := h.driftService.Compare(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Success",
+		"data":    result,
+	})
+}
+
+// ListChecks 获取漂移检查列表
+func (h *DriftHandler) ListChecks(c *gin.Context) {
+	checks, err := h.driftService.ListChecks()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "Failed to get drift checks",
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Success",
+		"data":    checks,
+	})
+}
+
+// CreateCheck 创建漂移检查
+func (h *DriftHandler) CreateCheck(c *gin.Context) {
+	var req services.CreateDriftCheckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request parameters",
+			"data":    nil,
+		})
+		return
+	}
+
+	check, err := h.driftService.CreateCheck(&req, c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Drift check created successfully",
+		"data":    check,
+	})
+}
+
+// GetCheck 获取漂移检查
+func (h *DriftHandler) GetCheck(c *gin.Context) {
+	check, err := h.driftService.GetCheck(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Success",
+		"data":    check,
+	})
+}
+
+// UpdateCheck 更新漂移检查
+func (h *DriftHandler) UpdateCheck(c *gin.Context) {
+	var req services.CreateDriftCheckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request parameters",
+			"data":    nil,
+		})
+		return
+	}
+
+	check, err := h.driftService.UpdateCheck(c.Param("id"), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Drift check updated successfully",
+		"data":    check,
+	})
+}
+
+// DeleteCheck 删除漂移检查
+func (h *DriftHandler) DeleteCheck(c *gin.Context) {
+	if err := h.driftService.DeleteCheck(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Drift check deleted successfully",
+		"data":    nil,
+	})
+}
+
+// RunCheck 立即执行漂移检查
+func (h *DriftHandler) RunCheck(c *gin.Context) {
+	result, err := h.driftService.RunCheck(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Success",
+		"data":    result,
+	})
+}
+
+// ListReports 获取漂移检查的历史报告
+func (h *DriftHandler) ListReports(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	reports, err := h.driftService.ListReports(c.Param("id"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "Failed to get drift reports",
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Success",
+		"data":    reports,
+	})
+}
+
+// GetReport 获取漂移报告详情
+func (h *DriftHandler) GetReport(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid report ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	result, err := h.driftService.GetReport(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Success",
+		"data":    result,
+	})
+}
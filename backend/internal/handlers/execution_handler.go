@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -41,13 +42,15 @@ func (h *ExecutionHandler) List(c *gin.Context) {
 	}
 
 	params := map[string]interface{}{
-		"status":        c.Query("status"),
-		"connection_id": c.Query("connection_id"),
-		"start_date":    c.Query("start_date"),
-		"end_date":      c.Query("end_date"),
-		"keyword":       c.Query("keyword"),
-		"page":          page,
-		"size":          size,
+		"status":           c.Query("status"),
+		"connection_id":    c.Query("connection_id"),
+		"batch_id":         c.Query("batch_id"),
+		"failure_category": c.Query("failure_category"),
+		"start_date":       c.Query("start_date"),
+		"end_date":         c.Query("end_date"),
+		"keyword":          c.Query("keyword"),
+		"page":             page,
+		"size":             size,
 	}
 
 	records, total, err := h.executionService.ListWithFilters(params)
@@ -125,13 +128,14 @@ func (h *ExecutionHandler) GetByID(c *gin.Context) {
 // Stop 停止执行
 func (h *ExecutionHandler) Stop(c *gin.Context) {
 	id := c.Param("id")
+	userID := c.GetString("user_id")
 
 	// 先尝试通过引擎停止运行中的任务
-	if err := h.executionEngine.StopExecution(id); err != nil {
+	if err := h.executionEngine.StopExecution(id, userID); err != nil {
 		// 引擎未在运行或停止失败则继续走服务层兜底
 	}
 
-	err := h.executionService.Stop(id)
+	err := h.executionService.Stop(id, userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
@@ -153,11 +157,24 @@ func (h *ExecutionHandler) Retry(c *gin.Context) {
 	id := c.Param("id")
 	userID := c.GetString("user_id")
 
-	err := h.executionService.Retry(id, userID)
+	// 请求体可选，未指定 mode 时从头执行
+	var req services.RetryRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": "Invalid request parameters",
+				"data":    nil,
+			})
+			return
+		}
+	}
+
+	err := h.executionService.Retry(id, userID, req.Mode)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "Failed to retry execution",
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
 			"data":    nil,
 		})
 		return
@@ -170,15 +187,169 @@ func (h *ExecutionHandler) Retry(c *gin.Context) {
 	})
 }
 
-// GetLogs 获取执行日志
-func (h *ExecutionHandler) GetLogs(c *gin.Context) {
+// FailureCategories 失败分类及处理建议（用于历史筛选）
+func (h *ExecutionHandler) FailureCategories(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Failure categories retrieved successfully",
+		"data":    h.executionService.FailureCategories(),
+	})
+}
+
+// ListAttempts 获取执行记录的全部执行尝试
+func (h *ExecutionHandler) ListAttempts(c *gin.Context) {
+	attempts, err := h.executionService.ListAttempts(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Execution attempts retrieved successfully",
+		"data":    attempts,
+	})
+}
+
+// GetTimeline 获取执行记录的时间线（执行尝试与状态变更）
+func (h *ExecutionHandler) GetTimeline(c *gin.Context) {
+	timeline, err := h.executionService.GetTimeline(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Execution timeline retrieved successfully",
+		"data":    timeline,
+	})
+}
+
+// GetAttempt 获取单次执行尝试
+func (h *ExecutionHandler) GetAttempt(c *gin.Context) {
+	attempt, err := strconv.Atoi(c.Param("attempt"))
+	if err != nil || attempt < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid attempt number",
+			"data":    nil,
+		})
+		return
+	}
+
+	result, err := h.executionService.GetAttempt(c.Param("id"), attempt)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Execution attempt retrieved successfully",
+		"data":    result,
+	})
+}
+
+// ResumeCheck 检查失败的执行能否从中断处续跑
+func (h *ExecutionHandler) ResumeCheck(c *gin.Context) {
+	state, err := h.executionService.ResumeCheck(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Resume state retrieved successfully",
+		"data":    state,
+	})
+}
+
+// Revert 回滚已完成的执行（创建新的关联执行）
+func (h *ExecutionHandler) Revert(c *gin.Context) {
 	id := c.Param("id")
+	userID := c.GetString("user_id")
 
-	logs, err := h.executionService.GetLogs(id)
+	record, err := h.executionService.Revert(id, userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "Failed to get execution logs",
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Revert execution created successfully",
+		"data":    record,
+	})
+}
+
+// Declarative 提交目标建表语句，生成 ALTER 并可直接预览或创建执行
+func (h *ExecutionHandler) Declarative(c *gin.Context) {
+	var req services.DeclarativeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request parameters",
+			"data":    nil,
+		})
+		return
+	}
+
+	result, err := h.executionService.Declarative(&req, c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Success",
+		"data":    result,
+	})
+}
+
+// GetLogs 分页获取执行日志（offset/limit 分页，search 为正则，attempt 指定执行次数）
+func (h *ExecutionHandler) GetLogs(c *gin.Context) {
+	var query services.LogQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid query parameters",
+			"data":    nil,
+		})
+		return
+	}
+
+	page, err := h.executionService.GetLogs(c.Param("id"), &query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
 			"data":    nil,
 		})
 		return
@@ -187,10 +358,41 @@ func (h *ExecutionHandler) GetLogs(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
 		"message": "Success",
-		"data":    gin.H{"logs": logs},
+		"data":    page,
 	})
 }
 
+// DownloadLogs 下载纯文本执行日志（支持与分页查询相同的过滤条件）
+func (h *ExecutionHandler) DownloadLogs(c *gin.Context) {
+	var query services.LogQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid query parameters",
+			"data":    nil,
+		})
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := h.executionService.GetByID(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "Execution not found",
+			"data":    nil,
+		})
+		return
+	}
+
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="execution-%s.log"`, id))
+	c.Status(http.StatusOK)
+	if err := h.executionService.DownloadLogs(id, &query, c.Writer); err != nil {
+		// 响应头已发送，只能在内容末尾标注错误
+		fmt.Fprintf(c.Writer, "\n[日志下载中断: %v]\n", err)
+	}
+}
+
 // PreviewCommand 预览PT命令
 func (h *ExecutionHandler) PreviewCommand(c *gin.Context) {
 	var req services.PreviewCommandRequest
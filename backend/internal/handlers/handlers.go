@@ -3,6 +3,7 @@ package handlers
 import (
 	"github.com/fengzhencai/MySQLer/backend/internal/config"
 	"github.com/fengzhencai/MySQLer/backend/internal/middleware"
+	"github.com/fengzhencai/MySQLer/backend/internal/models"
 	"github.com/fengzhencai/MySQLer/backend/internal/services"
 	"github.com/gin-gonic/gin"
 )
@@ -52,6 +53,11 @@ func RegisterRoutes(router *gin.Engine, services *services.Services, cfg *config
 
 		// DDL执行
 		executionHandler := NewExecutionHandler(services.Execution, services.ExecutionEngine, services.Audit)
+		snapshotHandler := NewSnapshotHandler(services.Snapshot)
+		oldTableHandler := NewOldTableHandler(services.OldTable)
+		columnBackupHandler := NewColumnBackupHandler(services.ColumnBackup)
+		progressHandler := NewProgressHandler(services.Progress)
+		blockingSessionHandler := NewBlockingSessionHandler(services.BlockingSession)
 		executionGroup := authenticated.Group("/executions")
 		{
 			executionGroup.GET("", executionHandler.List)
@@ -59,19 +65,115 @@ func RegisterRoutes(router *gin.Engine, services *services.Services, cfg *config
 			executionGroup.GET("/:id", executionHandler.GetByID)
 			executionGroup.POST("/:id/stop", executionHandler.Stop)
 			executionGroup.POST("/:id/retry", executionHandler.Retry)
+			executionGroup.GET("/:id/resume-check", executionHandler.ResumeCheck)
+			executionGroup.GET("/:id/attempts", executionHandler.ListAttempts)
+			executionGroup.GET("/:id/attempts/:attempt", executionHandler.GetAttempt)
+			executionGroup.GET("/:id/timeline", executionHandler.GetTimeline)
+			executionGroup.POST("/:id/revert", executionHandler.Revert)
+			executionGroup.POST("/:id/swap-back",
+				middleware.RequirePermission(services.Permission, models.PermissionDangerousOperations),
+				oldTableHandler.SwapBack)
+			executionGroup.GET("/:id/column-backup", columnBackupHandler.GetBackup)
+			executionGroup.POST("/:id/column-backup/restore",
+				middleware.RequirePermission(services.Permission, models.PermissionDangerousOperations),
+				columnBackupHandler.Restore)
+			executionGroup.GET("/:id/column-backup/restores", columnBackupHandler.ListRestoreJobs)
 			executionGroup.GET("/:id/logs", executionHandler.GetLogs)
+			executionGroup.GET("/:id/logs/download", executionHandler.DownloadLogs)
+			executionGroup.GET("/:id/progress-series", progressHandler.GetSeries)
+			executionGroup.GET("/:id/load-series", progressHandler.GetLoadSeries)
+			executionGroup.GET("/:id/blocking-sessions", blockingSessionHandler.List)
+			executionGroup.POST("/:id/blocking-sessions/kill",
+				middleware.RequirePermission(services.Permission, models.PermissionDangerousOperations),
+				blockingSessionHandler.Kill)
+			executionGroup.GET("/:id/snapshots", snapshotHandler.ListSnapshots)
+			executionGroup.GET("/:id/schema-diff", snapshotHandler.GetDiff)
 			executionGroup.POST("/preview", executionHandler.PreviewCommand)
+			executionGroup.POST("/declarative", executionHandler.Declarative)
 			executionGroup.POST("/:id/start", executionHandler.StartExecution)
 			executionGroup.GET("/:id/status", executionHandler.GetExecutionStatus)
 			executionGroup.GET("/running", executionHandler.GetRunningTasks)
+			executionGroup.GET("/failure-categories", executionHandler.FailureCategories)
 		}
 
+		// 连接分组与批量执行
+		batchHandler := NewBatchHandler(services.Batch, services.Audit)
+		groupGroup := authenticated.Group("/connection-groups")
+		{
+			groupGroup.GET("", batchHandler.ListGroups)
+			groupGroup.POST("", batchHandler.CreateGroup)
+			groupGroup.GET("/:id", batchHandler.GetGroup)
+			groupGroup.PUT("/:id", batchHandler.UpdateGroup)
+			groupGroup.DELETE("/:id", batchHandler.DeleteGroup)
+		}
+		batchGroup := authenticated.Group("/batch-executions")
+		{
+			batchGroup.GET("", batchHandler.ListBatches)
+			batchGroup.POST("", batchHandler.CreateBatch)
+			batchGroup.POST("/fragment", batchHandler.CreateFragmentBatch)
+			batchGroup.GET("/:id", batchHandler.GetBatch)
+			batchGroup.POST("/:id/start", batchHandler.StartBatch)
+			batchGroup.POST("/:id/cancel", batchHandler.CancelBatch)
+		}
+
+		// 跨环境变更发布
+		changeHandler := NewChangeHandler(services.Change)
+		changeGroup := authenticated.Group("/changes")
+		{
+			changeGroup.GET("", changeHandler.ListChanges)
+			changeGroup.POST("", changeHandler.CreateChange)
+			changeGroup.GET("/:id", changeHandler.GetChange)
+			changeGroup.POST("/:id/promote", changeHandler.Promote)
+			changeGroup.POST("/:id/cancel", changeHandler.Cancel)
+			changeGroup.POST("/:id/stages/:order/verify", changeHandler.VerifyStage)
+		}
+
+		// 版本化迁移导入
+		migrationHandler := NewMigrationHandler(services.Migration)
+		migrationGroup := authenticated.Group("/migration-sources")
+		{
+			migrationGroup.GET("", migrationHandler.ListSources)
+			migrationGroup.POST("", migrationHandler.CreateSource)
+			migrationGroup.POST("/upload", migrationHandler.UploadSource)
+			migrationGroup.GET("/:id", migrationHandler.GetSource)
+			migrationGroup.DELETE("/:id", migrationHandler.DeleteSource)
+			migrationGroup.GET("/:id/files", migrationHandler.ListFiles)
+			migrationGroup.GET("/:id/status", migrationHandler.GetStatus)
+			migrationGroup.POST("/:id/plan", migrationHandler.Plan)
+			migrationGroup.POST("/:id/baseline", migrationHandler.Baseline)
+		}
+
+		// 跨环境结构漂移检测
+		driftHandler := NewDriftHandler(services.Drift)
+		driftGroup := authenticated.Group("/drift")
+		{
+			driftGroup.POST("/compare", driftHandler.Compare)
+			driftGroup.GET("/checks", driftHandler.ListChecks)
+			driftGroup.POST("/checks", driftHandler.CreateCheck)
+			driftGroup.GET("/checks/:id", driftHandler.GetCheck)
+			driftGroup.PUT("/checks/:id", driftHandler.UpdateCheck)
+			driftGroup.DELETE("/checks/:id", driftHandler.DeleteCheck)
+			driftGroup.POST("/checks/:id/run", driftHandler.RunCheck)
+			driftGroup.GET("/checks/:id/reports", driftHandler.ListReports)
+			driftGroup.GET("/reports/:id", driftHandler.GetReport)
+		}
+
+		// SQL审核规则（查看）
+		lintHandler := NewLintHandler(services.Lint)
+		authenticated.GET("/lint-rules/:environment", lintHandler.GetRules)
+
+		// 自动重试策略（查看）
+		retryPolicyHandler := NewRetryPolicyHandler(services.RetryPolicy)
+		authenticated.GET("/retry-policies/:environment", retryPolicyHandler.GetPolicy)
+
 		// 工具类接口
 		toolsGroup := authenticated.Group("/tools")
 		{
 			toolsGroup.GET("/connections/:id/databases", connectionHandler.GetDatabases)
 			toolsGroup.GET("/connections/:id/databases/:database/tables", connectionHandler.GetTables)
 			toolsGroup.GET("/connections/:id/databases/:database/tables/:table/schema", connectionHandler.GetTableSchema)
+			toolsGroup.GET("/connections/:id/databases/:database/tables/:table/indexes", connectionHandler.AnalyzeIndexes)
+			toolsGroup.GET("/connections/:id/fragmentation", connectionHandler.ScanFragmentation)
 		}
 
 		// 管理员路由
@@ -89,6 +191,10 @@ func RegisterRoutes(router *gin.Engine, services *services.Services, cfg *config
 				userGroup.DELETE("/:id", userHandler.Delete)
 			}
 
+			// SQL审核规则配置
+			adminGroup.PUT("/lint-rules/:environment", lintHandler.UpdateRules)
+			adminGroup.PUT("/retry-policies/:environment", retryPolicyHandler.UpdatePolicy)
+
 			// 审计日志
 			auditHandler := NewAuditHandler(services.Audit)
 			auditGroup := adminGroup.Group("/audit-logs")
@@ -114,12 +220,17 @@ func RegisterRoutes(router *gin.Engine, services *services.Services, cfg *config
 			if progressMsg, ok := progress.(map[string]interface{}); ok {
 				// 创建进度消息并广播
 				progressData := map[string]interface{}{
-					"execution_id":  progressMsg["execution_id"],
-					"status":        progressMsg["status"],
-					"progress":      progressMsg["progress"],
-					"current_speed": progressMsg["current_speed"],
-					"current_stage": progressMsg["current_stage"],
-					"timestamp":     progressMsg["timestamp"],
+					"execution_id":      progressMsg["execution_id"],
+					"status":            progressMsg["status"],
+					"progress":          progressMsg["progress"],
+					"current_speed":     progressMsg["current_speed"],
+					"current_stage":     progressMsg["current_stage"],
+					"throttled":         progressMsg["throttled"],
+					"health":            progressMsg["health"],
+					"health_reason":     progressMsg["health_reason"],
+					"server_load":       progressMsg["server_load"],
+					"transaction_guard": progressMsg["transaction_guard"],
+					"timestamp":         progressMsg["timestamp"],
 				}
 				wsHandler.BroadcastExecutionProgress(executionID, progressData)
 			}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/fengzhencai/MySQLer/backend/internal/models"
+	"github.com/fengzhencai/MySQLer/backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// LintHandler SQL审核规则处理器
+type LintHandler struct {
+	lintService *services.LintService
+}
+
+// NewLintHandler 创建SQL审核规则处理器
+func NewLintHandler(lintService *services.LintService) *LintHandler {
+	return &LintHandler{
+		lintService: lintService,
+	}
+}
+
+// GetRules 获取环境下生效的审核规则
+func (h *LintHandler) GetRules(c *gin.Context) {
+	rules, err := h.lintService.GetRules(models.Environment(c.Param("environment")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Success",
+		"data":    rules,
+	})
+}
+
+// UpdateRules 更新环境下的审核规则（管理员）
+func (h *LintHandler) UpdateRules(c *gin.Context) {
+	var req services.UpdateLintRulesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request parameters",
+			"data":    nil,
+		})
+		return
+	}
+
+	rules, err := h.lintService.UpdateRules(models.Environment(c.Param("environment")), &req, c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Lint rules updated successfully",
+		"data":    rules,
+	})
+}
@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/fengzhencai/MySQLer/backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// MigrationHandler 版本化迁移导入处理器
+type MigrationHandler struct {
+	migrationService *services.MigrationService
+}
+
+// NewMigrationHandler 创建迁移导入处理器
+func NewMigrationHandler(migrationService *services.MigrationService) *MigrationHandler {
+	return &MigrationHandler{
+		migrationService: migrationService,
+	}
+}
+
+// ListSources 获取迁移来源列表
+func (h *MigrationHandler) ListSources(c *gin.Context) {
+	sources, err := h.migrationService.ListSources()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "Failed to get migration sources",
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Success",
+		"data":    sources,
+	})
+}
+
+// CreateSource 登记本地目录
+func (h *MigrationHandler) CreateSource(c *gin.Context) {
+	var req services.CreateMigrationSourceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request parameters",
+			"data":    nil,
+		})
+		return
+	}
+
+	source, err := h.migrationService.CreateSource(&req, c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Migration source created successfully",
+		"data":    source,
+	})
+}
+
+// UploadSource 上传迁移压缩包（multipart: name, description, file）
+func (h *MigrationHandler) UploadSource(c *gin.Context) {
+	name := c.PostForm("name")
+	fileHeader, err := c.FormFile("file")
+	if err != nil || name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request parameters",
+			"data":    nil,
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+	defer file.Close()
+
+	var description *string
+	if desc := c.PostForm("description"); desc != "" {
+		description = &desc
+	}
+
+	source, err := h.migrationService.UploadSource(name, description, file, c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Migration source uploaded successfully",
+		"data":    source,
+	})
+}
+
+// GetSource 获取迁移来源
+func (h *MigrationHandler) GetSource(c *gin.Context) {
+	source, err := h.migrationService.GetSource(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Success",
+		"data":    source,
+	})
+}
+
+// DeleteSource 删除迁移来源
+func (h *MigrationHandler) DeleteSource(c *gin.Context) {
+	if err := h.migrationService.DeleteSource(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Migration source deleted successfully",
+		"data":    nil,
+	})
+}
+
+// ListFiles 获取解析后的迁移文件
+func (h *MigrationHandler) ListFiles(c *gin.Context) {
+	files, err := h.migrationService.ListFiles(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Success",
+		"data":    files,
+	})
+}
+
+// GetStatus 获取各版本在目标库上的应用状态（query: connection_id, database_name）
+func (h *MigrationHandler) GetStatus(c *gin.Context) {
+	var req services.MigrationTargetRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request parameters",
+			"data":    nil,
+		})
+		return
+	}
+
+	status, err := h.migrationService.GetStatus(c.Param("id"), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Success",
+		"data":    status,
+	})
+}
+
+// Plan 为未应用版本生成待执行记录
+func (h *MigrationHandler) Plan(c *gin.Context) {
+	var req services.PlanMigrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request parameters",
+			"data":    nil,
+		})
+		return
+	}
+
+	result, err := h.migrationService.Plan(c.Param("id"), &req, c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    result,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Success",
+		"data":    result,
+	})
+}
+
+// Baseline 将已有版本标记为已应用
+func (h *MigrationHandler) Baseline(c *gin.Context) {
+	var req services.BaselineMigrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request parameters",
+			"data":    nil,
+		})
+		return
+	}
+
+	ledgers, err := h.migrationService.Baseline(c.Param("id"), &req, c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Success",
+		"data":    ledgers,
+	})
+}
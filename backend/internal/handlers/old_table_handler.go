@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/fengzhencai/MySQLer/backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// OldTableHandler 旧表处理器
+type OldTableHandler struct {
+	oldTableService *services.OldTableService
+}
+
+// NewOldTableHandler 创建旧表处理器
+func NewOldTableHandler(oldTableService *services.OldTableService) *OldTableHandler {
+	return &OldTableHandler{
+		oldTableService: oldTableService,
+	}
+}
+
+// SwapBackRequest 切回旧表请求
+type SwapBackRequest struct {
+	Confirm bool   `json:"confirm"` // 确认已知晓切换后写入的数据将丢失
+	Reason  string `json:"reason"`  // 切回原因，记入审计日志
+}
+
+// SwapBack 将保留的旧表原子切换回正式表（需危险操作权限并填写原因）
+func (h *OldTableHandler) SwapBack(c *gin.Context) {
+	var req SwapBackRequest
+	_ = c.ShouldBindJSON(&req)
+
+	if !req.Confirm {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请确认后重试: " + services.SwapBackWarning,
+			"data":    gin.H{"warning": services.SwapBackWarning},
+		})
+		return
+	}
+
+	result, err := h.oldTableService.SwapBack(c.Param("id"), req.Reason, c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Old table swapped back successfully",
+		"data":    result,
+	})
+}
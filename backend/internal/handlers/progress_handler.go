@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fengzhencai/MySQLer/backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// ProgressHandler 执行进度序列处理器
+type ProgressHandler struct {
+	progressService *services.ProgressService
+}
+
+// NewProgressHandler 创建进度序列处理器
+func NewProgressHandler(progressService *services.ProgressService) *ProgressHandler {
+	return &ProgressHandler{
+		progressService: progressService,
+	}
+}
+
+// GetSeries 获取执行的进度时间序列（用于图表）
+func (h *ProgressHandler) GetSeries(c *gin.Context) {
+	series, err := h.progressService.ListSeries(c.Param("id"), maxPointsParam(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "Failed to get progress series",
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Success",
+		"data":    series,
+	})
+}
+
+// GetLoadSeries 获取执行期间的实例负载序列及 max-load/critical-load 阈值
+func (h *ProgressHandler) GetLoadSeries(c *gin.Context) {
+	series, err := h.progressService.GetLoadSeries(c.Param("id"), maxPointsParam(c))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Success",
+		"data":    series,
+	})
+}
+
+// maxPointsParam 读取序列最大点数参数（0 表示使用默认值）
+func maxPointsParam(c *gin.Context) int {
+	if v := c.Query("max_points"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= 5000 {
+			return n
+		}
+	}
+	return 0
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/fengzhencai/MySQLer/backend/internal/models"
+	"github.com/fengzhencai/MySQLer/backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// RetryPolicyHandler 自动重试策略处理器
+type RetryPolicyHandler struct {
+	retryPolicyService *services.RetryPolicyService
+}
+
+// NewRetryPolicyHandler 创建自动重试策略处理器
+func NewRetryPolicyHandler(retryPolicyService *services.RetryPolicyService) *RetryPolicyHandler {
+	return &RetryPolicyHandler{
+		retryPolicyService: retryPolicyService,
+	}
+}
+
+// GetPolicy 获取环境下生效的重试策略
+func (h *RetryPolicyHandler) GetPolicy(c *gin.Context) {
+	policy, err := h.retryPolicyService.Get(models.Environment(c.Param("environment")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Success",
+		"data":    policy,
+	})
+}
+
+// UpdatePolicy 更新环境下的重试策略（管理员）
+func (h *RetryPolicyHandler) UpdatePolicy(c *gin.Context) {
+	var req services.UpdateRetryPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request parameters",
+			"data":    nil,
+		})
+		return
+	}
+
+	policy, err := h.retryPolicyService.Update(models.Environment(c.Param("environment")), &req, c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Retry policy updated successfully",
+		"data":    policy,
+	})
+}
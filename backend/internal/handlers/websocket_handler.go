@@ -48,6 +48,9 @@ type ExecutionProgressMessage struct {
 	TotalRows     int64   `json:"total_rows"`
 	CurrentSpeed  float64 `json:"current_speed"`
 	CurrentStage  string  `json:"current_stage"`
+	Throttled     bool    `json:"throttled"`
+	Health        string  `json:"health,omitempty"`
+	HealthReason  string  `json:"health_reason,omitempty"`
 	LogLine       string  `json:"log_line,omitempty"`
 	Timestamp     string  `json:"timestamp"`
 }
@@ -259,6 +262,9 @@ func (h *WebSocketHandler) sendExecutionStatus(wsConn *WebSocketConnection, exec
 			Progress:     status.Progress,
 			CurrentSpeed: status.Speed,
 			CurrentStage: status.CurrentStage,
+			Throttled:    status.Throttled,
+			Health:       string(status.Health),
+			HealthReason: status.HealthReason,
 			Timestamp:    time.Now().Format("2006-01-02 15:04:05"),
 		},
 	})
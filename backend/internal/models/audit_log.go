@@ -28,6 +28,17 @@ const (
 	ActionExecutionCancel AuditAction = "execution_cancel"
 	ActionExecutionDelete AuditAction = "execution_delete"
 	ActionExecutionRerun  AuditAction = "execution_rerun"
+	ActionExecutionHealth AuditAction = "execution_health" // 运行健康状态变化（系统触发）
+
+	// 旧表管理相关
+	ActionOldTableSwapBack AuditAction = "old_table_swap_back"
+	ActionOldTableDrop     AuditAction = "old_table_drop"
+
+	// 列数据备份相关
+	ActionColumnRestore AuditAction = "column_restore" // 将备份的列数据写回表中
+
+	// 会话管理相关
+	ActionSessionKill AuditAction = "session_kill" // 终止阻塞切换的会话
 
 	// 用户管理相关
 	ActionUserCreate AuditAction = "user_create"
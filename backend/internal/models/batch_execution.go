@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BatchFailureMode 批量执行失败处理模式
+type BatchFailureMode string
+
+const (
+	BatchStopOnFailure BatchFailureMode = "stop"     // 首个失败后停止派发
+	BatchContinue      BatchFailureMode = "continue" // 失败后继续执行其余成员
+)
+
+// BatchKind 批量执行来源
+type BatchKind string
+
+const (
+	BatchKindGroup    BatchKind = "group"    // 同一ALTER下发到分组成员
+	BatchKindFragment BatchKind = "fragment" // 碎片扫描生成的批量重建
+)
+
+// BatchExecution 批量执行（同一ALTER下发到分组内所有成员，或碎片扫描生成的批量重建）
+type BatchExecution struct {
+	ID              string           `json:"id" gorm:"type:varchar(36);primaryKey"`
+	Kind            BatchKind        `json:"kind" gorm:"type:varchar(20);default:'group';index"`
+	GroupID         string           `json:"group_id" gorm:"type:varchar(36);not null;index"`                // 碎片批量为空
+	ConnectionID    *string          `json:"connection_id" gorm:"type:varchar(36);index"`                    // 碎片批量所属连接
+	TargetTableName string           `json:"table_name" gorm:"column:table_name;type:varchar(200);not null"` // 碎片批量为空
+	DDLType         *DDLType         `json:"ddl_type" gorm:"type:enum('fragment','add_column','modify_column','drop_column','add_index','drop_index','other')"`
+	OriginalDDL     *string          `json:"original_ddl" gorm:"type:text"`
+	ExecutionParams *ExecutionParams `json:"execution_params" gorm:"type:json"`
+	Concurrency     int              `json:"concurrency" gorm:"default:1"`
+	FailureMode     BatchFailureMode `json:"failure_mode" gorm:"type:varchar(20);default:'stop'"`
+	Status          ExecutionStatus  `json:"status" gorm:"type:enum('pending','running','completed','failed','cancelled');default:'pending';index"`
+	TotalCount      int              `json:"total_count" gorm:"default:0"`
+	StartTime       *time.Time       `json:"start_time"`
+	EndTime         *time.Time       `json:"end_time"`
+	ErrorMessage    *string          `json:"error_message" gorm:"type:text"` // 批次异常结束的原因
+	CreatedBy       string           `json:"created_by" gorm:"type:varchar(100);index"`
+	CreatedAt       time.Time        `json:"created_at" gorm:"index"`
+	UpdatedAt       time.Time        `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt   `json:"-" gorm:"index"`
+
+	// 关联的分组与子执行记录
+	Group      ConnectionGroup   `json:"group,omitempty" gorm:"foreignKey:GroupID"`
+	Executions []ExecutionRecord `json:"executions,omitempty" gorm:"foreignKey:BatchID"`
+}
+
+// TableName 返回表名
+func (BatchExecution) TableName() string {
+	return "batch_executions"
+}
+
+// IsFinished 检查批量执行是否已结束
+func (b *BatchExecution) IsFinished() bool {
+	return b.Status == StatusCompleted || b.Status == StatusFailed || b.Status == StatusCancelled
+}
@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// RestoreJobStatus 列数据恢复任务状态
+type RestoreJobStatus string
+
+const (
+	RestorePending   RestoreJobStatus = "pending"
+	RestoreRunning   RestoreJobStatus = "running"
+	RestoreCompleted RestoreJobStatus = "completed"
+	RestoreFailed    RestoreJobStatus = "failed"
+)
+
+// ColumnBackup 删除列前导出的列数据备份
+type ColumnBackup struct {
+	ID              int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	ExecutionID     string    `json:"execution_id" gorm:"type:varchar(36);not null;index"`
+	DatabaseName    string    `json:"database_name" gorm:"type:varchar(100);not null"`
+	TargetTableName string    `json:"table_name" gorm:"column:table_name;type:varchar(200);not null"`
+	KeyColumns      string    `json:"key_columns" gorm:"type:varchar(500);not null"` // 主键列，逗号分隔
+	Columns         string    `json:"columns" gorm:"type:varchar(1000);not null"`    // 被删除的列，逗号分隔
+	Format          string    `json:"format" gorm:"type:varchar(10);not null"`       // csv / sql
+	FilePath        string    `json:"file_path" gorm:"type:varchar(500);not null"`
+	Checksum        string    `json:"checksum" gorm:"type:varchar(64);not null"` // sha256
+	FileSize        int64     `json:"file_size" gorm:"default:0"`
+	RowCount        int64     `json:"row_count" gorm:"default:0"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// TableName 返回表名
+func (ColumnBackup) TableName() string {
+	return "column_backups"
+}
+
+// ColumnRestoreJob 列数据恢复任务
+type ColumnRestoreJob struct {
+	ID           string           `json:"id" gorm:"type:varchar(36);primaryKey"`
+	ExecutionID  string           `json:"execution_id" gorm:"type:varchar(36);not null;index"`
+	BackupID     int64            `json:"backup_id" gorm:"not null"`
+	ColumnMap    string           `json:"column_map" gorm:"type:text"` // 备份列 -> 目标列（JSON）
+	Status       RestoreJobStatus `json:"status" gorm:"type:varchar(20);default:'pending';index"`
+	RestoredRows int64            `json:"restored_rows" gorm:"default:0"`
+	ErrorMessage *string          `json:"error_message" gorm:"type:text"`
+	StartTime    *time.Time       `json:"start_time"`
+	EndTime      *time.Time       `json:"end_time"`
+	CreatedBy    string           `json:"created_by" gorm:"type:varchar(100)"`
+	CreatedAt    time.Time        `json:"created_at"`
+	UpdatedAt    time.Time        `json:"updated_at"`
+}
+
+// TableName 返回表名
+func (ColumnRestoreJob) TableName() string {
+	return "column_restore_jobs"
+}
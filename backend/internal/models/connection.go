@@ -29,6 +29,11 @@ type Connection struct {
 	ConnectTimeout int            `json:"connect_timeout" gorm:"default:5"`
 	Charset        string         `json:"charset" gorm:"type:varchar(20);default:'utf8mb4'"`
 	UseSSL         bool           `json:"use_ssl" gorm:"default:false"`
+	SSLMode        string         `json:"ssl_mode" gorm:"type:varchar(20);default:''"` // disabled/preferred/required/verify_ca/verify_identity
+	SSLCA          *string        `json:"-" gorm:"type:text"`                          // CA证书（PEM）
+	SSLCert        *string        `json:"-" gorm:"type:text"`                          // 客户端证书（PEM）
+	SSLKey         *string        `json:"-" gorm:"type:text"`                          // 客户端私钥（加密存储）
+	SSLServerName  string         `json:"ssl_server_name" gorm:"type:varchar(255)"`    // 证书校验使用的服务端名称
 	CreatedBy      string         `json:"created_by" gorm:"type:varchar(100)"`
 	CreatedAt      time.Time      `json:"created_at"`
 	UpdatedAt      time.Time      `json:"updated_at"`
@@ -66,6 +71,11 @@ type ConnectionResponse struct {
 	ConnectTimeout int         `json:"connect_timeout"`
 	Charset        string      `json:"charset"`
 	UseSSL         bool        `json:"use_ssl"`
+	SSLMode        string      `json:"ssl_mode"`
+	SSLServerName  string      `json:"ssl_server_name"`
+	HasSSLCA       bool        `json:"has_ssl_ca"`
+	HasSSLCert     bool        `json:"has_ssl_cert"`
+	HasSSLKey      bool        `json:"has_ssl_key"`
 	CreatedBy      string      `json:"created_by"`
 	CreatedAt      time.Time   `json:"created_at"`
 	UpdatedAt      time.Time   `json:"updated_at"`
@@ -85,6 +95,11 @@ func (c *Connection) ToResponse() ConnectionResponse {
 		ConnectTimeout: c.ConnectTimeout,
 		Charset:        c.Charset,
 		UseSSL:         c.UseSSL,
+		SSLMode:        c.SSLMode,
+		SSLServerName:  c.SSLServerName,
+		HasSSLCA:       c.SSLCA != nil && *c.SSLCA != "",
+		HasSSLCert:     c.SSLCert != nil && *c.SSLCert != "",
+		HasSSLKey:      c.SSLKey != nil && *c.SSLKey != "",
 		CreatedBy:      c.CreatedBy,
 		CreatedAt:      c.CreatedAt,
 		UpdatedAt:      c.UpdatedAt,
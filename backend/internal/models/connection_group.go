@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ConnectionGroup 连接/库分组模型（如同构分片库）
+type ConnectionGroup struct {
+	ID          string         `json:"id" gorm:"type:varchar(36);primaryKey"`
+	Name        string         `json:"name" gorm:"type:varchar(100);not null;index"`
+	Description *string        `json:"description" gorm:"type:text"`
+	CreatedBy   string         `json:"created_by" gorm:"type:varchar(100)"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// 分组成员
+	Members []ConnectionGroupMember `json:"members,omitempty" gorm:"foreignKey:GroupID"`
+}
+
+// TableName 返回表名
+func (ConnectionGroup) TableName() string {
+	return "connection_groups"
+}
+
+// ConnectionGroupMember 分组成员（连接 + 数据库）
+type ConnectionGroupMember struct {
+	ID           string    `json:"id" gorm:"type:varchar(36);primaryKey"`
+	GroupID      string    `json:"group_id" gorm:"type:varchar(36);not null;index"`
+	ConnectionID string    `json:"connection_id" gorm:"type:varchar(36);not null"`
+	DatabaseName string    `json:"database_name" gorm:"type:varchar(100);not null"`
+	SortOrder    int       `json:"sort_order" gorm:"default:0"`
+	CreatedAt    time.Time `json:"created_at"`
+
+	// 关联的连接信息
+	Connection Connection `json:"connection,omitempty" gorm:"foreignKey:ConnectionID"`
+}
+
+// TableName 返回表名
+func (ConnectionGroupMember) TableName() string {
+	return "connection_group_members"
+}
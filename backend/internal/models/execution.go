@@ -31,6 +31,33 @@ const (
 	StatusCompleted ExecutionStatus = "completed" // 执行完成
 	StatusFailed    ExecutionStatus = "failed"    // 执行失败
 	StatusCancelled ExecutionStatus = "cancelled" // 手动取消
+
+	StatusCompletedWithWarnings ExecutionStatus = "completed_with_warnings" // 执行完成但执行后校验存在不一致
+)
+
+// Succeeded 是否已成功完成（含校验存在警告的完成）
+func (s ExecutionStatus) Succeeded() bool {
+	return s == StatusCompleted || s == StatusCompletedWithWarnings
+}
+
+// ExecutionHealth 运行中执行的健康状态
+type ExecutionHealth string
+
+const (
+	HealthHealthy    ExecutionHealth = "healthy"     // 正常
+	HealthNoOutput   ExecutionHealth = "no_output"   // 长时间无日志输出
+	HealthNoProgress ExecutionHealth = "no_progress" // 进度长时间未推进
+	HealthThrottled  ExecutionHealth = "throttled"   // pt-osc 因负载/延迟暂停
+	HealthSlow       ExecutionHealth = "slow"        // 速度明显低于自身移动平均
+)
+
+// OldTableStatus 旧表状态
+type OldTableStatus string
+
+const (
+	OldTableKept     OldTableStatus = "kept"     // 已保留
+	OldTableRestored OldTableStatus = "restored" // 已切换回旧表
+	OldTableDropped  OldTableStatus = "dropped"  // 已删除
 )
 
 // ExecutionParams 执行参数
@@ -42,6 +69,18 @@ type ExecutionParams struct {
 	LockWaitTimeout int    `json:"lock_wait_timeout"` // 锁等待超时
 	OtherParams     string `json:"other_params"`      // 其他参数
 	NoCheckAlter    bool   `json:"no_check_alter"`    // 跳过check-alter预检
+	KeepOldTable    bool   `json:"keep_old_table"`    // 保留旧表 _<table>_old
+	OldTableRetain  int    `json:"old_table_retain"`  // 旧表保留时长（小时），0 使用默认值
+	BackupFormat    string `json:"backup_format"`     // 删除列备份格式：csv / sql
+
+	// 切换保护：存在长事务时推迟切换
+	HoldCutover      bool   `json:"hold_cutover"`       // 切换前等待目标表上的长事务结束
+	LongTrxSeconds   int    `json:"long_trx_seconds"`   // 长事务判定秒数，0 使用默认值
+	CutoverTimeout   int    `json:"cutover_timeout"`    // 最长等待秒数，0 使用默认值
+	CutoverOnTimeout string `json:"cutover_on_timeout"` // 等待超时后：proceed（照常切换）/ abort（中止）
+
+	VerifyChecksum bool `json:"verify_checksum"` // 完成后按主键分块比较新旧表校验和（需保留旧表）
+	Resumable      bool `json:"resumable"`       // 失败时保留新表与触发器，重试时可从中断处续跑
 }
 
 // ExecutionRecord 执行记录模型
@@ -54,7 +93,7 @@ type ExecutionRecord struct {
 	OriginalDDL      *string          `json:"original_ddl" gorm:"type:text"`
 	GeneratedCommand string           `json:"generated_command" gorm:"type:text;not null"`
 	ExecutionParams  *ExecutionParams `json:"execution_params" gorm:"type:json"`
-	Status           ExecutionStatus  `json:"status" gorm:"type:enum('pending','running','completed','failed','cancelled');default:'pending';index"`
+	Status           ExecutionStatus  `json:"status" gorm:"type:enum('pending','running','completed','completed_with_warnings','failed','cancelled');default:'pending';index"`
 	StartTime        *time.Time       `json:"start_time"`
 	EndTime          *time.Time       `json:"end_time"`
 	DurationSeconds  *int             `json:"duration_seconds"`
@@ -64,6 +103,25 @@ type ExecutionRecord struct {
 	ContainerID      *string          `json:"container_id" gorm:"type:varchar(64)"`
 	ExecutionLogs    *string          `json:"execution_logs" gorm:"type:longtext"`
 	ErrorMessage     *string          `json:"error_message" gorm:"type:text"`
+	FailureCategory  *string          `json:"failure_category" gorm:"type:varchar(50);index"` // 失败分类
+	FailureLine      *string          `json:"failure_line" gorm:"type:text"`                  // 命中分类规则的日志行
+	FailureHint      *string          `json:"failure_hint" gorm:"type:text"`                  // 处理建议
+	BatchID          *string          `json:"batch_id" gorm:"type:varchar(36);index"`         // 所属批量执行
+	ChangeID         *string          `json:"change_id" gorm:"type:varchar(36);index"`        // 所属发布变更
+	MigrationID      *string          `json:"migration_id" gorm:"type:varchar(36);index"`     // 所属迁移版本
+	RollbackDDL      *string          `json:"rollback_ddl" gorm:"type:text"`                  // 逆向ALTER语句
+	Revertible       bool             `json:"revertible" gorm:"default:false"`                // 是否支持一键回滚
+	RevertOf         *string          `json:"revert_of" gorm:"type:varchar(36);index"`        // 回滚的原执行ID
+	OldTableName     *string          `json:"old_table_name" gorm:"type:varchar(64)"`         // 保留的旧表名
+	OldTableStatus   *OldTableStatus  `json:"old_table_status" gorm:"type:varchar(20);index"`
+	OldTableExpireAt *time.Time       `json:"old_table_expire_at"`                     // 旧表过期时间
+	BackupFile       *string          `json:"backup_file" gorm:"type:varchar(500)"`    // 删除列数据备份文件
+	BackupChecksum   *string          `json:"backup_checksum" gorm:"type:varchar(64)"` // 备份文件sha256
+	ReclaimedBytes   *int64           `json:"reclaimed_bytes"`                         // 碎片整理实际回收空间（字节）
+	Verification     *json.RawMessage `json:"verification" gorm:"type:json"`           // 执行后校验结果
+	PTJobID          *int64           `json:"pt_job_id"`                               // pt-osc 历史表中的任务ID（用于 --resume）
+	Attempts         int              `json:"attempts" gorm:"default:0"`               // 已开始的执行次数
+	NextRetryAt      *time.Time       `json:"next_retry_at"`                           // 计划的自动重试时间
 	CreatedBy        string           `json:"created_by" gorm:"type:varchar(100);index"`
 	CreatedAt        time.Time        `json:"created_at" gorm:"index"`
 	UpdatedAt        time.Time        `json:"updated_at"`
@@ -85,7 +143,7 @@ func (e *ExecutionRecord) IsRunning() bool {
 
 // IsCompleted 检查是否已完成
 func (e *ExecutionRecord) IsCompleted() bool {
-	return e.Status == StatusCompleted
+	return e.Status.Succeeded()
 }
 
 // IsFailed 检查是否执行失败
@@ -93,6 +151,11 @@ func (e *ExecutionRecord) IsFailed() bool {
 	return e.Status == StatusFailed
 }
 
+// IsFinished 检查是否已结束（完成、失败或取消）
+func (e *ExecutionRecord) IsFinished() bool {
+	return e.Status.Succeeded() || e.Status == StatusFailed || e.Status == StatusCancelled
+}
+
 // CanCancel 检查是否可以取消
 func (e *ExecutionRecord) CanCancel() bool {
 	return e.Status == StatusPending || e.Status == StatusRunning
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// AttemptTrigger 执行尝试的触发方式
+type AttemptTrigger string
+
+const (
+	AttemptInitial AttemptTrigger = "initial" // 首次执行
+	AttemptManual  AttemptTrigger = "manual"  // 手动重试
+	AttemptAuto    AttemptTrigger = "auto"    // 按重试策略自动重试
+)
+
+// ExecutionAttempt 执行记录的一次运行（重试不会覆盖之前的运行）
+type ExecutionAttempt struct {
+	ID              int64           `json:"id" gorm:"primaryKey;autoIncrement"`
+	ExecutionID     string          `json:"execution_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_execution_attempt"`
+	Attempt         int             `json:"attempt" gorm:"not null;uniqueIndex:idx_execution_attempt"` // 第几次执行，从1开始
+	Trigger         AttemptTrigger  `json:"trigger" gorm:"type:varchar(20);not null"`
+	Mode            string          `json:"mode" gorm:"type:varchar(20)"` // 重试方式：restart / resume
+	Status          ExecutionStatus `json:"status" gorm:"type:varchar(30);not null;index"`
+	ScheduledAt     *time.Time      `json:"scheduled_at"` // 自动重试的计划开始时间
+	StartTime       *time.Time      `json:"start_time"`
+	EndTime         *time.Time      `json:"end_time"`
+	DurationSeconds *int            `json:"duration_seconds"`
+	ContainerID     *string         `json:"container_id" gorm:"type:varchar(64)"`
+	ExitCode        *int            `json:"exit_code"` // pt-osc 容器退出码，未启动容器时为空
+	ErrorMessage    *string         `json:"error_message" gorm:"type:text"`
+	FailureCategory *string         `json:"failure_category" gorm:"type:varchar(50)"`
+	CreatedBy       string          `json:"created_by" gorm:"type:varchar(100)"`
+	CreatedAt       time.Time       `json:"created_at"`
+}
+
+// TableName 返回表名
+func (ExecutionAttempt) TableName() string {
+	return "execution_attempts"
+}
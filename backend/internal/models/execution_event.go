@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// EventActorSystem 系统触发的状态变更（执行引擎、自动重试等）
+const EventActorSystem = "system"
+
+// ExecutionEvent 执行记录的状态变更
+type ExecutionEvent struct {
+	ID          int64           `json:"id" gorm:"primaryKey;autoIncrement"`
+	ExecutionID string          `json:"execution_id" gorm:"type:varchar(36);not null;index"`
+	Attempt     int             `json:"attempt"` // 变更发生时的执行次数
+	FromStatus  ExecutionStatus `json:"from_status" gorm:"type:varchar(30)"`
+	ToStatus    ExecutionStatus `json:"to_status" gorm:"type:varchar(30);not null"`
+	Actor       string          `json:"actor" gorm:"type:varchar(100);not null"` // 操作人ID，系统触发为 system
+	Reason      string          `json:"reason" gorm:"type:text"`
+	CreatedAt   time.Time       `json:"created_at" gorm:"index"`
+}
+
+// TableName 返回表名
+func (ExecutionEvent) TableName() string {
+	return "execution_events"
+}
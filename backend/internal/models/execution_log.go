@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// ExecutionLogChunk 执行日志分块（按行号连续编号，跨多次执行尝试递增）
+type ExecutionLogChunk struct {
+	ID          int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	ExecutionID string    `json:"execution_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_execution_chunk"`
+	Seq         int       `json:"seq" gorm:"not null;uniqueIndex:idx_execution_chunk"` // 分块序号，从0开始
+	Attempt     int       `json:"attempt" gorm:"index"`                                // 所属执行次数
+	FirstLine   int64     `json:"first_line" gorm:"not null"`                          // 首行行号，从0开始
+	LineCount   int       `json:"line_count" gorm:"not null"`
+	Content     []byte    `json:"-" gorm:"type:mediumblob"` // 以换行分隔的日志内容
+	Compressed  bool      `json:"compressed" gorm:"index"`  // Content 是否经 gzip 压缩
+	CreatedAt   time.Time `json:"created_at" gorm:"index"`
+}
+
+// TableName 返回表名
+func (ExecutionLogChunk) TableName() string {
+	return "execution_log_chunks"
+}
+
+// EndLine 分块之后的下一行行号
+func (c *ExecutionLogChunk) EndLine() int64 {
+	return c.FirstLine + int64(c.LineCount)
+}
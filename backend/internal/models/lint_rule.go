@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// LintRuleSetting 审核规则在某个环境下的配置（未配置时使用内置默认值）
+type LintRuleSetting struct {
+	ID          int64       `json:"id" gorm:"primaryKey;autoIncrement"`
+	Environment Environment `json:"environment" gorm:"type:varchar(20);not null;uniqueIndex:idx_env_rule"`
+	RuleID      string      `json:"rule_id" gorm:"type:varchar(50);not null;uniqueIndex:idx_env_rule"`
+	Enabled     bool        `json:"enabled" gorm:"default:true"`
+	Severity    string      `json:"severity" gorm:"type:varchar(10);not null"`
+	Threshold   int         `json:"threshold" gorm:"default:0"`
+	UpdatedBy   string      `json:"updated_by" gorm:"type:varchar(100)"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+// TableName 返回表名
+func (LintRuleSetting) TableName() string {
+	return "lint_rule_settings"
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MigrationSourceType 迁移来源类型
+type MigrationSourceType string
+
+const (
+	MigrationSourceDirectory MigrationSourceType = "directory" // 服务器本地目录（如 git 仓库检出目录）
+	MigrationSourceArchive   MigrationSourceType = "archive"   // 上传的 tar / tar.gz 包
+)
+
+// MigrationLedgerStatus 迁移版本应用状态
+type MigrationLedgerStatus string
+
+const (
+	MigrationPending   MigrationLedgerStatus = "pending"   // 已生成执行记录，尚未全部完成
+	MigrationApplied   MigrationLedgerStatus = "applied"   // 已应用
+	MigrationFailed    MigrationLedgerStatus = "failed"    // 执行失败或取消
+	MigrationBaselined MigrationLedgerStatus = "baselined" // 标记为已应用（未经 MySQLer 执行）
+)
+
+// MigrationSource 版本化迁移文件来源
+type MigrationSource struct {
+	ID          string              `json:"id" gorm:"type:varchar(36);primaryKey"`
+	Name        string              `json:"name" gorm:"type:varchar(100);not null;index"`
+	SourceType  MigrationSourceType `json:"source_type" gorm:"type:varchar(20);not null"`
+	Path        string              `json:"path" gorm:"type:varchar(500);not null"` // 目录路径或解压目录
+	Description *string             `json:"description" gorm:"type:text"`
+	FileCount   int                 `json:"file_count" gorm:"default:0"`
+	ScannedAt   *time.Time          `json:"scanned_at"`
+	CreatedBy   string              `json:"created_by" gorm:"type:varchar(100)"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt      `json:"-" gorm:"index"`
+}
+
+// TableName 返回表名
+func (MigrationSource) TableName() string {
+	return "migration_sources"
+}
+
+// MigrationLedger 迁移版本在某个连接/库上的应用记录
+type MigrationLedger struct {
+	ID           string                `json:"id" gorm:"type:varchar(36);primaryKey"`
+	SourceID     string                `json:"source_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_migration_target"`
+	Version      string                `json:"version" gorm:"type:varchar(50);not null;uniqueIndex:idx_migration_target"`
+	ConnectionID string                `json:"connection_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_migration_target"`
+	DatabaseName string                `json:"database_name" gorm:"type:varchar(100);not null;uniqueIndex:idx_migration_target"`
+	FileName     string                `json:"file_name" gorm:"type:varchar(500)"`
+	Checksum     string                `json:"checksum" gorm:"type:varchar(64)"`
+	Status       MigrationLedgerStatus `json:"status" gorm:"type:varchar(20);default:'pending';index"`
+	PlannedAt    *time.Time            `json:"planned_at"` // 最近一次生成执行记录的时间
+	AppliedAt    *time.Time            `json:"applied_at"`
+	CreatedBy    string                `json:"created_by" gorm:"type:varchar(100)"`
+	CreatedAt    time.Time             `json:"created_at"`
+	UpdatedAt    time.Time             `json:"updated_at"`
+
+	// 该版本生成的执行记录
+	Executions []ExecutionRecord `json:"executions,omitempty" gorm:"foreignKey:MigrationID"`
+}
+
+// TableName 返回表名
+func (MigrationLedger) TableName() string {
+	return "migration_ledger"
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// ExecutionProgressSample 执行进度采样（用于事后查看复制速度变化与停顿）
+type ExecutionProgressSample struct {
+	ID            int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	ExecutionID   string    `json:"execution_id" gorm:"type:varchar(36);not null;index:idx_execution_sampled"`
+	SampledAt     time.Time `json:"sampled_at" gorm:"not null;index:idx_execution_sampled"`
+	Progress      float64   `json:"progress" gorm:"type:decimal(5,2)"`
+	ProcessedRows int64     `json:"processed_rows" gorm:"default:0"`
+	Speed         float64   `json:"speed" gorm:"type:decimal(12,2)"` // 行/秒
+	Stage         string    `json:"stage" gorm:"type:varchar(100)"`
+	Throttled     bool      `json:"throttled" gorm:"default:false"` // pt-osc 因负载/延迟暂停
+}
+
+// TableName 返回表名
+func (ExecutionProgressSample) TableName() string {
+	return "execution_progress_samples"
+}
+
+// ExecutionLoadSample 执行期间目标实例的负载采样
+type ExecutionLoadSample struct {
+	ID                 int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	ExecutionID        string    `json:"execution_id" gorm:"type:varchar(36);not null;index:idx_execution_sampled"`
+	SampledAt          time.Time `json:"sampled_at" gorm:"not null;index:idx_execution_sampled"`
+	ThreadsRunning     int64     `json:"threads_running"`
+	ThreadsConnected   int64     `json:"threads_connected"`
+	HistoryListLength  *int64    `json:"history_list_length"`
+	ReplicationLag     *int64    `json:"replication_lag"` // 秒，非从库时为空
+	RowsReadPerSec     float64   `json:"rows_read_per_sec" gorm:"type:decimal(14,2)"`
+	RowsInsertedPerSec float64   `json:"rows_inserted_per_sec" gorm:"type:decimal(14,2)"`
+	RowsUpdatedPerSec  float64   `json:"rows_updated_per_sec" gorm:"type:decimal(14,2)"`
+	RowsDeletedPerSec  float64   `json:"rows_deleted_per_sec" gorm:"type:decimal(14,2)"`
+}
+
+// TableName 返回表名
+func (ExecutionLoadSample) TableName() string {
+	return "execution_load_samples"
+}
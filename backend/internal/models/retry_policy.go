@@ -0,0 +1,47 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// RetryPolicy 某个环境下失败执行的自动重试策略（未配置时使用内置默认值）
+type RetryPolicy struct {
+	ID                  int64       `json:"id" gorm:"primaryKey;autoIncrement"`
+	Environment         Environment `json:"environment" gorm:"type:varchar(20);not null;uniqueIndex"`
+	Enabled             bool        `json:"enabled"`
+	MaxAttempts         int         `json:"max_attempts"`                                  // 含首次执行在内的最多执行次数
+	BackoffSeconds      int         `json:"backoff_seconds"`                               // 首次重试前等待秒数
+	BackoffMultiplier   float64     `json:"backoff_multiplier"`                            // 每次重试等待时间的倍数
+	MaxBackoffSeconds   int         `json:"max_backoff_seconds"`                           // 等待时间上限
+	RetriableCategories string      `json:"retriable_categories" gorm:"type:varchar(500)"` // 可重试的失败分类，逗号分隔
+	UpdatedBy           string      `json:"updated_by" gorm:"type:varchar(100)"`
+	UpdatedAt           time.Time   `json:"updated_at"`
+}
+
+// TableName 返回表名
+func (RetryPolicy) TableName() string {
+	return "retry_policies"
+}
+
+// Retriable 失败分类是否允许自动重试
+func (p *RetryPolicy) Retriable(category string) bool {
+	for _, item := range strings.Split(p.RetriableCategories, ",") {
+		if strings.TrimSpace(item) == category {
+			return true
+		}
+	}
+	return false
+}
+
+// Backoff 第 attempt 次执行失败后的等待时间
+func (p *RetryPolicy) Backoff(attempt int) time.Duration {
+	seconds := float64(p.BackoffSeconds)
+	for i := 1; i < attempt; i++ {
+		seconds *= p.BackoffMultiplier
+	}
+	if p.MaxBackoffSeconds > 0 && seconds > float64(p.MaxBackoffSeconds) {
+		seconds = float64(p.MaxBackoffSeconds)
+	}
+	return time.Duration(seconds) * time.Second
+}
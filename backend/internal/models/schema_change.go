@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ChangeStatus 发布变更状态
+type ChangeStatus string
+
+const (
+	ChangePending    ChangeStatus = "pending"     // 尚未开始
+	ChangeInProgress ChangeStatus = "in_progress" // 发布中
+	ChangeCompleted  ChangeStatus = "completed"   // 所有环境已完成
+	ChangeFailed     ChangeStatus = "failed"      // 某一环境执行失败
+	ChangeCancelled  ChangeStatus = "cancelled"   // 已取消
+)
+
+// SchemaChange 跨环境发布的结构变更（同一ALTER依次经过 dev → test → prod）
+type SchemaChange struct {
+	ID              string           `json:"id" gorm:"type:varchar(36);primaryKey"`
+	Title           string           `json:"title" gorm:"type:varchar(200);not null"`
+	TargetTableName string           `json:"table_name" gorm:"column:table_name;type:varchar(200);not null"`
+	DDLType         *DDLType         `json:"ddl_type" gorm:"type:enum('fragment','add_column','modify_column','drop_column','add_index','drop_index','other')"`
+	OriginalDDL     *string          `json:"original_ddl" gorm:"type:text"`
+	ExecutionParams *ExecutionParams `json:"execution_params" gorm:"type:json"`
+	Status          ChangeStatus     `json:"status" gorm:"type:varchar(20);default:'pending';index"`
+	CurrentStage    int              `json:"current_stage" gorm:"default:0"` // 下一个待完成阶段的序号
+	CreatedBy       string           `json:"created_by" gorm:"type:varchar(100);index"`
+	CreatedAt       time.Time        `json:"created_at" gorm:"index"`
+	UpdatedAt       time.Time        `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt   `json:"-" gorm:"index"`
+
+	// 发布阶段（按顺序）
+	Stages []ChangeStage `json:"stages,omitempty" gorm:"foreignKey:ChangeID"`
+}
+
+// TableName 返回表名
+func (SchemaChange) TableName() string {
+	return "schema_changes"
+}
+
+// IsFinished 检查变更是否已结束
+func (c *SchemaChange) IsFinished() bool {
+	return c.Status == ChangeCompleted || c.Status == ChangeCancelled
+}
+
+// ChangeStage 变更在某个环境上的发布阶段
+type ChangeStage struct {
+	ID            string          `json:"id" gorm:"type:varchar(36);primaryKey"`
+	ChangeID      string          `json:"change_id" gorm:"type:varchar(36);not null;index"`
+	StageOrder    int             `json:"stage_order" gorm:"not null"`
+	Environment   Environment     `json:"environment" gorm:"type:varchar(20)"`
+	ConnectionID  string          `json:"connection_id" gorm:"type:varchar(36);not null"`
+	DatabaseName  string          `json:"database_name" gorm:"type:varchar(100);not null"`
+	Status        ExecutionStatus `json:"status" gorm:"type:varchar(30);default:'pending'"`
+	ExecutionID   *string         `json:"execution_id" gorm:"type:varchar(36);index"` // 最近一次执行
+	ResultSchema  *string         `json:"result_schema" gorm:"type:longtext"`         // 完成后的建表语句
+	VerifiedAt    *time.Time      `json:"verified_at"`                                // 最近一次结构校验时间
+	VerifyMessage *string         `json:"verify_message" gorm:"type:text"`            // 结构校验结果
+	StartedAt     *time.Time      `json:"started_at"`
+	CompletedAt   *time.Time      `json:"completed_at"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+
+	// 关联的连接与执行记录
+	Connection Connection       `json:"connection,omitempty" gorm:"foreignKey:ConnectionID"`
+	Execution  *ExecutionRecord `json:"execution,omitempty" gorm:"foreignKey:ExecutionID"`
+}
+
+// TableName 返回表名
+func (ChangeStage) TableName() string {
+	return "change_stages"
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DriftCheck 跨环境结构漂移检查配置（基准库与对比库）
+type DriftCheck struct {
+	ID                 string         `json:"id" gorm:"type:varchar(36);primaryKey"`
+	Name               string         `json:"name" gorm:"type:varchar(100);not null"`
+	BaseConnectionID   string         `json:"base_connection_id" gorm:"type:varchar(36);not null"`
+	BaseDatabase       string         `json:"base_database" gorm:"type:varchar(100);not null"`
+	TargetConnectionID string         `json:"target_connection_id" gorm:"type:varchar(36);not null"`
+	TargetDatabase     string         `json:"target_database" gorm:"type:varchar(100);not null"`
+	GenerateAlters     bool           `json:"generate_alters" gorm:"default:false"`
+	IntervalMinutes    int            `json:"interval_minutes" gorm:"default:0"` // 0 表示不定时执行
+	Enabled            bool           `json:"enabled" gorm:"default:true"`
+	LastRunAt          *time.Time     `json:"last_run_at"`
+	NextRunAt          *time.Time     `json:"next_run_at" gorm:"index"`
+	CreatedBy          string         `json:"created_by" gorm:"type:varchar(100)"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt `json:"-" gorm:"index"`
+
+	BaseConnection   Connection `json:"base_connection,omitempty" gorm:"foreignKey:BaseConnectionID"`
+	TargetConnection Connection `json:"target_connection,omitempty" gorm:"foreignKey:TargetConnectionID"`
+}
+
+// TableName 返回表名
+func (DriftCheck) TableName() string {
+	return "drift_checks"
+}
+
+// DriftReport 漂移检查结果
+type DriftReport struct {
+	ID                 int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	CheckID            *string   `json:"check_id" gorm:"type:varchar(36);index"` // 临时对比为空
+	BaseConnectionID   string    `json:"base_connection_id" gorm:"type:varchar(36);not null"`
+	BaseDatabase       string    `json:"base_database" gorm:"type:varchar(100);not null"`
+	TargetConnectionID string    `json:"target_connection_id" gorm:"type:varchar(36);not null"`
+	TargetDatabase     string    `json:"target_database" gorm:"type:varchar(100);not null"`
+	Status             string    `json:"status" gorm:"type:varchar(20);not null"` // success / failed
+	TablesCompared     int       `json:"tables_compared" gorm:"default:0"`
+	DriftedTables      int       `json:"drifted_tables" gorm:"default:0"`
+	MissingTables      int       `json:"missing_tables" gorm:"default:0"`
+	ColumnDiffs        int       `json:"column_diffs" gorm:"default:0"`
+	IndexDiffs         int       `json:"index_diffs" gorm:"default:0"`
+	CharsetDiffs       int       `json:"charset_diffs" gorm:"default:0"`
+	OtherDiffs         int       `json:"other_diffs" gorm:"default:0"`
+	Details            *string   `json:"details,omitempty" gorm:"type:longtext"` // 逐表差异（JSON）
+	ErrorMessage       *string   `json:"error_message" gorm:"type:text"`
+	DurationMs         int64     `json:"duration_ms" gorm:"default:0"`
+	CreatedAt          time.Time `json:"created_at" gorm:"index"`
+}
+
+// TableName 返回表名
+func (DriftReport) TableName() string {
+	return "drift_reports"
+}
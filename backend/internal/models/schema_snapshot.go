@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// SnapshotPhase 快照阶段
+type SnapshotPhase string
+
+const (
+	SnapshotBefore SnapshotPhase = "before" // 执行前
+	SnapshotAfter  SnapshotPhase = "after"  // 执行后
+)
+
+// SchemaSnapshot 执行前后的表结构快照
+type SchemaSnapshot struct {
+	ID              int64         `json:"id" gorm:"primaryKey;autoIncrement"`
+	ExecutionID     string        `json:"execution_id" gorm:"type:varchar(36);not null;index:idx_execution_phase"`
+	Phase           SnapshotPhase `json:"phase" gorm:"type:varchar(20);not null;index:idx_execution_phase"`
+	DatabaseName    string        `json:"database_name" gorm:"type:varchar(100);not null"`
+	TargetTableName string        `json:"table_name" gorm:"column:table_name;type:varchar(200);not null"`
+	CreateTableSQL  string        `json:"create_table_sql" gorm:"type:longtext"`
+	TableRows       int64         `json:"table_rows" gorm:"default:0"`
+	DataLength      int64         `json:"data_length" gorm:"default:0"`
+	IndexLength     int64         `json:"index_length" gorm:"default:0"`
+	DataFree        int64         `json:"data_free" gorm:"default:0"`
+	CapturedAt      time.Time     `json:"captured_at"`
+}
+
+// TableName 返回表名
+func (SchemaSnapshot) TableName() string {
+	return "schema_snapshots"
+}
@@ -0,0 +1,631 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fengzhencai/MySQLer/backend/internal/config"
+	"github.com/fengzhencai/MySQLer/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// BatchService 连接分组与批量执行服务
+type BatchService struct {
+	db               *gorm.DB
+	cfg              *config.Config
+	executionService *ExecutionService
+	executionEngine  *ExecutionEngine
+
+	// 正在派发的批量执行（batchID -> 停止信号）
+	dispatchers map[string]chan struct{}
+	mutex       sync.Mutex
+
+	// 子任务状态轮询间隔
+	pollInterval time.Duration
+}
+
+// NewBatchService 创建批量执行服务
+func NewBatchService(db *gorm.DB, cfg *config.Config, executionService *ExecutionService, executionEngine *ExecutionEngine) *BatchService {
+	return &BatchService{
+		db:               db,
+		cfg:              cfg,
+		executionService: executionService,
+		executionEngine:  executionEngine,
+		dispatchers:      make(map[string]chan struct{}),
+		pollInterval:     2 * time.Second,
+	}
+}
+
+// GroupMemberRequest 分组成员请求
+type GroupMemberRequest struct {
+	ConnectionID string `json:"connection_id" binding:"required,uuid4"`
+	DatabaseName string `json:"database_name" binding:"required,min=1,max=100"`
+}
+
+// CreateGroupRequest 创建/更新分组请求
+type CreateGroupRequest struct {
+	Name        string               `json:"name" binding:"required,min=1,max=100"`
+	Description *string              `json:"description" binding:"omitempty,max=200"`
+	Members     []GroupMemberRequest `json:"members" binding:"required,min=1,dive"`
+}
+
+// CreateBatchRequest 创建批量执行请求
+type CreateBatchRequest struct {
+	GroupID         string                  `json:"group_id" binding:"required,uuid4"`
+	TableName       string                  `json:"table_name" binding:"required,min=1,max=200"`
+	DDLType         *models.DDLType         `json:"ddl_type" binding:"required"`
+	OriginalDDL     *string                 `json:"original_ddl" binding:"omitempty,max=2000"`
+	ExecutionParams *models.ExecutionParams `json:"execution_params"`
+	Concurrency     int                     `json:"concurrency" binding:"omitempty,min=1,max=32"`
+	FailureMode     models.BatchFailureMode `json:"failure_mode" binding:"omitempty,oneof=stop continue"`
+}
+
+// CreateFragmentBatchRequest 根据碎片扫描结果创建批量重建请求
+type CreateFragmentBatchRequest struct {
+	ConnectionID string `json:"connection_id" binding:"required,uuid4"`
+	FragmentScanRequest
+	Tables          []string                `json:"tables"` // 可选：仅重建其中的表（database.table）
+	ExecutionParams *models.ExecutionParams `json:"execution_params"`
+	Concurrency     int                     `json:"concurrency" binding:"omitempty,min=1,max=32"`
+	FailureMode     models.BatchFailureMode `json:"failure_mode" binding:"omitempty,oneof=stop continue"`
+}
+
+// BatchProgress 批量执行聚合进度
+type BatchProgress struct {
+	Total     int     `json:"total"`
+	Pending   int     `json:"pending"`
+	Running   int     `json:"running"`
+	Completed int     `json:"completed"`
+	Failed    int     `json:"failed"`
+	Cancelled int     `json:"cancelled"`
+	Progress  float64 `json:"progress"` // 所有成员进度的平均值（百分比）
+
+	ReclaimedBytes int64 `json:"reclaimed_bytes,omitempty"` // 已完成碎片整理的实际回收空间合计
+}
+
+// BatchDetail 批量执行详情
+type BatchDetail struct {
+	*models.BatchExecution
+	AggregateProgress BatchProgress `json:"aggregate_progress"`
+}
+
+// ListGroups 获取分组列表
+func (s *BatchService) ListGroups() ([]models.ConnectionGroup, error) {
+	var groups []models.ConnectionGroup
+	err := s.db.Preload("Members", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sort_order")
+	}).Order("created_at DESC").Find(&groups).Error
+	return groups, err
+}
+
+// GetGroup 根据ID获取分组
+func (s *BatchService) GetGroup(id string) (*models.ConnectionGroup, error) {
+	var group models.ConnectionGroup
+	err := s.db.Preload("Members", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sort_order")
+	}).Preload("Members.Connection").First(&group, "id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("分组不存在")
+		}
+		return nil, err
+	}
+	return &group, nil
+}
+
+// CreateGroup 创建分组
+func (s *BatchService) CreateGroup(req *CreateGroupRequest, userID string) (*models.ConnectionGroup, error) {
+	if err := s.validateMembers(req.Members); err != nil {
+		return nil, err
+	}
+
+	group := &models.ConnectionGroup{
+		ID:          uuid.New().String(),
+		Name:        req.Name,
+		Description: req.Description,
+		CreatedBy:   userID,
+		Members:     buildGroupMembers(req.Members),
+	}
+
+	if err := s.db.Create(group).Error; err != nil {
+		return nil, fmt.Errorf("创建分组失败: %v", err)
+	}
+	return group, nil
+}
+
+// UpdateGroup 更新分组（成员整体替换）
+func (s *BatchService) UpdateGroup(id string, req *CreateGroupRequest) (*models.ConnectionGroup, error) {
+	if err := s.validateMembers(req.Members); err != nil {
+		return nil, err
+	}
+
+	group, err := s.GetGroup(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		group.Name = req.Name
+		group.Description = req.Description
+		if err := tx.Omit("Members").Save(group).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("group_id = ?", id).Delete(&models.ConnectionGroupMember{}).Error; err != nil {
+			return err
+		}
+		members := buildGroupMembers(req.Members)
+		for i := range members {
+			members[i].GroupID = id
+		}
+		if err := tx.Create(&members).Error; err != nil {
+			return err
+		}
+		group.Members = members
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("更新分组失败: %v", err)
+	}
+	return group, nil
+}
+
+// DeleteGroup 删除分组
+func (s *BatchService) DeleteGroup(id string) error {
+	var count int64
+	s.db.Model(&models.BatchExecution{}).
+		Where("group_id = ? AND status IN ?", id, []models.ExecutionStatus{models.StatusPending, models.StatusRunning}).
+		Count(&count)
+	if count > 0 {
+		return fmt.Errorf("该分组存在未结束的批量执行，无法删除")
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("group_id = ?", id).Delete(&models.ConnectionGroupMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.ConnectionGroup{}, "id = ?", id).Error
+	})
+}
+
+// ListBatches 获取批量执行列表
+func (s *BatchService) ListBatches() ([]models.BatchExecution, error) {
+	var batches []models.BatchExecution
+	err := s.db.Preload("Group").Order("created_at DESC").Find(&batches).Error
+	return batches, err
+}
+
+// CreateBatch 创建批量执行：为分组内每个成员生成一条子执行记录
+func (s *BatchService) CreateBatch(req *CreateBatchRequest, userID string) (*BatchDetail, error) {
+	group, err := s.GetGroup(req.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	if len(group.Members) == 0 {
+		return nil, fmt.Errorf("分组没有成员")
+	}
+
+	batch := &models.BatchExecution{
+		ID:              uuid.New().String(),
+		Kind:            models.BatchKindGroup,
+		GroupID:         group.ID,
+		TargetTableName: req.TableName,
+		DDLType:         req.DDLType,
+		OriginalDDL:     req.OriginalDDL,
+		ExecutionParams: req.ExecutionParams,
+		Concurrency:     req.Concurrency,
+		FailureMode:     req.FailureMode,
+		Status:          models.StatusPending,
+		TotalCount:      len(group.Members),
+		CreatedBy:       userID,
+	}
+	if batch.Concurrency <= 0 {
+		batch.Concurrency = 1
+	}
+	if batch.FailureMode == "" {
+		batch.FailureMode = models.BatchStopOnFailure
+	}
+
+	if err := s.db.Create(batch).Error; err != nil {
+		return nil, fmt.Errorf("创建批量执行失败: %v", err)
+	}
+
+	// 逐个成员创建子执行记录；任一失败则回滚已创建的记录
+	var created []string
+	for _, member := range group.Members {
+		record, err := s.executionService.Create(&CreateExecutionRequest{
+			ConnectionID:    member.ConnectionID,
+			TableName:       req.TableName,
+			DatabaseName:    member.DatabaseName,
+			DDLType:         req.DDLType,
+			OriginalDDL:     req.OriginalDDL,
+			ExecutionParams: req.ExecutionParams,
+			BatchID:         &batch.ID,
+		}, userID)
+		if err != nil {
+			if len(created) > 0 {
+				s.db.Delete(&models.ExecutionRecord{}, "id IN ?", created)
+			}
+			s.db.Delete(batch)
+			return nil, fmt.Errorf("成员 %s/%s 创建执行失败: %v", member.Connection.Name, member.DatabaseName, err)
+		}
+		created = append(created, record.ID)
+	}
+
+	return s.GetBatch(batch.ID)
+}
+
+// CreateFragmentBatch 扫描连接下的表碎片，为满足阈值的表逐一创建碎片整理执行
+func (s *BatchService) CreateFragmentBatch(req *CreateFragmentBatchRequest, userID string) (*BatchDetail, error) {
+	if req.MinFreeMB <= 0 && req.MinFreeRatio <= 0 && len(req.Tables) == 0 {
+		return nil, fmt.Errorf("请设置可回收空间或碎片率阈值，或指定需要重建的表")
+	}
+
+	scan, err := s.executionService.connectionService.ScanFragmentation(req.ConnectionID, &req.FragmentScanRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := scan.Tables
+	if len(req.Tables) > 0 {
+		selected := make(map[string]bool, len(req.Tables))
+		for _, name := range req.Tables {
+			selected[name] = true
+		}
+		candidates = nil
+		for _, table := range scan.Tables {
+			if selected[table.Database+"."+table.Table] {
+				candidates = append(candidates, table)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("没有满足条件的碎片表")
+	}
+
+	ddlType := models.DDLFragment
+	batch := &models.BatchExecution{
+		ID:              uuid.New().String(),
+		Kind:            models.BatchKindFragment,
+		ConnectionID:    &req.ConnectionID,
+		DDLType:         &ddlType,
+		ExecutionParams: req.ExecutionParams,
+		Concurrency:     req.Concurrency,
+		FailureMode:     req.FailureMode,
+		Status:          models.StatusPending,
+		TotalCount:      len(candidates),
+		CreatedBy:       userID,
+	}
+	if batch.Concurrency <= 0 {
+		batch.Concurrency = 1
+	}
+	if batch.FailureMode == "" {
+		batch.FailureMode = models.BatchContinue
+	}
+
+	if err := s.db.Create(batch).Error; err != nil {
+		return nil, fmt.Errorf("创建批量执行失败: %v", err)
+	}
+
+	// 按可回收空间从大到小创建子执行；任一失败则回滚已创建的记录
+	var created []string
+	for _, table := range candidates {
+		record, err := s.executionService.Create(&CreateExecutionRequest{
+			ConnectionID:    req.ConnectionID,
+			TableName:       table.Table,
+			DatabaseName:    table.Database,
+			DDLType:         &ddlType,
+			ExecutionParams: req.ExecutionParams,
+			BatchID:         &batch.ID,
+		}, userID)
+		if err != nil {
+			if len(created) > 0 {
+				s.db.Delete(&models.ExecutionRecord{}, "id IN ?", created)
+			}
+			s.db.Delete(batch)
+			return nil, fmt.Errorf("表 %s.%s 创建执行失败: %v", table.Database, table.Table, err)
+		}
+		created = append(created, record.ID)
+	}
+
+	return s.GetBatch(batch.ID)
+}
+
+// GetBatch 获取批量执行详情及聚合进度
+func (s *BatchService) GetBatch(id string) (*BatchDetail, error) {
+	var batch models.BatchExecution
+	err := s.db.Preload("Group").Preload("Executions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at")
+	}).Preload("Executions.Connection").First(&batch, "id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("批量执行不存在")
+		}
+		return nil, err
+	}
+
+	return &BatchDetail{
+		BatchExecution:    &batch,
+		AggregateProgress: s.aggregateProgress(batch.Executions),
+	}, nil
+}
+
+// StartBatch 启动批量执行
+func (s *BatchService) StartBatch(id string) error {
+	var batch models.BatchExecution
+	if err := s.db.First(&batch, "id = ?", id).Error; err != nil {
+		return fmt.Errorf("批量执行不存在")
+	}
+	if batch.Status != models.StatusPending {
+		return fmt.Errorf("当前状态无法启动: %s", batch.Status)
+	}
+
+	s.mutex.Lock()
+	if _, exists := s.dispatchers[id]; exists {
+		s.mutex.Unlock()
+		return fmt.Errorf("批量执行已在派发中")
+	}
+	stopCh := make(chan struct{})
+	s.dispatchers[id] = stopCh
+	s.mutex.Unlock()
+
+	now := time.Now()
+	batch.Status = models.StatusRunning
+	batch.StartTime = &now
+	if err := s.db.Save(&batch).Error; err != nil {
+		s.removeDispatcher(id)
+		return fmt.Errorf("更新批量执行状态失败: %v", err)
+	}
+
+	go s.dispatch(&batch, stopCh)
+	return nil
+}
+
+// CancelBatch 取消批量执行：停止派发并停止运行中的子任务
+func (s *BatchService) CancelBatch(id string) error {
+	var batch models.BatchExecution
+	if err := s.db.First(&batch, "id = ?", id).Error; err != nil {
+		return fmt.Errorf("批量执行不存在")
+	}
+	if batch.IsFinished() {
+		return fmt.Errorf("当前状态无法取消: %s", batch.Status)
+	}
+
+	s.mutex.Lock()
+	if stopCh, exists := s.dispatchers[id]; exists {
+		close(stopCh)
+		delete(s.dispatchers, id)
+	}
+	s.mutex.Unlock()
+
+	var records []models.ExecutionRecord
+	s.db.Where("batch_id = ? AND status IN ?", id,
+		[]models.ExecutionStatus{models.StatusPending, models.StatusRunning}).Find(&records)
+	for _, record := range records {
+		if record.Status == models.StatusRunning {
+			if err := s.executionEngine.StopExecution(record.ID, ""); err != nil {
+				s.executionService.Stop(record.ID, "")
+			}
+		}
+	}
+	s.cancelPendingChildren(id, "批量执行取消")
+
+	return s.finishBatch(id, models.StatusCancelled)
+}
+
+// dispatch 按并发上限派发子任务并等待全部结束
+func (s *BatchService) dispatch(batch *models.BatchExecution, stopCh chan struct{}) {
+	defer s.removeDispatcher(batch.ID)
+
+	var records []models.ExecutionRecord
+	s.db.Where("batch_id = ? AND status = ?", batch.ID, models.StatusPending).
+		Order("created_at").Find(&records)
+
+	sem := make(chan struct{}, batch.Concurrency)
+	var wg sync.WaitGroup
+	var failed bool
+	var failedMutex sync.Mutex
+
+	stopped := func() bool {
+		select {
+		case <-stopCh:
+			return true
+		default:
+		}
+		failedMutex.Lock()
+		defer failedMutex.Unlock()
+		return failed && batch.FailureMode == models.BatchStopOnFailure
+	}
+
+	for _, record := range records {
+		// 等待空闲槽位
+		select {
+		case sem <- struct{}{}:
+		case <-stopCh:
+		}
+		if stopped() {
+			break
+		}
+
+		wg.Add(1)
+		go func(recordID string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			status := s.runChild(recordID, stopCh)
+			if status == models.StatusFailed {
+				failedMutex.Lock()
+				failed = true
+				failedMutex.Unlock()
+			}
+		}(record.ID)
+	}
+	wg.Wait()
+
+	// 取消的批次由 CancelBatch 负责收尾
+	select {
+	case <-stopCh:
+		return
+	default:
+	}
+
+	// 未派发的子任务（首个失败后停止）标记为取消
+	s.cancelPendingChildren(batch.ID, "批量执行取消")
+
+	final := models.StatusCompleted
+	if failed {
+		final = models.StatusFailed
+	}
+	s.finishBatch(batch.ID, final)
+}
+
+// runChild 启动子任务并等待其结束，返回最终状态
+func (s *BatchService) runChild(recordID string, stopCh chan struct{}) models.ExecutionStatus {
+	if err := s.executionEngine.StartExecution(recordID, nil); err != nil {
+		errorMsg := fmt.Sprintf("批量派发失败: %v", err)
+		s.db.Model(&models.ExecutionRecord{}).Where("id = ?", recordID).Updates(map[string]interface{}{
+			"status":        models.StatusFailed,
+			"error_message": errorMsg,
+		})
+		return models.StatusFailed
+	}
+
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return models.StatusCancelled
+		case <-ticker.C:
+			var record models.ExecutionRecord
+			if err := s.db.Select("id", "status").First(&record, "id = ?", recordID).Error; err != nil {
+				return models.StatusFailed
+			}
+			if record.IsFinished() {
+				return record.Status
+			}
+		}
+	}
+}
+
+// RecoverInterrupted 服务启动时收尾上次进程中断的批量执行
+// 派发状态只保存在内存中，重启后无法继续派发：取消未开始的子任务并将批次标记为失败，
+// 已在执行的子任务不做处理
+func (s *BatchService) RecoverInterrupted() {
+	var batches []models.BatchExecution
+	if err := s.db.Select("id").Where("status = ?", models.StatusRunning).Find(&batches).Error; err != nil {
+		return
+	}
+	for _, batch := range batches {
+		s.cancelPendingChildren(batch.ID, "服务重启，批量派发中断")
+		now := time.Now()
+		s.db.Model(&models.BatchExecution{}).Where("id = ? AND status = ?", batch.ID, models.StatusRunning).Updates(map[string]interface{}{
+			"status":        models.StatusFailed,
+			"end_time":      &now,
+			"error_message": "服务重启导致批量派发中断，未开始的子任务已取消，可针对剩余目标重新创建批量执行",
+		})
+	}
+}
+
+// cancelPendingChildren 将尚未开始的子任务标记为取消
+func (s *BatchService) cancelPendingChildren(batchID string, reason string) {
+	var records []models.ExecutionRecord
+	s.db.Select("id", "status", "attempts").
+		Where("batch_id = ? AND status = ?", batchID, models.StatusPending).Find(&records)
+	for i := range records {
+		record := &records[i]
+		if err := s.db.Model(record).Update("status", models.StatusCancelled).Error; err != nil {
+			continue
+		}
+		recordExecutionEvent(s.db, record, models.StatusPending, "", reason)
+	}
+}
+
+// finishBatch 更新批量执行的最终状态
+func (s *BatchService) finishBatch(batchID string, status models.ExecutionStatus) error {
+	now := time.Now()
+	return s.db.Model(&models.BatchExecution{}).Where("id = ?", batchID).Updates(map[string]interface{}{
+		"status":   status,
+		"end_time": &now,
+	}).Error
+}
+
+// removeDispatcher 移除派发登记
+func (s *BatchService) removeDispatcher(batchID string) {
+	s.mutex.Lock()
+	delete(s.dispatchers, batchID)
+	s.mutex.Unlock()
+}
+
+// aggregateProgress 汇总子任务进度（运行中的任务优先使用引擎实时进度）
+func (s *BatchService) aggregateProgress(records []models.ExecutionRecord) BatchProgress {
+	progress := BatchProgress{Total: len(records)}
+	if len(records) == 0 {
+		return progress
+	}
+
+	var sum float64
+	for i := range records {
+		record := &records[i]
+		switch record.Status {
+		case models.StatusPending:
+			progress.Pending++
+		case models.StatusRunning:
+			progress.Running++
+			if task, err := s.executionEngine.GetTaskStatus(record.ID); err == nil {
+				sum += task.Progress
+			} else {
+				sum += record.GetProgress()
+			}
+		case models.StatusCompleted, models.StatusCompletedWithWarnings:
+			progress.Completed++
+			sum += 100
+			if record.ReclaimedBytes != nil {
+				progress.ReclaimedBytes += *record.ReclaimedBytes
+			}
+		case models.StatusFailed:
+			progress.Failed++
+		case models.StatusCancelled:
+			progress.Cancelled++
+		}
+	}
+	progress.Progress = sum / float64(len(records))
+
+	return progress
+}
+
+// validateMembers 校验分组成员
+func (s *BatchService) validateMembers(members []GroupMemberRequest) error {
+	seen := make(map[string]bool)
+	for _, m := range members {
+		key := m.ConnectionID + "/" + m.DatabaseName
+		if seen[key] {
+			return fmt.Errorf("成员重复: %s", m.DatabaseName)
+		}
+		seen[key] = true
+
+		var count int64
+		s.db.Model(&models.Connection{}).Where("id = ?", m.ConnectionID).Count(&count)
+		if count == 0 {
+			return fmt.Errorf("连接不存在: %s", m.ConnectionID)
+		}
+	}
+	return nil
+}
+
+// buildGroupMembers 将请求转换为分组成员模型
+func buildGroupMembers(members []GroupMemberRequest) []models.ConnectionGroupMember {
+	result := make([]models.ConnectionGroupMember, 0, len(members))
+	for i, m := range members {
+		result = append(result, models.ConnectionGroupMember{
+			ID:           uuid.New().String(),
+			ConnectionID: m.ConnectionID,
+			DatabaseName: m.DatabaseName,
+			SortOrder:    i,
+		})
+	}
+	return result
+}
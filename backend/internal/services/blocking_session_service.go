@@ -0,0 +1,167 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fengzhencai/MySQLer/backend/internal/config"
+	"github.com/fengzhencai/MySQLer/backend/internal/models"
+	"github.com/fengzhencai/MySQLer/backend/internal/utils"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// BlockingSessionService 运行中执行的阻塞会话查看与终止
+type BlockingSessionService struct {
+	db     *gorm.DB
+	cfg    *config.Config
+	crypto *utils.CryptoService
+	audit  *AuditService
+}
+
+// NewBlockingSessionService 创建阻塞会话服务
+func NewBlockingSessionService(db *gorm.DB, cfg *config.Config) *BlockingSessionService {
+	return &BlockingSessionService{
+		db:     db,
+		cfg:    cfg,
+		crypto: utils.NewCryptoService(cfg.EncryptionKey),
+		audit:  NewAuditService(db, cfg),
+	}
+}
+
+// KillSessionsRequest 终止会话请求
+type KillSessionsRequest struct {
+	SessionIDs []int64 `json:"session_ids" binding:"required,min=1"`
+	Reason     string  `json:"reason" binding:"required"`
+}
+
+// KillSessionResult 单个会话的终止结果
+type KillSessionResult struct {
+	ThreadID int64                  `json:"thread_id"`
+	Killed   bool                   `json:"killed"`
+	Error    string                 `json:"error,omitempty"`
+	Session  *utils.BlockingSession `json:"session,omitempty"`
+}
+
+// List 列出阻塞执行目标表的会话
+func (s *BlockingSessionService) List(executionID string) (*utils.BlockingSessionList, error) {
+	record, dbConn, err := s.load(executionID)
+	if err != nil {
+		return nil, err
+	}
+	return utils.ListBlockingSessions(dbConn, record.DatabaseName, record.TargetTableName,
+		longTrxSeconds(record.ExecutionParams, s.cfg))
+}
+
+// Kill 终止选中的阻塞会话；仅允许终止当前仍在阻塞目标表的会话，每个会话单独记录审计
+func (s *BlockingSessionService) Kill(executionID string, req *KillSessionsRequest, userID string) ([]KillSessionResult, error) {
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, fmt.Errorf("请填写终止会话的原因")
+	}
+
+	record, dbConn, err := s.load(executionID)
+	if err != nil {
+		return nil, err
+	}
+
+	blocking, err := utils.ListBlockingSessions(dbConn, record.DatabaseName, record.TargetTableName,
+		longTrxSeconds(record.ExecutionParams, s.cfg))
+	if err != nil {
+		return nil, err
+	}
+	sessions := make(map[int64]utils.BlockingSession, len(blocking.Sessions))
+	for _, session := range blocking.Sessions {
+		sessions[session.ThreadID] = session
+	}
+
+	results := make([]KillSessionResult, 0, len(req.SessionIDs))
+	for _, id := range req.SessionIDs {
+		result := KillSessionResult{ThreadID: id}
+		listed, ok := sessions[id]
+		if !ok {
+			result.Error = fmt.Sprintf("会话 %d 未阻塞 %s.%s 或已结束", id, record.DatabaseName, record.TargetTableName)
+			results = append(results, result)
+			continue
+		}
+
+		killed, killErr := utils.KillSession(dbConn, id)
+		if killed == nil {
+			killed = &listed
+		} else {
+			killed.Reason = listed.Reason
+			killed.MetadataLock = listed.MetadataLock
+			killed.TrxSeconds = listed.TrxSeconds
+		}
+		result.Session = killed
+		result.Killed = killErr == nil
+		if killErr != nil {
+			result.Error = killErr.Error()
+		}
+		s.recordAudit(record, userID, req.Reason, killed, killErr)
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// load 读取运行中的执行记录并建立目标库连接
+func (s *BlockingSessionService) load(executionID string) (*models.ExecutionRecord, *utils.DatabaseConnection, error) {
+	var record models.ExecutionRecord
+	if err := s.db.Preload("Connection").First(&record, "id = ?", executionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, fmt.Errorf("执行记录不存在")
+		}
+		return nil, nil, err
+	}
+	if record.Status != models.StatusRunning {
+		return nil, nil, fmt.Errorf("仅执行中的任务可以查看或终止阻塞会话")
+	}
+
+	dbConn, err := buildDatabaseConnection(s.crypto, &record.Connection, record.DatabaseName)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &record, dbConn, nil
+}
+
+// recordAudit 记录终止会话的审计日志（含被终止会话的语句）
+func (s *BlockingSessionService) recordAudit(record *models.ExecutionRecord, userID, reason string, session *utils.BlockingSession, killErr error) {
+	auditLog := &models.AuditLog{
+		UserID:       &userID,
+		Action:       string(models.ActionSessionKill),
+		ResourceType: stringPtr("execution"),
+		ResourceID:   &record.ID,
+		Status:       models.AuditStatusSuccess,
+		RiskLevel:    "high",
+		CreatedAt:    time.Now(),
+	}
+	if killErr != nil {
+		auditLog.Status = models.AuditStatusFailed
+		errorMsg := killErr.Error()
+		auditLog.ErrorMsg = &errorMsg
+	}
+
+	data := map[string]interface{}{
+		"connection_id": record.ConnectionID,
+		"database":      record.DatabaseName,
+		"table":         record.TargetTableName,
+		"reason":        reason,
+		"thread_id":     session.ThreadID,
+		"user":          session.User,
+		"host":          session.Host,
+		"db":            session.DB,
+		"command":       session.Command,
+		"state":         session.State,
+		"query":         session.Query,
+		"blocking":      session.Reason,
+	}
+	if raw, err := json.Marshal(data); err == nil {
+		payload := json.RawMessage(raw)
+		auditLog.RequestData = &payload
+	}
+	if err := s.audit.CreateAuditLog(auditLog); err != nil {
+		logrus.Errorf("记录审计日志失败: %v", err)
+	}
+}
@@ -0,0 +1,437 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fengzhencai/MySQLer/backend/internal/config"
+	"github.com/fengzhencai/MySQLer/backend/internal/models"
+	"github.com/fengzhencai/MySQLer/backend/internal/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ChangeService 跨环境变更发布服务
+type ChangeService struct {
+	db               *gorm.DB
+	cfg              *config.Config
+	crypto           *utils.CryptoService
+	executionService *ExecutionService
+	executionEngine  *ExecutionEngine
+	snapshots        *SnapshotService
+}
+
+// NewChangeService 创建变更发布服务
+func NewChangeService(db *gorm.DB, cfg *config.Config, executionService *ExecutionService, executionEngine *ExecutionEngine) *ChangeService {
+	return &ChangeService{
+		db:               db,
+		cfg:              cfg,
+		crypto:           utils.NewCryptoService(cfg.EncryptionKey),
+		executionService: executionService,
+		executionEngine:  executionEngine,
+		snapshots:        NewSnapshotService(db),
+	}
+}
+
+// ChangeStageRequest 发布阶段请求
+type ChangeStageRequest struct {
+	ConnectionID string `json:"connection_id" binding:"required,uuid4"`
+	DatabaseName string `json:"database_name" binding:"required,min=1,max=100"`
+}
+
+// CreateChangeRequest 创建变更请求
+type CreateChangeRequest struct {
+	Title           string                  `json:"title" binding:"required,min=1,max=200"`
+	TableName       string                  `json:"table_name" binding:"required,min=1,max=200"`
+	DDLType         *models.DDLType         `json:"ddl_type" binding:"required"`
+	OriginalDDL     *string                 `json:"original_ddl" binding:"omitempty,max=2000"`
+	ExecutionParams *models.ExecutionParams `json:"execution_params"`
+	Stages          []ChangeStageRequest    `json:"stages" binding:"required,min=1,dive"`
+}
+
+// ListChanges 获取变更列表
+func (s *ChangeService) ListChanges() ([]models.SchemaChange, error) {
+	var changes []models.SchemaChange
+	err := s.db.Preload("Stages", func(db *gorm.DB) *gorm.DB {
+		return db.Order("stage_order")
+	}).Order("created_at DESC").Find(&changes).Error
+	return changes, err
+}
+
+// GetChange 获取变更详情（同步各阶段执行状态）
+func (s *ChangeService) GetChange(id string) (*models.SchemaChange, error) {
+	change, err := s.loadChange(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.refresh(change); err != nil {
+		return nil, err
+	}
+	return s.loadChange(id)
+}
+
+// CreateChange 创建变更及其发布阶段，阶段顺序须符合配置的环境顺序
+func (s *ChangeService) CreateChange(req *CreateChangeRequest, userID string) (*models.SchemaChange, error) {
+	if *req.DDLType != models.DDLFragment && (req.OriginalDDL == nil || strings.TrimSpace(*req.OriginalDDL) == "") {
+		return nil, fmt.Errorf("自定义DDL时原始DDL语句不能为空")
+	}
+
+	change := &models.SchemaChange{
+		ID:              uuid.New().String(),
+		Title:           req.Title,
+		TargetTableName: req.TableName,
+		DDLType:         req.DDLType,
+		OriginalDDL:     req.OriginalDDL,
+		ExecutionParams: req.ExecutionParams,
+		Status:          models.ChangePending,
+		CreatedBy:       userID,
+	}
+
+	seen := make(map[string]bool)
+	lastRank := -1
+	for i, stage := range req.Stages {
+		key := stage.ConnectionID + "/" + stage.DatabaseName
+		if seen[key] {
+			return nil, fmt.Errorf("阶段重复: %s", stage.DatabaseName)
+		}
+		seen[key] = true
+
+		var connection models.Connection
+		if err := s.db.First(&connection, "id = ?", stage.ConnectionID).Error; err != nil {
+			return nil, fmt.Errorf("连接不存在: %s", stage.ConnectionID)
+		}
+
+		rank := s.environmentRank(connection.Environment)
+		if rank < 0 {
+			return nil, fmt.Errorf("连接 %s 的环境 %s 不在发布顺序 %s 中",
+				connection.Name, connection.Environment, strings.Join(s.cfg.PromotionEnvironments, " → "))
+		}
+		if rank < lastRank {
+			return nil, fmt.Errorf("阶段 %d（%s）违反发布顺序 %s",
+				i+1, connection.Environment, strings.Join(s.cfg.PromotionEnvironments, " → "))
+		}
+		lastRank = rank
+
+		change.Stages = append(change.Stages, models.ChangeStage{
+			ID:           uuid.New().String(),
+			StageOrder:   i,
+			Environment:  connection.Environment,
+			ConnectionID: stage.ConnectionID,
+			DatabaseName: stage.DatabaseName,
+			Status:       models.StatusPending,
+		})
+	}
+
+	if err := s.db.Create(change).Error; err != nil {
+		return nil, fmt.Errorf("创建变更失败: %v", err)
+	}
+	return s.loadChange(change.ID)
+}
+
+// Promote 启动下一个发布阶段：前一阶段必须已成功完成且其表结构与预期一致
+func (s *ChangeService) Promote(id string, userID string) (*models.SchemaChange, error) {
+	change, err := s.loadChange(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.refresh(change); err != nil {
+		return nil, err
+	}
+	if change.IsFinished() {
+		return nil, fmt.Errorf("当前状态无法继续发布: %s", change.Status)
+	}
+
+	stage := s.nextStage(change)
+	if stage == nil {
+		return nil, fmt.Errorf("所有阶段均已完成")
+	}
+	if stage.Status == models.StatusRunning {
+		return nil, fmt.Errorf("阶段 %d（%s）正在执行中", stage.StageOrder+1, stage.Environment)
+	}
+
+	// 校验前一阶段
+	if stage.StageOrder > 0 {
+		prev := &change.Stages[stage.StageOrder-1]
+		if !prev.Status.Succeeded() {
+			return nil, fmt.Errorf("前一阶段（%s）尚未成功完成", prev.Environment)
+		}
+		if err := s.verifyStage(change, prev); err != nil {
+			return nil, err
+		}
+	}
+
+	// 条件更新抢占阶段，并发的发布请求只有一个能成功
+	now := time.Now()
+	previous := stage.Status
+	claim := s.db.Model(&models.ChangeStage{}).
+		Where("id = ? AND status IN ?", stage.ID, []models.ExecutionStatus{models.StatusPending, models.StatusFailed, models.StatusCancelled}).
+		Updates(map[string]interface{}{
+			"status":        models.StatusRunning,
+			"execution_id":  nil, // 避免并发刷新按上次执行结果改回阶段状态
+			"started_at":    now,
+			"completed_at":  nil,
+			"result_schema": nil,
+		})
+	if claim.Error != nil {
+		return nil, fmt.Errorf("更新发布阶段失败: %v", claim.Error)
+	}
+	if claim.RowsAffected == 0 {
+		return nil, fmt.Errorf("阶段 %d（%s）正在执行中或已被其他请求发布", stage.StageOrder+1, stage.Environment)
+	}
+
+	record, err := s.executionService.Create(&CreateExecutionRequest{
+		ConnectionID:    stage.ConnectionID,
+		TableName:       change.TargetTableName,
+		DatabaseName:    stage.DatabaseName,
+		DDLType:         change.DDLType,
+		OriginalDDL:     change.OriginalDDL,
+		ExecutionParams: change.ExecutionParams,
+		ChangeID:        &change.ID,
+	}, userID)
+	// release 执行未启动时释放抢占，恢复阶段原状态，之后可再次发布
+	previousExecutionID := stage.ExecutionID
+	release := func() {
+		s.db.Model(&models.ChangeStage{}).Where("id = ?", stage.ID).Updates(map[string]interface{}{
+			"status":       previous,
+			"execution_id": previousExecutionID,
+		})
+	}
+	if err != nil {
+		release()
+		return nil, fmt.Errorf("阶段 %d（%s）创建执行失败: %v", stage.StageOrder+1, stage.Environment, err)
+	}
+
+	stage.ExecutionID = &record.ID
+	stage.Status = models.StatusRunning
+	stage.StartedAt = &now
+	stage.CompletedAt = nil
+	stage.ResultSchema = nil
+	if err := s.db.Model(&models.ChangeStage{}).Where("id = ?", stage.ID).Update("execution_id", record.ID).Error; err != nil {
+		return nil, fmt.Errorf("更新发布阶段失败: %v", err)
+	}
+
+	if err := s.executionEngine.StartExecution(record.ID, nil); err != nil {
+		errorMsg := fmt.Sprintf("发布派发失败: %v", err)
+		s.db.Model(&models.ExecutionRecord{}).Where("id = ?", record.ID).Updates(map[string]interface{}{
+			"status":        models.StatusFailed,
+			"error_message": errorMsg,
+		})
+		release()
+		return nil, fmt.Errorf("%s", errorMsg)
+	}
+
+	s.db.Model(change).Updates(map[string]interface{}{
+		"status":        models.ChangeInProgress,
+		"current_stage": stage.StageOrder,
+	})
+
+	return s.loadChange(id)
+}
+
+// VerifyStage 手动校验某一阶段的表结构是否与预期一致
+func (s *ChangeService) VerifyStage(id string, order int) (*models.ChangeStage, error) {
+	change, err := s.GetChange(id)
+	if err != nil {
+		return nil, err
+	}
+	if order < 0 || order >= len(change.Stages) {
+		return nil, fmt.Errorf("阶段不存在")
+	}
+	stage := &change.Stages[order]
+	if !stage.Status.Succeeded() {
+		return nil, fmt.Errorf("阶段尚未完成，无法校验")
+	}
+
+	// 校验结果记录在阶段上，不一致时同样返回阶段详情
+	s.verifyStage(change, stage)
+	return stage, nil
+}
+
+// Cancel 取消变更：停止正在执行的阶段，剩余阶段不再发布
+func (s *ChangeService) Cancel(id string) error {
+	change, err := s.loadChange(id)
+	if err != nil {
+		return err
+	}
+	if change.IsFinished() {
+		return fmt.Errorf("当前状态无法取消: %s", change.Status)
+	}
+
+	for i := range change.Stages {
+		stage := &change.Stages[i]
+		switch stage.Status {
+		case models.StatusRunning:
+			if stage.ExecutionID != nil {
+				if err := s.executionEngine.StopExecution(*stage.ExecutionID, ""); err != nil {
+					s.executionService.Stop(*stage.ExecutionID, "")
+				}
+			}
+			s.db.Model(stage).Update("status", models.StatusCancelled)
+		case models.StatusPending:
+			s.db.Model(stage).Update("status", models.StatusCancelled)
+		}
+	}
+
+	return s.db.Model(change).Update("status", models.ChangeCancelled).Error
+}
+
+// loadChange 加载变更及其阶段
+func (s *ChangeService) loadChange(id string) (*models.SchemaChange, error) {
+	var change models.SchemaChange
+	err := s.db.Preload("Stages", func(db *gorm.DB) *gorm.DB {
+		return db.Order("stage_order")
+	}).Preload("Stages.Connection").Preload("Stages.Execution").First(&change, "id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("变更不存在")
+		}
+		return nil, err
+	}
+	return &change, nil
+}
+
+// refresh 根据关联执行记录同步阶段与变更状态
+func (s *ChangeService) refresh(change *models.SchemaChange) error {
+	if change.Status == models.ChangeCancelled {
+		return nil
+	}
+
+	for i := range change.Stages {
+		stage := &change.Stages[i]
+		if stage.Status != models.StatusRunning || stage.Execution == nil {
+			continue
+		}
+		record := stage.Execution
+		if !record.IsFinished() {
+			continue
+		}
+
+		updates := map[string]interface{}{"status": record.Status}
+		stage.Status = record.Status
+		if record.Status.Succeeded() {
+			stage.CompletedAt = record.EndTime
+			updates["completed_at"] = record.EndTime
+			// 执行后快照即为该阶段的结果结构
+			if snapshot, err := s.snapshots.GetSnapshot(record.ID, models.SnapshotAfter); err == nil {
+				stage.ResultSchema = &snapshot.CreateTableSQL
+				updates["result_schema"] = snapshot.CreateTableSQL
+			}
+		}
+		if err := s.db.Model(&models.ChangeStage{}).Where("id = ?", stage.ID).Updates(updates).Error; err != nil {
+			return fmt.Errorf("更新发布阶段失败: %v", err)
+		}
+	}
+
+	status := models.ChangePending
+	current := len(change.Stages)
+	completed := 0
+	for i := range change.Stages {
+		stage := &change.Stages[i]
+		switch stage.Status {
+		case models.StatusCompleted, models.StatusCompletedWithWarnings:
+			completed++
+		case models.StatusFailed, models.StatusCancelled:
+			status = models.ChangeFailed
+		}
+		if !stage.Status.Succeeded() && i < current {
+			current = i
+		}
+	}
+	if status != models.ChangeFailed {
+		switch {
+		case completed == len(change.Stages):
+			status = models.ChangeCompleted
+		case completed > 0 || (current < len(change.Stages) && change.Stages[current].Status == models.StatusRunning):
+			status = models.ChangeInProgress
+		}
+	}
+
+	change.Status = status
+	change.CurrentStage = current
+	return s.db.Model(change).Updates(map[string]interface{}{
+		"status":        status,
+		"current_stage": current,
+	}).Error
+}
+
+// nextStage 返回第一个未成功完成的阶段
+func (s *ChangeService) nextStage(change *models.SchemaChange) *models.ChangeStage {
+	for i := range change.Stages {
+		if !change.Stages[i].Status.Succeeded() {
+			return &change.Stages[i]
+		}
+	}
+	return nil
+}
+
+// verifyStage 校验阶段当前表结构与预期结构（首个阶段的执行结果）一致
+func (s *ChangeService) verifyStage(change *models.SchemaChange, stage *models.ChangeStage) error {
+	reference := change.Stages[0].ResultSchema
+	if stage.StageOrder == 0 {
+		reference = stage.ResultSchema
+	}
+
+	message, err := s.compareWithReference(change.TargetTableName, stage, reference)
+
+	now := time.Now()
+	stage.VerifiedAt = &now
+	stage.VerifyMessage = &message
+	s.db.Model(&models.ChangeStage{}).Where("id = ?", stage.ID).Updates(map[string]interface{}{
+		"verified_at":    &now,
+		"verify_message": message,
+	})
+
+	return err
+}
+
+// compareWithReference 对比阶段表结构与预期结构，返回校验说明
+func (s *ChangeService) compareWithReference(table string, stage *models.ChangeStage, reference *string) (string, error) {
+	if reference == nil || *reference == "" {
+		err := fmt.Errorf("阶段（%s）缺少执行后结构快照，无法确认预期结构", stage.Environment)
+		return err.Error(), err
+	}
+	expected, err := utils.ParseCreateTable(*reference)
+	if err != nil {
+		err = fmt.Errorf("解析预期结构失败: %v", err)
+		return err.Error(), err
+	}
+
+	var connection models.Connection
+	if err := s.db.First(&connection, "id = ?", stage.ConnectionID).Error; err != nil {
+		err = fmt.Errorf("连接不存在: %s", stage.ConnectionID)
+		return err.Error(), err
+	}
+	dbConn, err := buildDatabaseConnection(s.crypto, &connection, stage.DatabaseName)
+	if err != nil {
+		return err.Error(), err
+	}
+	current, err := loadTableDefinition(dbConn, stage.DatabaseName, table)
+	if err != nil {
+		err = fmt.Errorf("读取阶段（%s）表结构失败: %v", stage.Environment, err)
+		return err.Error(), err
+	}
+
+	diff := utils.DiffTableDefinitions(current, expected, table)
+	if len(diff.Changes) == 0 {
+		return "表结构与预期一致", nil
+	}
+
+	details := make([]string, 0, len(diff.Changes))
+	for _, change := range diff.Changes {
+		details = append(details, fmt.Sprintf("%s %s %s", change.Action, change.Object, change.Name))
+	}
+	err = fmt.Errorf("阶段（%s）表结构与预期不一致: %s", stage.Environment, strings.Join(details, "; "))
+	return err.Error(), err
+}
+
+// environmentRank 返回环境在发布顺序中的位置，不在顺序中返回 -1
+func (s *ChangeService) environmentRank(env models.Environment) int {
+	for i, name := range s.cfg.PromotionEnvironments {
+		if strings.EqualFold(name, string(env)) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,283 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fengzhencai/MySQLer/backend/internal/config"
+	"github.com/fengzhencai/MySQLer/backend/internal/models"
+	"github.com/fengzhencai/MySQLer/backend/internal/utils"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// ColumnBackupService 删除列数据备份与恢复服务
+type ColumnBackupService struct {
+	db     *gorm.DB
+	cfg    *config.Config
+	crypto *utils.CryptoService
+	audit  *AuditService
+}
+
+// NewColumnBackupService 创建列备份服务
+func NewColumnBackupService(db *gorm.DB, cfg *config.Config) *ColumnBackupService {
+	return &ColumnBackupService{
+		db:     db,
+		cfg:    cfg,
+		crypto: utils.NewCryptoService(cfg.EncryptionKey),
+		audit:  NewAuditService(db, cfg),
+	}
+}
+
+// RestoreColumnsRequest 恢复列数据请求
+type RestoreColumnsRequest struct {
+	ColumnMap map[string]string `json:"column_map"` // 备份列 -> 目标列，为空时按原列名恢复
+	BatchSize int               `json:"batch_size"`
+	Reason    string            `json:"reason"` // 恢复原因，记入审计日志
+}
+
+// DroppedColumns 解析执行中被删除的列
+func DroppedColumns(record *models.ExecutionRecord) []string {
+	if record.OriginalDDL == nil || *record.OriginalDDL == "" {
+		return nil
+	}
+	if record.DDLType != nil && *record.DDLType == models.DDLFragment {
+		return nil
+	}
+
+	cleaned, err := utils.CleanAlterSQL(*record.OriginalDDL)
+	if err != nil {
+		return nil
+	}
+
+	var columns []string
+	for _, clause := range utils.ParseAlterClauses(cleaned) {
+		if clause.Kind == utils.AlterDropColumn {
+			columns = append(columns, clause.Name)
+		}
+	}
+	return columns
+}
+
+// Backup 导出执行中被删除列的数据（主键 + 被删除列），无删除列时返回 nil
+func (s *ColumnBackupService) Backup(record *models.ExecutionRecord, dbConn *utils.DatabaseConnection) (*models.ColumnBackup, error) {
+	columns := DroppedColumns(record)
+	if len(columns) == 0 {
+		return nil, nil
+	}
+
+	createSQL, err := utils.GetCreateTable(dbConn, record.DatabaseName, record.TargetTableName)
+	if err != nil {
+		return nil, err
+	}
+	definition, err := utils.ParseCreateTable(createSQL)
+	if err != nil {
+		return nil, err
+	}
+	pk := definition.PrimaryKey()
+	if pk == nil {
+		return nil, fmt.Errorf("表 %s 缺少主键，无法备份列数据", record.TargetTableName)
+	}
+
+	format := utils.BackupFormatCSV
+	if record.ExecutionParams != nil && record.ExecutionParams.BackupFormat == utils.BackupFormatSQL {
+		format = utils.BackupFormatSQL
+	}
+
+	fileName := fmt.Sprintf("%s_%s_%s_%s.%s.gz", record.DatabaseName, record.TargetTableName,
+		record.ID[:8], time.Now().Format("20060102150405"), format)
+	path := filepath.Join(s.cfg.BackupDir, fileName)
+
+	result, err := utils.BackupColumns(dbConn, record.DatabaseName, record.TargetTableName, pk.Columns, columns, format, path)
+	if err != nil {
+		return nil, err
+	}
+
+	backup := &models.ColumnBackup{
+		ExecutionID:     record.ID,
+		DatabaseName:    record.DatabaseName,
+		TargetTableName: record.TargetTableName,
+		KeyColumns:      strings.Join(pk.Columns, ","),
+		Columns:         strings.Join(columns, ","),
+		Format:          format,
+		FilePath:        result.FilePath,
+		Checksum:        result.Checksum,
+		FileSize:        result.FileSize,
+		RowCount:        result.Rows,
+	}
+
+	// 重试时覆盖之前的备份记录
+	s.db.Where("execution_id = ?", record.ID).Delete(&models.ColumnBackup{})
+	if err := s.db.Create(backup).Error; err != nil {
+		return nil, fmt.Errorf("保存备份记录失败: %v", err)
+	}
+
+	record.BackupFile = &backup.FilePath
+	record.BackupChecksum = &backup.Checksum
+	return backup, nil
+}
+
+// GetBackup 获取执行的列数据备份
+func (s *ColumnBackupService) GetBackup(executionID string) (*models.ColumnBackup, error) {
+	var backup models.ColumnBackup
+	if err := s.db.Where("execution_id = ?", executionID).Order("id DESC").First(&backup).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("该执行没有列数据备份")
+		}
+		return nil, err
+	}
+	return &backup, nil
+}
+
+// ListRestoreJobs 获取执行的恢复任务
+func (s *ColumnBackupService) ListRestoreJobs(executionID string) ([]models.ColumnRestoreJob, error) {
+	var jobs []models.ColumnRestoreJob
+	err := s.db.Where("execution_id = ?", executionID).Order("created_at DESC").Find(&jobs).Error
+	return jobs, err
+}
+
+// StartRestore 创建恢复任务，将备份值按主键写回（目标列需已重新添加）
+func (s *ColumnBackupService) StartRestore(executionID string, req *RestoreColumnsRequest, userID string) (*models.ColumnRestoreJob, error) {
+	if req == nil || strings.TrimSpace(req.Reason) == "" {
+		return nil, fmt.Errorf("请填写恢复列数据的原因")
+	}
+
+	backup, err := s.GetBackup(executionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 校验备份文件完整性
+	checksum, err := utils.FileChecksum(backup.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("读取备份文件失败: %v", err)
+	}
+	if checksum != backup.Checksum {
+		return nil, fmt.Errorf("备份文件校验失败，文件可能已被修改")
+	}
+
+	var running int64
+	s.db.Model(&models.ColumnRestoreJob{}).
+		Where("execution_id = ? AND status IN ?", executionID, []models.RestoreJobStatus{models.RestorePending, models.RestoreRunning}).
+		Count(&running)
+	if running > 0 {
+		return nil, fmt.Errorf("该执行已有进行中的恢复任务")
+	}
+
+	var record models.ExecutionRecord
+	if err := s.db.Preload("Connection").First(&record, "id = ?", executionID).Error; err != nil {
+		return nil, err
+	}
+	dbConn, err := buildDatabaseConnection(s.crypto, &record.Connection, backup.DatabaseName)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装列映射并确认目标列存在
+	columnMap := map[string]string{}
+	for _, c := range strings.Split(backup.Columns, ",") {
+		columnMap[c] = c
+	}
+	for from, to := range req.ColumnMap {
+		if _, ok := columnMap[from]; !ok {
+			return nil, fmt.Errorf("备份中不存在列 %s", from)
+		}
+		columnMap[from] = to
+	}
+
+	createSQL, err := utils.GetCreateTable(dbConn, backup.DatabaseName, backup.TargetTableName)
+	if err != nil {
+		return nil, err
+	}
+	definition, err := utils.ParseCreateTable(createSQL)
+	if err != nil {
+		return nil, err
+	}
+	for _, target := range columnMap {
+		if definition.FindColumn(target) == nil {
+			return nil, fmt.Errorf("目标列 %s 不存在，请先重新添加该列", target)
+		}
+	}
+
+	mapJSON, _ := json.Marshal(columnMap)
+	job := &models.ColumnRestoreJob{
+		ID:          uuid.New().String(),
+		ExecutionID: executionID,
+		BackupID:    backup.ID,
+		ColumnMap:   string(mapJSON),
+		Status:      models.RestorePending,
+		CreatedBy:   userID,
+	}
+	if err := s.db.Create(job).Error; err != nil {
+		return nil, fmt.Errorf("创建恢复任务失败: %v", err)
+	}
+
+	go s.runRestore(job, backup, dbConn, columnMap, req.BatchSize, req.Reason)
+
+	return job, nil
+}
+
+// runRestore 执行恢复任务
+func (s *ColumnBackupService) runRestore(job *models.ColumnRestoreJob, backup *models.ColumnBackup, dbConn *utils.DatabaseConnection, columnMap map[string]string, batchSize int, reason string) {
+	now := time.Now()
+	s.db.Model(job).Updates(map[string]interface{}{"status": models.RestoreRunning, "start_time": now})
+
+	restored, err := utils.RestoreColumns(dbConn, backup.DatabaseName, backup.TargetTableName,
+		strings.Split(backup.KeyColumns, ","), columnMap, backup.FilePath, backup.Format, batchSize,
+		func(rows int64) {
+			s.db.Model(job).Update("restored_rows", rows)
+		})
+
+	updates := map[string]interface{}{
+		"status":        models.RestoreCompleted,
+		"restored_rows": restored,
+		"end_time":      time.Now(),
+	}
+	if err != nil {
+		logrus.Errorf("恢复列数据失败 (execution=%s): %v", job.ExecutionID, err)
+		updates["status"] = models.RestoreFailed
+		updates["error_message"] = err.Error()
+	}
+	s.db.Model(job).Updates(updates)
+	s.recordRestoreAudit(job, backup, reason, restored, err)
+}
+
+// recordRestoreAudit 记录列数据恢复审计
+func (s *ColumnBackupService) recordRestoreAudit(job *models.ColumnRestoreJob, backup *models.ColumnBackup, reason string, restored int64, opErr error) {
+	auditLog := &models.AuditLog{
+		Action:       string(models.ActionColumnRestore),
+		ResourceType: stringPtr("execution"),
+		ResourceID:   &job.ExecutionID,
+		Status:       models.AuditStatusSuccess,
+		RiskLevel:    "high",
+		CreatedAt:    time.Now(),
+	}
+	if job.CreatedBy != "" {
+		auditLog.UserID = &job.CreatedBy
+	}
+	if opErr != nil {
+		auditLog.Status = models.AuditStatusFailed
+		errorMsg := opErr.Error()
+		auditLog.ErrorMsg = &errorMsg
+	}
+	if raw, err := json.Marshal(map[string]interface{}{
+		"reason":        reason,
+		"job_id":        job.ID,
+		"backup_id":     backup.ID,
+		"column_map":    json.RawMessage(job.ColumnMap),
+		"database":      backup.DatabaseName,
+		"table":         backup.TargetTableName,
+		"restored_rows": restored,
+	}); err == nil {
+		payload := json.RawMessage(raw)
+		auditLog.RequestData = &payload
+	}
+	if err := s.audit.CreateAuditLog(auditLog); err != nil {
+		logrus.Errorf("记录审计日志失败: %v", err)
+	}
+}
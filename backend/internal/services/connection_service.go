@@ -40,6 +40,15 @@ type CreateConnectionRequest struct {
 	ConnectTimeout int                `json:"connect_timeout" binding:"omitempty,min=1,max=60"`
 	Charset        string             `json:"charset" binding:"omitempty,oneof=utf8 utf8mb4"`
 	UseSSL         bool               `json:"use_ssl"`
+	SSLMode        string             `json:"ssl_mode" binding:"omitempty,oneof=disabled preferred required verify_ca verify_identity"`
+	SSLCA          string             `json:"ssl_ca" binding:"omitempty,max=65535"`
+	SSLCert        string             `json:"ssl_cert" binding:"omitempty,max=65535"`
+	SSLKey         string             `json:"ssl_key" binding:"omitempty,max=65535"`
+	SSLServerName  string             `json:"ssl_server_name" binding:"omitempty,max=255"`
+	// 更新时证书与私钥留空表示沿用已保存的值，需删除时显式指定
+	ClearSSLCA   bool `json:"clear_ssl_ca"`
+	ClearSSLCert bool `json:"clear_ssl_cert"`
+	ClearSSLKey  bool `json:"clear_ssl_key"`
 }
 
 // List 获取连接列表
@@ -66,16 +75,7 @@ func (s *ConnectionService) Create(req *CreateConnectionRequest, userID string)
 	}
 
 	// 2. 测试连接
-	dbConn := &utils.DatabaseConnection{
-		Host:           req.Host,
-		Port:           req.Port,
-		Username:       req.Username,
-		Password:       req.Password,
-		DatabaseName:   req.DatabaseName,
-		ConnectTimeout: req.ConnectTimeout,
-		Charset:        req.Charset,
-		UseSSL:         req.UseSSL,
-	}
+	dbConn := req.toDatabaseConnection()
 
 	if err := utils.TestConnection(dbConn); err != nil {
 		return nil, fmt.Errorf("连接测试失败: %v", err)
@@ -103,6 +103,9 @@ func (s *ConnectionService) Create(req *CreateConnectionRequest, userID string)
 		UseSSL:         req.UseSSL,
 		CreatedBy:      userID,
 	}
+	if err := s.applyTLSFields(connection, req); err != nil {
+		return nil, err
+	}
 
 	// 设置默认值
 	if connection.Port == 0 {
@@ -160,18 +163,14 @@ func (s *ConnectionService) Update(id string, req *CreateConnectionRequest, user
 		return nil, err
 	}
 
+	// 未重新上传的证书与私钥沿用已保存的值
+	if err := s.inheritTLSMaterial(&connection, req); err != nil {
+		return nil, err
+	}
+
 	// 3. 如果密码有变化，测试新连接
 	if req.Password != "" {
-		dbConn := &utils.DatabaseConnection{
-			Host:           req.Host,
-			Port:           req.Port,
-			Username:       req.Username,
-			Password:       req.Password,
-			DatabaseName:   req.DatabaseName,
-			ConnectTimeout: req.ConnectTimeout,
-			Charset:        req.Charset,
-			UseSSL:         req.UseSSL,
-		}
+		dbConn := req.toDatabaseConnection()
 
 		if err := utils.TestConnection(dbConn); err != nil {
 			return nil, fmt.Errorf("连接测试失败: %v", err)
@@ -196,6 +195,9 @@ func (s *ConnectionService) Update(id string, req *CreateConnectionRequest, user
 	connection.ConnectTimeout = req.ConnectTimeout
 	connection.Charset = req.Charset
 	connection.UseSSL = req.UseSSL
+	if err := s.applyTLSFields(&connection, req); err != nil {
+		return nil, err
+	}
 
 	// 设置默认值
 	if connection.Port == 0 {
@@ -260,25 +262,13 @@ func (s *ConnectionService) TestConnection(id string) (map[string]interface{}, e
 		return nil, err
 	}
 
-	// 2. 解密密码
-	password, err := s.crypto.Decrypt(connection.Password)
+	// 2. 解密密码并构建连接配置
+	dbConn, err := buildDatabaseConnection(s.crypto, &connection, connection.DatabaseName)
 	if err != nil {
-		return nil, fmt.Errorf("密码解密失败: %v", err)
-	}
-
-	// 3. 构建连接配置
-	dbConn := &utils.DatabaseConnection{
-		Host:           connection.Host,
-		Port:           connection.Port,
-		Username:       connection.Username,
-		Password:       password,
-		DatabaseName:   connection.DatabaseName,
-		ConnectTimeout: connection.ConnectTimeout,
-		Charset:        connection.Charset,
-		UseSSL:         connection.UseSSL,
+		return nil, err
 	}
 
-	// 4. 测试连接并获取信息
+	// 3. 测试连接并获取信息
 	if err := utils.TestConnection(dbConn); err != nil {
 		return map[string]interface{}{
 			"success": false,
@@ -286,7 +276,7 @@ func (s *ConnectionService) TestConnection(id string) (map[string]interface{}, e
 		}, nil
 	}
 
-	// 5. 获取数据库信息
+	// 4. 获取数据库信息
 	info, err := utils.GetDatabaseInfo(dbConn)
 	if err != nil {
 		return map[string]interface{}{
@@ -309,16 +299,7 @@ func (s *ConnectionService) TestConnectionByParams(req *CreateConnectionRequest)
 	}
 
 	// 2. 构建连接配置
-	dbConn := &utils.DatabaseConnection{
-		Host:           req.Host,
-		Port:           req.Port,
-		Username:       req.Username,
-		Password:       req.Password,
-		DatabaseName:   req.DatabaseName,
-		ConnectTimeout: req.ConnectTimeout,
-		Charset:        req.Charset,
-		UseSSL:         req.UseSSL,
-	}
+	dbConn := req.toDatabaseConnection()
 
 	// 3. 测试连接
 	if err := utils.TestConnection(dbConn); err != nil {
@@ -355,25 +336,13 @@ func (s *ConnectionService) GetDatabases(id string) ([]string, error) {
 		return nil, err
 	}
 
-	// 2. 解密密码
-	password, err := s.crypto.Decrypt(connection.Password)
+	// 2. 解密密码并构建连接配置
+	dbConn, err := buildDatabaseConnection(s.crypto, &connection, connection.DatabaseName)
 	if err != nil {
-		return nil, fmt.Errorf("密码解密失败: %v", err)
-	}
-
-	// 3. 构建连接配置
-	dbConn := &utils.DatabaseConnection{
-		Host:           connection.Host,
-		Port:           connection.Port,
-		Username:       connection.Username,
-		Password:       password,
-		DatabaseName:   connection.DatabaseName,
-		ConnectTimeout: connection.ConnectTimeout,
-		Charset:        connection.Charset,
-		UseSSL:         connection.UseSSL,
+		return nil, err
 	}
 
-	// 4. 获取数据库列表
+	// 3. 获取数据库列表
 	return utils.GetDatabaseList(dbConn)
 }
 
@@ -389,25 +358,13 @@ func (s *ConnectionService) GetTables(id string, database string) ([]map[string]
 		return nil, err
 	}
 
-	// 2. 解密密码
-	password, err := s.crypto.Decrypt(connection.Password)
+	// 2. 解密密码并构建连接配置
+	dbConn, err := buildDatabaseConnection(s.crypto, &connection, database)
 	if err != nil {
-		return nil, fmt.Errorf("密码解密失败: %v", err)
-	}
-
-	// 3. 构建连接配置
-	dbConn := &utils.DatabaseConnection{
-		Host:           connection.Host,
-		Port:           connection.Port,
-		Username:       connection.Username,
-		Password:       password,
-		DatabaseName:   database, // 使用指定的数据库
-		ConnectTimeout: connection.ConnectTimeout,
-		Charset:        connection.Charset,
-		UseSSL:         connection.UseSSL,
+		return nil, err
 	}
 
-	// 4. 获取表列表
+	// 3. 获取表列表
 	return utils.GetTableList(dbConn, database)
 }
 
@@ -423,13 +380,169 @@ func (s *ConnectionService) GetTableSchema(id string, database string, table str
 		return nil, err
 	}
 
-	// 2. 解密密码
-	password, err := s.crypto.Decrypt(connection.Password)
+	// 2. 解密密码并构建连接配置
+	dbConn, err := buildDatabaseConnection(s.crypto, &connection, database)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 获取表结构
+	return utils.GetTableSchema(dbConn, database, table)
+}
+
+// AnalyzeIndexes 分析指定表的重复、冗余与未使用索引
+func (s *ConnectionService) AnalyzeIndexes(id string, database string, table string) (*utils.IndexAnalysis, error) {
+	var connection models.Connection
+	err := s.db.First(&connection, "id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("连接不存在")
+		}
+		return nil, err
+	}
+
+	dbConn, err := buildDatabaseConnection(s.crypto, &connection, database)
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.AnalyzeIndexes(dbConn, database, table)
+}
+
+// FragmentScanRequest 碎片扫描条件（阈值均为0时返回全部存在空闲空间的表）
+type FragmentScanRequest struct {
+	Databases    []string `json:"databases" form:"database"`
+	MinFreeMB    int64    `json:"min_free_mb" form:"min_free_mb" binding:"omitempty,min=0"`
+	MinFreeRatio float64  `json:"min_free_ratio" form:"min_free_ratio" binding:"omitempty,min=0,max=100"`
+	Limit        int      `json:"limit" form:"limit" binding:"omitempty,min=1,max=1000"`
+}
+
+// FragmentScanResult 碎片扫描结果
+type FragmentScanResult struct {
+	Tables           []*utils.TableSpace `json:"tables"`            // 满足阈值的表，按可回收空间降序
+	ReclaimableBytes int64               `json:"reclaimable_bytes"` // 满足阈值的表 data_free 合计
+	FragmentedTables int                 `json:"fragmented_tables"` // 存在空闲空间的表总数
+}
+
+// ScanFragmentation 扫描连接下各数据库的表碎片，按可回收空间排序并按阈值过滤
+func (s *ConnectionService) ScanFragmentation(id string, req *FragmentScanRequest) (*FragmentScanResult, error) {
+	var connection models.Connection
+	err := s.db.First(&connection, "id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("连接不存在")
+		}
+		return nil, err
+	}
+
+	dbConn, err := buildDatabaseConnection(s.crypto, &connection, connection.DatabaseName)
+	if err != nil {
+		return nil, err
+	}
+
+	tables, err := utils.ScanFragmentation(dbConn, req.Databases)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &FragmentScanResult{
+		Tables:           []*utils.TableSpace{},
+		FragmentedTables: len(tables),
+	}
+	for _, table := range tables {
+		if table.DataFree < req.MinFreeMB*1024*1024 || table.FreeRatio < req.MinFreeRatio {
+			continue
+		}
+		if req.Limit > 0 && len(result.Tables) >= req.Limit {
+			break
+		}
+		result.Tables = append(result.Tables, table)
+		result.ReclaimableBytes += table.DataFree
+	}
+	return result, nil
+}
+
+// inheritTLSMaterial 请求中未提供且未要求清除的证书与私钥，使用连接已保存的值
+func (s *ConnectionService) inheritTLSMaterial(connection *models.Connection, req *CreateConnectionRequest) error {
+	if req.SSLCA == "" && !req.ClearSSLCA && connection.SSLCA != nil {
+		req.SSLCA = *connection.SSLCA
+	}
+	if req.SSLCert == "" && !req.ClearSSLCert && connection.SSLCert != nil {
+		req.SSLCert = *connection.SSLCert
+	}
+	if req.SSLKey == "" && !req.ClearSSLKey && connection.SSLKey != nil && *connection.SSLKey != "" {
+		key, err := s.crypto.Decrypt(*connection.SSLKey)
+		if err != nil {
+			return fmt.Errorf("TLS私钥解密失败: %v", err)
+		}
+		req.SSLKey = key
+	}
+	if (req.SSLCert == "") != (req.SSLKey == "") {
+		return fmt.Errorf("客户端证书与私钥必须同时提供或同时清除")
+	}
+	return nil
+}
+
+// applyTLSFields 将请求中的TLS配置写入连接模型（私钥加密存储）
+func (s *ConnectionService) applyTLSFields(connection *models.Connection, req *CreateConnectionRequest) error {
+	connection.SSLMode = req.SSLMode
+	connection.SSLServerName = req.SSLServerName
+	connection.SSLCA = nil
+	connection.SSLCert = nil
+	connection.SSLKey = nil
+
+	// 关闭TLS时仍保留证书，重新开启后无需再次上传
+	if req.SSLMode == utils.SSLModeDisabled {
+		connection.UseSSL = false
+	} else if req.SSLMode != "" {
+		connection.UseSSL = true
+	}
+
+	if req.SSLCA != "" {
+		ca := req.SSLCA
+		connection.SSLCA = &ca
+	}
+	if req.SSLCert != "" {
+		cert := req.SSLCert
+		connection.SSLCert = &cert
+	}
+	if req.SSLKey != "" {
+		encryptedKey, err := s.crypto.Encrypt(req.SSLKey)
+		if err != nil {
+			return fmt.Errorf("TLS私钥加密失败: %v", err)
+		}
+		connection.SSLKey = &encryptedKey
+	}
+
+	return nil
+}
+
+// toDatabaseConnection 将请求参数转换为数据库连接配置
+func (req *CreateConnectionRequest) toDatabaseConnection() *utils.DatabaseConnection {
+	return &utils.DatabaseConnection{
+		Host:           req.Host,
+		Port:           req.Port,
+		Username:       req.Username,
+		Password:       req.Password,
+		DatabaseName:   req.DatabaseName,
+		ConnectTimeout: req.ConnectTimeout,
+		Charset:        req.Charset,
+		UseSSL:         req.UseSSL,
+		SSLMode:        req.SSLMode,
+		SSLCA:          req.SSLCA,
+		SSLCert:        req.SSLCert,
+		SSLKey:         req.SSLKey,
+		SSLServerName:  req.SSLServerName,
+	}
+}
+
+// buildDatabaseConnection 根据已保存的连接构建数据库连接配置（解密密码与TLS私钥）
+func buildDatabaseConnection(crypto *utils.CryptoService, connection *models.Connection, database string) (*utils.DatabaseConnection, error) {
+	password, err := crypto.Decrypt(connection.Password)
 	if err != nil {
 		return nil, fmt.Errorf("密码解密失败: %v", err)
 	}
 
-	// 3. 构建连接配置
 	dbConn := &utils.DatabaseConnection{
 		Host:           connection.Host,
 		Port:           connection.Port,
@@ -439,10 +552,24 @@ func (s *ConnectionService) GetTableSchema(id string, database string, table str
 		ConnectTimeout: connection.ConnectTimeout,
 		Charset:        connection.Charset,
 		UseSSL:         connection.UseSSL,
+		SSLMode:        connection.SSLMode,
+		SSLServerName:  connection.SSLServerName,
+	}
+	if connection.SSLCA != nil {
+		dbConn.SSLCA = *connection.SSLCA
+	}
+	if connection.SSLCert != nil {
+		dbConn.SSLCert = *connection.SSLCert
+	}
+	if connection.SSLKey != nil && *connection.SSLKey != "" {
+		key, err := crypto.Decrypt(*connection.SSLKey)
+		if err != nil {
+			return nil, fmt.Errorf("TLS私钥解密失败: %v", err)
+		}
+		dbConn.SSLKey = key
 	}
 
-	// 4. 获取表结构
-	return utils.GetTableSchema(dbConn, database, table)
+	return dbConn, nil
 }
 
 // validateCreateRequest 验证创建请求
@@ -0,0 +1,500 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fengzhencai/MySQLer/backend/internal/config"
+	"github.com/fengzhencai/MySQLer/backend/internal/models"
+	"github.com/fengzhencai/MySQLer/backend/internal/utils"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// 漂移状态
+const (
+	DriftMissingInTarget = "missing_in_target" // 对比库缺少该表
+	DriftMissingInBase   = "missing_in_base"   // 基准库缺少该表
+	DriftChanged         = "drifted"           // 表结构不一致
+	DriftError           = "error"             // 获取结构失败
+)
+
+// 漂移分类
+const (
+	DriftCategoryColumn  = "column"
+	DriftCategoryIndex   = "index"
+	DriftCategoryCharset = "charset"
+	DriftCategoryOther   = "other"
+)
+
+// DriftService 跨环境结构漂移检测服务
+type DriftService struct {
+	db     *gorm.DB
+	cfg    *config.Config
+	crypto *utils.CryptoService
+
+	running  map[string]bool
+	mutex    sync.Mutex
+	stopChan chan struct{}
+	stopOnce sync.Once
+}
+
+// NewDriftService 创建漂移检测服务
+func NewDriftService(db *gorm.DB, cfg *config.Config) *DriftService {
+	return &DriftService{
+		db:       db,
+		cfg:      cfg,
+		crypto:   utils.NewCryptoService(cfg.EncryptionKey),
+		running:  make(map[string]bool),
+		stopChan: make(chan struct{}),
+	}
+}
+
+// DriftCompareRequest 临时对比请求
+type DriftCompareRequest struct {
+	BaseConnectionID   string `json:"base_connection_id" binding:"required,uuid4"`
+	BaseDatabase       string `json:"base_database" binding:"required,min=1,max=100"`
+	TargetConnectionID string `json:"target_connection_id" binding:"required,uuid4"`
+	TargetDatabase     string `json:"target_database" binding:"required,min=1,max=100"`
+	GenerateAlters     bool   `json:"generate_alters"`
+}
+
+// CreateDriftCheckRequest 创建/更新漂移检查请求
+type CreateDriftCheckRequest struct {
+	Name string `json:"name" binding:"required,min=1,max=100"`
+	DriftCompareRequest
+	IntervalMinutes int   `json:"interval_minutes" binding:"min=0"`
+	Enabled         *bool `json:"enabled"`
+}
+
+// TableDrift 单表漂移详情
+type TableDrift struct {
+	Table      string               `json:"table"`
+	Status     string               `json:"status"`
+	Categories []string             `json:"categories,omitempty"`
+	Changes    []utils.SchemaChange `json:"changes,omitempty"`
+	AlterSQL   string               `json:"alter_sql,omitempty"`  // 使对比库收敛到基准库的 ALTER
+	CreateSQL  string               `json:"create_sql,omitempty"` // 对比库缺表时的建表语句
+	Warnings   []string             `json:"warnings,omitempty"`
+	Error      string               `json:"error,omitempty"`
+}
+
+// DriftResult 漂移对比结果
+type DriftResult struct {
+	Report *models.DriftReport `json:"report"`
+	Tables []TableDrift        `json:"tables"`
+}
+
+// Compare 临时对比两个库并保存报告
+func (s *DriftService) Compare(req *DriftCompareRequest) (*DriftResult, error) {
+	return s.run(nil, req)
+}
+
+// run 执行对比并保存报告
+func (s *DriftService) run(checkID *string, req *DriftCompareRequest) (*DriftResult, error) {
+	start := time.Now()
+	report := &models.DriftReport{
+		CheckID:            checkID,
+		BaseConnectionID:   req.BaseConnectionID,
+		BaseDatabase:       req.BaseDatabase,
+		TargetConnectionID: req.TargetConnectionID,
+		TargetDatabase:     req.TargetDatabase,
+		Status:             "success",
+	}
+
+	tables, err := s.compare(req)
+	if err != nil {
+		report.Status = "failed"
+		errorMsg := err.Error()
+		report.ErrorMessage = &errorMsg
+	} else {
+		summarizeDrift(report, tables)
+		if details, err := json.Marshal(tables); err == nil {
+			detailStr := string(details)
+			report.Details = &detailStr
+		}
+	}
+	report.DurationMs = time.Since(start).Milliseconds()
+
+	if saveErr := s.db.Create(report).Error; saveErr != nil {
+		return nil, fmt.Errorf("保存漂移报告失败: %v", saveErr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &DriftResult{Report: report, Tables: tables}, nil
+}
+
+// compare 逐表比较基准库与对比库
+func (s *DriftService) compare(req *DriftCompareRequest) ([]TableDrift, error) {
+	baseConn, err := s.openConnection(req.BaseConnectionID, req.BaseDatabase)
+	if err != nil {
+		return nil, fmt.Errorf("基准连接: %v", err)
+	}
+	targetConn, err := s.openConnection(req.TargetConnectionID, req.TargetDatabase)
+	if err != nil {
+		return nil, fmt.Errorf("对比连接: %v", err)
+	}
+
+	baseTables, err := tableNames(baseConn, req.BaseDatabase)
+	if err != nil {
+		return nil, fmt.Errorf("获取基准库表列表失败: %v", err)
+	}
+	targetTables, err := tableNames(targetConn, req.TargetDatabase)
+	if err != nil {
+		return nil, fmt.Errorf("获取对比库表列表失败: %v", err)
+	}
+
+	names := map[string]bool{}
+	for name := range baseTables {
+		names[name] = true
+	}
+	for name := range targetTables {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	var result []TableDrift
+	for _, name := range sorted {
+		drift := TableDrift{Table: name}
+
+		if !targetTables[name] {
+			drift.Status = DriftMissingInTarget
+			if req.GenerateAlters {
+				drift.CreateSQL, _ = utils.GetCreateTable(baseConn, req.BaseDatabase, name)
+			}
+			result = append(result, drift)
+			continue
+		}
+		if !baseTables[name] {
+			drift.Status = DriftMissingInBase
+			result = append(result, drift)
+			continue
+		}
+
+		baseDef, err := loadTableDefinition(baseConn, req.BaseDatabase, name)
+		if err == nil {
+			var targetDef *utils.TableDefinition
+			if targetDef, err = loadTableDefinition(targetConn, req.TargetDatabase, name); err == nil {
+				diff := utils.DiffTableDefinitions(targetDef, baseDef, name)
+				if len(diff.Changes) == 0 {
+					continue
+				}
+				drift.Status = DriftChanged
+				drift.Changes = diff.Changes
+				drift.Categories = driftCategories(diff.Changes)
+				if req.GenerateAlters {
+					drift.AlterSQL = diff.AlterSQL
+					drift.Warnings = diff.Warnings
+				}
+			}
+		}
+		if err != nil {
+			drift.Status = DriftError
+			drift.Error = err.Error()
+		}
+		result = append(result, drift)
+	}
+
+	return result, nil
+}
+
+// openConnection 根据连接ID构建数据库连接
+func (s *DriftService) openConnection(connectionID, database string) (*utils.DatabaseConnection, error) {
+	var connection models.Connection
+	if err := s.db.First(&connection, "id = ?", connectionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("连接不存在")
+		}
+		return nil, err
+	}
+	return buildDatabaseConnection(s.crypto, &connection, database)
+}
+
+// tableNames 获取库中的表名集合
+func tableNames(conn *utils.DatabaseConnection, database string) (map[string]bool, error) {
+	tables, err := utils.GetTableList(conn, database)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool, len(tables))
+	for _, table := range tables {
+		if name, ok := table["table_name"].(string); ok {
+			names[name] = true
+		}
+	}
+	return names, nil
+}
+
+// loadTableDefinition 读取并解析表结构
+func loadTableDefinition(conn *utils.DatabaseConnection, database, table string) (*utils.TableDefinition, error) {
+	createSQL, err := utils.GetCreateTable(conn, database, table)
+	if err != nil {
+		return nil, err
+	}
+	return utils.ParseCreateTable(createSQL)
+}
+
+// classifyChange 将单项差异归类
+func classifyChange(change utils.SchemaChange) string {
+	switch change.Object {
+	case "index", "foreign_key":
+		return DriftCategoryIndex
+	case "option":
+		if change.Name == "CHARSET" || change.Name == "COLLATE" {
+			return DriftCategoryCharset
+		}
+		return DriftCategoryOther
+	case "column":
+		if change.Action != utils.ChangeModify {
+			return DriftCategoryColumn
+		}
+		before, errBefore := utils.ParseColumnDefinition("c " + change.Before)
+		after, errAfter := utils.ParseColumnDefinition("c " + change.After)
+		if errBefore != nil || errAfter != nil || before.Type != after.Type {
+			return DriftCategoryColumn
+		}
+		if before.Charset != after.Charset || before.Collation != after.Collation {
+			return DriftCategoryCharset
+		}
+		return DriftCategoryColumn
+	}
+	return DriftCategoryOther
+}
+
+// driftCategories 汇总单表涉及的差异分类
+func driftCategories(changes []utils.SchemaChange) []string {
+	seen := map[string]bool{}
+	var categories []string
+	for _, change := range changes {
+		category := classifyChange(change)
+		if !seen[category] {
+			seen[category] = true
+			categories = append(categories, category)
+		}
+	}
+	return categories
+}
+
+// summarizeDrift 统计报告汇总数据
+func summarizeDrift(report *models.DriftReport, tables []TableDrift) {
+	for _, table := range tables {
+		switch table.Status {
+		case DriftMissingInTarget, DriftMissingInBase:
+			report.MissingTables++
+			report.DriftedTables++
+		case DriftChanged:
+			report.DriftedTables++
+			for _, change := range table.Changes {
+				switch classifyChange(change) {
+				case DriftCategoryColumn:
+					report.ColumnDiffs++
+				case DriftCategoryIndex:
+					report.IndexDiffs++
+				case DriftCategoryCharset:
+					report.CharsetDiffs++
+				default:
+					report.OtherDiffs++
+				}
+			}
+		}
+	}
+
+	compared := 0
+	for _, table := range tables {
+		if table.Status != DriftMissingInTarget && table.Status != DriftMissingInBase {
+			compared++
+		}
+	}
+	report.TablesCompared = compared
+}
+
+// ListChecks 获取漂移检查列表
+func (s *DriftService) ListChecks() ([]models.DriftCheck, error) {
+	var checks []models.DriftCheck
+	err := s.db.Preload("BaseConnection").Preload("TargetConnection").Order("created_at DESC").Find(&checks).Error
+	return checks, err
+}
+
+// GetCheck 获取漂移检查
+func (s *DriftService) GetCheck(id string) (*models.DriftCheck, error) {
+	var check models.DriftCheck
+	if err := s.db.Preload("BaseConnection").Preload("TargetConnection").First(&check, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("漂移检查不存在")
+		}
+		return nil, err
+	}
+	return &check, nil
+}
+
+// CreateCheck 创建漂移检查
+func (s *DriftService) CreateCheck(req *CreateDriftCheckRequest, userID string) (*models.DriftCheck, error) {
+	check := &models.DriftCheck{
+		ID:        uuid.New().String(),
+		CreatedBy: userID,
+	}
+	applyDriftCheck(check, req)
+
+	if err := s.db.Create(check).Error; err != nil {
+		return nil, fmt.Errorf("创建漂移检查失败: %v", err)
+	}
+	return check, nil
+}
+
+// UpdateCheck 更新漂移检查
+func (s *DriftService) UpdateCheck(id string, req *CreateDriftCheckRequest) (*models.DriftCheck, error) {
+	check, err := s.GetCheck(id)
+	if err != nil {
+		return nil, err
+	}
+	applyDriftCheck(check, req)
+
+	if err := s.db.Omit("BaseConnection", "TargetConnection").Save(check).Error; err != nil {
+		return nil, fmt.Errorf("更新漂移检查失败: %v", err)
+	}
+	return check, nil
+}
+
+// DeleteCheck 删除漂移检查（保留历史报告）
+func (s *DriftService) DeleteCheck(id string) error {
+	result := s.db.Delete(&models.DriftCheck{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("漂移检查不存在")
+	}
+	return nil
+}
+
+// applyDriftCheck 将请求写入检查配置
+func applyDriftCheck(check *models.DriftCheck, req *CreateDriftCheckRequest) {
+	check.Name = req.Name
+	check.BaseConnectionID = req.BaseConnectionID
+	check.BaseDatabase = req.BaseDatabase
+	check.TargetConnectionID = req.TargetConnectionID
+	check.TargetDatabase = req.TargetDatabase
+	check.GenerateAlters = req.GenerateAlters
+	check.IntervalMinutes = req.IntervalMinutes
+	check.Enabled = req.Enabled == nil || *req.Enabled
+
+	check.NextRunAt = nil
+	if check.Enabled && check.IntervalMinutes > 0 {
+		next := time.Now().Add(time.Duration(check.IntervalMinutes) * time.Minute)
+		check.NextRunAt = &next
+	}
+}
+
+// RunCheck 立即执行一次漂移检查
+func (s *DriftService) RunCheck(id string) (*DriftResult, error) {
+	check, err := s.GetCheck(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	if s.running[id] {
+		s.mutex.Unlock()
+		return nil, fmt.Errorf("该漂移检查正在执行中")
+	}
+	s.running[id] = true
+	s.mutex.Unlock()
+	defer func() {
+		s.mutex.Lock()
+		delete(s.running, id)
+		s.mutex.Unlock()
+	}()
+
+	now := time.Now()
+	updates := map[string]interface{}{"last_run_at": now}
+	if check.Enabled && check.IntervalMinutes > 0 {
+		updates["next_run_at"] = now.Add(time.Duration(check.IntervalMinutes) * time.Minute)
+	}
+	s.db.Model(&models.DriftCheck{}).Where("id = ?", id).Updates(updates)
+
+	return s.run(&check.ID, &DriftCompareRequest{
+		BaseConnectionID:   check.BaseConnectionID,
+		BaseDatabase:       check.BaseDatabase,
+		TargetConnectionID: check.TargetConnectionID,
+		TargetDatabase:     check.TargetDatabase,
+		GenerateAlters:     check.GenerateAlters,
+	})
+}
+
+// ListReports 获取检查的历史报告（不含明细，用于趋势展示）
+func (s *DriftService) ListReports(checkID string, limit int) ([]models.DriftReport, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	var reports []models.DriftReport
+	err := s.db.Omit("details").Where("check_id = ?", checkID).
+		Order("created_at DESC").Limit(limit).Find(&reports).Error
+	return reports, err
+}
+
+// GetReport 获取报告详情
+func (s *DriftService) GetReport(id int64) (*DriftResult, error) {
+	var report models.DriftReport
+	if err := s.db.First(&report, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("漂移报告不存在")
+		}
+		return nil, err
+	}
+
+	result := &DriftResult{Report: &report}
+	if report.Details != nil {
+		if err := json.Unmarshal([]byte(*report.Details), &result.Tables); err != nil {
+			return nil, fmt.Errorf("解析报告明细失败: %v", err)
+		}
+		report.Details = nil
+	}
+	return result, nil
+}
+
+// StartScheduler 启动定时漂移检查
+func (s *DriftService) StartScheduler() {
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stopChan:
+				return
+			case <-ticker.C:
+				s.runDueChecks()
+			}
+		}
+	}()
+}
+
+// Stop 停止定时任务
+func (s *DriftService) Stop() {
+	s.stopOnce.Do(func() { close(s.stopChan) })
+}
+
+// runDueChecks 执行到期的漂移检查
+func (s *DriftService) runDueChecks() {
+	var checks []models.DriftCheck
+	err := s.db.Where("enabled = ? AND interval_minutes > 0 AND next_run_at <= ?", true, time.Now()).Find(&checks).Error
+	if err != nil {
+		logrus.Errorf("查询待执行漂移检查失败: %v", err)
+		return
+	}
+
+	for _, check := range checks {
+		if _, err := s.RunCheck(check.ID); err != nil {
+			logrus.Warnf("漂移检查 %s 执行失败: %v", check.Name, err)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fengzhencai/MySQLer/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// ListAttempts 获取执行记录的全部执行尝试（按次序）
+func (s *ExecutionService) ListAttempts(id string) ([]models.ExecutionAttempt, error) {
+	var count int64
+	if err := s.db.Model(&models.ExecutionRecord{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("执行记录不存在")
+	}
+
+	attempts := []models.ExecutionAttempt{}
+	err := s.db.Where("execution_id = ?", id).Order("attempt ASC").Find(&attempts).Error
+	return attempts, err
+}
+
+// nextAttempt 为下一次执行准备尝试记录（已有待执行的尝试时复用）
+func nextAttempt(db *gorm.DB, record *models.ExecutionRecord, trigger models.AttemptTrigger, mode, userID string, scheduledAt *time.Time) (*models.ExecutionAttempt, error) {
+	attempt, err := pendingAttempt(db, record)
+	if err != nil {
+		return nil, err
+	}
+	attempt.Trigger = trigger
+	attempt.Mode = mode
+	attempt.ScheduledAt = scheduledAt
+	if userID != "" {
+		attempt.CreatedBy = userID
+	}
+	if err := db.Save(attempt).Error; err != nil {
+		return nil, fmt.Errorf("保存执行尝试失败: %v", err)
+	}
+	return attempt, nil
+}
+
+// pendingAttempt 查找待执行的尝试，不存在时构造下一次尝试（不保存）
+func pendingAttempt(db *gorm.DB, record *models.ExecutionRecord) (*models.ExecutionAttempt, error) {
+	var attempt models.ExecutionAttempt
+	err := db.Where("execution_id = ? AND status = ?", record.ID, models.StatusPending).
+		Order("attempt DESC").First(&attempt).Error
+	if err == nil {
+		return &attempt, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	return &models.ExecutionAttempt{
+		ExecutionID: record.ID,
+		Attempt:     record.Attempts + 1,
+		Trigger:     models.AttemptInitial,
+		Status:      models.StatusPending,
+		CreatedBy:   record.CreatedBy,
+	}, nil
+}
+
+// startAttempt 任务开始执行时登记尝试记录
+func (e *ExecutionEngine) startAttempt(record *models.ExecutionRecord) *models.ExecutionAttempt {
+	attempt, err := pendingAttempt(e.db, record)
+	if err != nil {
+		fmt.Printf("读取执行尝试失败: %v\n", err)
+		return nil
+	}
+	attempt.Status = models.StatusRunning
+	attempt.StartTime = record.StartTime
+	if err := e.db.Save(attempt).Error; err != nil {
+		fmt.Printf("保存执行尝试失败: %v\n", err)
+		return nil
+	}
+	record.Attempts = attempt.Attempt
+	return attempt
+}
+
+// finishAttempt 任务结束时写入本次尝试的结果
+func (e *ExecutionEngine) finishAttempt(task *ExecutionTask) {
+	attempt := task.Attempt
+	if attempt == nil {
+		return
+	}
+	attempt.Status = task.Record.Status
+	attempt.EndTime = task.Record.EndTime
+	attempt.DurationSeconds = task.Record.DurationSeconds
+	attempt.ContainerID = task.Record.ContainerID
+	attempt.ExitCode = task.exitCode
+	attempt.ErrorMessage = task.Record.ErrorMessage
+	attempt.FailureCategory = task.Record.FailureCategory
+	if err := e.db.Save(attempt).Error; err != nil {
+		e.logLine(task, fmt.Sprintf("[%s] 保存执行尝试失败: %v", time.Now().Format("15:04:05"), err))
+	}
+}
+
+// scheduleAutoRetry 失败分类符合所在环境的重试策略时，按退避时间计划自动重试
+func (e *ExecutionEngine) scheduleAutoRetry(task *ExecutionTask) {
+	record := task.Record
+	if record.Status != models.StatusFailed || record.FailureCategory == nil {
+		return
+	}
+	policy, err := e.retryPolicies.Get(record.Connection.Environment)
+	if err != nil || !policy.Enabled || !policy.Retriable(*record.FailureCategory) {
+		return
+	}
+	if record.Attempts >= policy.MaxAttempts {
+		e.logLine(task, fmt.Sprintf("[%s] 已执行 %d 次，达到重试上限，不再自动重试", time.Now().Format("15:04:05"), record.Attempts))
+		return
+	}
+
+	// 计划时间取整到秒，与数据库中保存的精度一致，便于定时器比对
+	delay := policy.Backoff(record.Attempts)
+	retryAt := time.Now().Add(delay).Truncate(time.Second)
+	if _, err := nextAttempt(e.db, record, models.AttemptAuto, "", "", &retryAt); err != nil {
+		e.logLine(task, fmt.Sprintf("[%s] 计划自动重试失败: %v", time.Now().Format("15:04:05"), err))
+		return
+	}
+	record.NextRetryAt = &retryAt
+	e.logLine(task, fmt.Sprintf("[%s] 将于 %s 后自动重试（第 %d/%d 次执行）",
+		time.Now().Format("15:04:05"), delay, record.Attempts+1, policy.MaxAttempts))
+
+	recordID := record.ID
+	time.AfterFunc(time.Until(retryAt), func() { e.runAutoRetry(recordID, retryAt) })
+}
+
+// runAutoRetry 到达计划时间后重置记录并重新入队；
+// 已手动重试、取消计划或已重新计划（计划时间不一致）时跳过，认领以条件更新保证只入队一次
+func (e *ExecutionEngine) runAutoRetry(recordID string, scheduledAt time.Time) {
+	if e.ctx.Err() != nil {
+		return
+	}
+	var record models.ExecutionRecord
+	if err := e.db.Preload("Connection").First(&record, "id = ?", recordID).Error; err != nil {
+		return
+	}
+	if record.Status != models.StatusFailed || record.NextRetryAt == nil ||
+		!record.NextRetryAt.Truncate(time.Second).Equal(scheduledAt.Truncate(time.Second)) {
+		return
+	}
+
+	// 新表与触发器完整时续跑，否则从头执行
+	mode := RetryModeRestart
+	if state, _, err := resumeStateOf(e.crypto, &record); err == nil && state.Resumable {
+		mode = RetryModeResume
+	}
+	from := record.Status
+	if err := prepareRetry(e.crypto, &record, mode); err != nil {
+		fmt.Printf("自动重试 %s 失败: %v\n", recordID, err)
+		return
+	}
+	record.NextRetryAt = nil
+
+	claimed := false
+	err := e.db.Transaction(func(tx *gorm.DB) error {
+		// 以条件更新认领本次计划，避免与同时发生的手动重试或取消相互覆盖
+		claim := tx.Model(&models.ExecutionRecord{}).
+			Where("id = ? AND status = ? AND next_retry_at = ?", recordID, models.StatusFailed, scheduledAt.Truncate(time.Second)).
+			Updates(map[string]interface{}{
+				"status":        models.StatusPending,
+				"next_retry_at": nil,
+			})
+		if claim.Error != nil {
+			return claim.Error
+		}
+		if claim.RowsAffected == 0 {
+			return nil
+		}
+		claimed = true
+
+		if _, err := nextAttempt(tx, &record, models.AttemptAuto, mode, "", &scheduledAt); err != nil {
+			return err
+		}
+		if err := tx.Omit("Connection").Save(&record).Error; err != nil {
+			return err
+		}
+		recordExecutionEvent(tx, &record, from, "", fmt.Sprintf("自动重试（%s）", mode))
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("自动重试 %s 失败: %v\n", recordID, err)
+		return
+	}
+	if !claimed {
+		return
+	}
+	if err := e.StartExecution(recordID, nil); err != nil {
+		fmt.Printf("自动重试 %s 入队失败: %v\n", recordID, err)
+	}
+}
+
+// resumeScheduledRetries 服务重启后恢复尚未执行的自动重试计划
+func (e *ExecutionEngine) resumeScheduledRetries() {
+	var records []models.ExecutionRecord
+	if err := e.db.Select("id", "next_retry_at").
+		Where("status = ? AND next_retry_at IS NOT NULL", models.StatusFailed).Find(&records).Error; err != nil {
+		return
+	}
+	for _, record := range records {
+		recordID, scheduledAt := record.ID, *record.NextRetryAt
+		delay := time.Until(scheduledAt)
+		if delay < 0 {
+			delay = 0
+		}
+		time.AfterFunc(delay, func() { e.runAutoRetry(recordID, scheduledAt) })
+	}
+}
+
+// cancelScheduledRetry 取消已计划的自动重试
+func cancelScheduledRetry(db *gorm.DB, record *models.ExecutionRecord) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.ExecutionAttempt{}).
+			Where("execution_id = ? AND status = ?", record.ID, models.StatusPending).
+			Update("status", models.StatusCancelled).Error; err != nil {
+			return err
+		}
+		record.NextRetryAt = nil
+		return tx.Model(record).Update("next_retry_at", nil).Error
+	})
+}
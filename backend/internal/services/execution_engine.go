@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
@@ -20,6 +21,14 @@ type ExecutionEngine struct {
 	cfg           *config.Config
 	dockerService *utils.DockerService
 	crypto        *utils.CryptoService
+	snapshots     *SnapshotService
+	oldTables     *OldTableService
+	backups       *ColumnBackupService
+	progress      *ProgressService
+	audit         *AuditService
+	notifier      *NotificationService
+	retryPolicies *RetryPolicyService
+	logs          *ExecutionLogService
 
 	// 执行队列管理
 	runningTasks  map[string]*ExecutionTask
@@ -54,10 +63,49 @@ type ExecutionTask struct {
 	Progress     float64                `json:"progress"`
 	CurrentStage string                 `json:"current_stage"`
 	Speed        float64                `json:"speed"`
+	Throttled    bool                   `json:"throttled"`
+	Health       models.ExecutionHealth `json:"health"`
+	HealthReason string                 `json:"health_reason,omitempty"`
+	ServerLoad   *ServerLoadStatus      `json:"server_load,omitempty"`
+
+	TransactionGuard *utils.TransactionGuardReport `json:"transaction_guard,omitempty"`
+
+	// 本次执行尝试
+	Attempt  *models.ExecutionAttempt `json:"-"`
+	exitCode *int
+
+	// 执行日志：分块持久化的写入器与容器完整输出（用于失败分类）
+	logs   *ExecutionLogWriter
+	output string
+
+	// 执行前预测的 pt-osc 旧表名（保留旧表时使用）
+	expectedOldTable string
+
+	// 进度采样状态
+	processedRows  int64
+	lastSampleAt   time.Time
+	lastSampleRows int64
+
+	// 健康检查状态（复制开始后生效）
+	watching       bool
+	lastLogAt      time.Time
+	lastProgressAt time.Time
+	speedAvg       float64 // 采样速度的指数移动平均
+	speedSamples   int
+	slow           bool
 
 	mutex sync.RWMutex
 }
 
+// progressSampleInterval 进度采样最小间隔（暂停状态变化时立即采样）
+const progressSampleInterval = 5 * time.Second
+
+// 降速判定：移动平均平滑系数与所需的最少采样数
+const (
+	speedAvgAlpha   = 0.2
+	minSpeedSamples = 3
+)
+
 // NewExecutionEngine 创建执行引擎
 func NewExecutionEngine(db *gorm.DB, cfg *config.Config) (*ExecutionEngine, error) {
 	dockerService, err := utils.NewDockerService()
@@ -72,6 +120,14 @@ func NewExecutionEngine(db *gorm.DB, cfg *config.Config) (*ExecutionEngine, erro
 		cfg:           cfg,
 		dockerService: dockerService,
 		crypto:        utils.NewCryptoService(cfg.EncryptionKey),
+		snapshots:     NewSnapshotService(db),
+		oldTables:     NewOldTableService(db, cfg),
+		backups:       NewColumnBackupService(db, cfg),
+		progress:      NewProgressService(db, cfg),
+		audit:         NewAuditService(db, cfg),
+		notifier:      NewNotificationService(cfg),
+		retryPolicies: NewRetryPolicyService(db),
+		logs:          NewExecutionLogService(db, cfg),
 		runningTasks:  make(map[string]*ExecutionTask),
 		maxConcurrent: 10, // 最大并发执行数
 		queue:         make(chan string, 100),
@@ -85,6 +141,12 @@ func NewExecutionEngine(db *gorm.DB, cfg *config.Config) (*ExecutionEngine, erro
 		go engine.worker()
 	}
 
+	// 启动执行健康检查
+	go engine.watchdog()
+
+	// 恢复服务重启前计划的自动重试
+	engine.resumeScheduledRetries()
+
 	return engine, nil
 }
 
@@ -105,8 +167,8 @@ func (e *ExecutionEngine) StartExecution(recordID string, logCallback func(strin
 	}
 }
 
-// StopExecution 停止执行任务
-func (e *ExecutionEngine) StopExecution(recordID string) error {
+// StopExecution 停止执行任务（operator 为操作人ID，系统触发时为空）
+func (e *ExecutionEngine) StopExecution(recordID string, operator string) error {
 	e.mutex.Lock()
 	defer e.mutex.Unlock()
 
@@ -133,6 +195,7 @@ func (e *ExecutionEngine) StopExecution(recordID string) error {
 
 	// 更新数据库状态
 	now := time.Now()
+	from := task.Record.Status
 	task.Record.Status = models.StatusCancelled
 	task.Record.EndTime = &now
 	duration := int(now.Sub(*task.Record.StartTime).Seconds())
@@ -141,6 +204,7 @@ func (e *ExecutionEngine) StopExecution(recordID string) error {
 	if err := e.db.Save(task.Record).Error; err != nil {
 		return fmt.Errorf("更新执行状态失败: %v", err)
 	}
+	recordExecutionEvent(e.db, task.Record, from, operator, "手动停止")
 
 	// 从运行任务列表中删除
 	delete(e.runningTasks, recordID)
@@ -168,8 +232,14 @@ func (e *ExecutionEngine) GetTaskStatus(recordID string) (*ExecutionTask, error)
 		Progress:     task.Progress,
 		CurrentStage: task.CurrentStage,
 		Speed:        task.Speed,
+		Throttled:    task.Throttled,
+		Health:       task.Health,
+		HealthReason: task.HealthReason,
+		ServerLoad:   task.ServerLoad,
 		StartTime:    task.StartTime,
 		ContainerID:  task.ContainerID,
+
+		TransactionGuard: task.TransactionGuard,
 	}, nil
 }
 
@@ -187,8 +257,14 @@ func (e *ExecutionEngine) GetRunningTasks() []*ExecutionTask {
 			Progress:     task.Progress,
 			CurrentStage: task.CurrentStage,
 			Speed:        task.Speed,
+			Throttled:    task.Throttled,
+			Health:       task.Health,
+			HealthReason: task.HealthReason,
+			ServerLoad:   task.ServerLoad,
 			StartTime:    task.StartTime,
 			ContainerID:  task.ContainerID,
+
+			TransactionGuard: task.TransactionGuard,
 		}
 		task.mutex.RUnlock()
 		tasks = append(tasks, taskCopy)
@@ -235,6 +311,7 @@ func (e *ExecutionEngine) worker() {
 				StartTime:    time.Now(),
 				Status:       models.StatusRunning,
 				CurrentStage: "准备执行",
+				Health:       models.HealthHealthy,
 			}
 
 			// 注册运行任务
@@ -246,6 +323,8 @@ func (e *ExecutionEngine) worker() {
 			now := time.Now()
 			record.Status = models.StatusRunning
 			record.StartTime = &now
+			record.NextRetryAt = nil
+			task.Attempt = e.startAttempt(&record)
 			if err := e.db.Save(&record).Error; err != nil {
 				e.mutex.Lock()
 				delete(e.runningTasks, recordID)
@@ -253,6 +332,7 @@ func (e *ExecutionEngine) worker() {
 				taskCancel()
 				continue
 			}
+			recordExecutionEvent(e.db, &record, models.StatusPending, "", fmt.Sprintf("开始第 %d 次执行", record.Attempts))
 
 			// 执行
 			e.executeTask(task)
@@ -268,40 +348,65 @@ func (e *ExecutionEngine) executeTask(task *ExecutionTask) {
 		e.mutex.Unlock()
 	}()
 
+	// 日志写入器在最终状态写入后关闭，保证结束提示一并落库
+	task.logs = e.logs.NewWriter(task.ID, task.Record.Attempts)
+	defer task.logs.Close()
+
 	var err error
+	var verification *VerificationReport
 	defer func() {
 		// 更新最终状态
+		from := task.Record.Status
 		now := time.Now()
 		task.Record.EndTime = &now
 		duration := int(now.Sub(*task.Record.StartTime).Seconds())
 		task.Record.DurationSeconds = &duration
 
 		if err != nil {
+			// 手动停止导致的失败保持为已取消
 			task.Record.Status = models.StatusFailed
+			if task.Context.Err() != nil {
+				task.Record.Status = models.StatusCancelled
+			}
 			errorMsg := err.Error()
 			task.Record.ErrorMessage = &errorMsg
+			e.classifyFailure(task, err)
 
 			task.mutex.Lock()
-			task.Status = models.StatusFailed
+			task.Status = task.Record.Status
 			task.mutex.Unlock()
 
 			if task.LogCallback != nil {
 				task.LogCallback(fmt.Sprintf("执行失败: %v", err))
 			}
+			task.logs.Append(fmt.Sprintf("执行失败: %v", err))
 		} else {
+			// 执行后校验存在不一致时标记为带警告完成
 			task.Record.Status = models.StatusCompleted
+			if verification != nil && !verification.Passed {
+				task.Record.Status = models.StatusCompletedWithWarnings
+			}
 			task.mutex.Lock()
-			task.Status = models.StatusCompleted
+			task.Status = task.Record.Status
 			task.Progress = 100.0
 			task.mutex.Unlock()
 
 			if task.LogCallback != nil {
-				task.LogCallback("执行完成")
+				task.LogCallback(finalMessage(task.Record.Status))
 			}
+			task.logs.Append(finalMessage(task.Record.Status))
 		}
 
+		// 由进度序列计算已处理行数与平均速度
+		e.applyProgressSummary(task, int64(duration))
+
+		// 记录本次尝试结果，符合重试策略时计划自动重试
+		e.finishAttempt(task)
+		e.scheduleAutoRetry(task)
+
 		// 保存最终状态
 		e.db.Save(task.Record)
+		recordExecutionEvent(e.db, task.Record, from, "", finalReason(task.Record, verification))
 
 		// 广播最终状态与结果
 		if e.progressBroadcaster != nil {
@@ -317,7 +422,7 @@ func (e *ExecutionEngine) executeTask(task *ExecutionTask) {
 		}
 
 		if e.logBroadcaster != nil {
-			finalLine := "执行完成"
+			finalLine := finalMessage(task.Record.Status)
 			if err != nil {
 				finalLine = fmt.Sprintf("执行失败: %v", err)
 			}
@@ -328,12 +433,43 @@ func (e *ExecutionEngine) executeTask(task *ExecutionTask) {
 	// 步骤1: 准备执行环境
 	e.updateStage(task, "准备执行环境")
 
-	// 解密连接密码
-	password, err := e.crypto.Decrypt(task.Record.Connection.Password)
+	// 解密连接密码与TLS私钥
+	dbConn, err := buildDatabaseConnection(e.crypto, &task.Record.Connection, task.Record.DatabaseName)
 	if err != nil {
-		err = fmt.Errorf("密码解密失败: %v", err)
 		return
 	}
+	password := dbConn.Password
+
+	// 从头重试：清理上次中断残留的新表与触发器
+	if err = e.dropResumeArtifacts(task); err != nil {
+		return
+	}
+
+	// 检查实例负载是否已接近阈值
+	e.checkServerLoad(task, dbConn)
+
+	// 检查目标表上是否存在可能阻塞切换的长事务
+	e.checkTransactions(task, dbConn)
+
+	// 采集执行前表结构快照
+	e.updateStage(task, "采集执行前表结构")
+	before := e.captureSnapshot(task, dbConn, models.SnapshotBefore)
+
+	// 删除列前导出列数据，备份失败则中止执行
+	if len(DroppedColumns(task.Record)) > 0 {
+		e.updateStage(task, "备份待删除列数据")
+		if _, err = e.backups.Backup(task.Record, dbConn); err != nil {
+			err = fmt.Errorf("删除列数据备份失败: %v", err)
+			return
+		}
+	}
+
+	// 保留旧表（或续跑模式必然保留旧表）时预先确定 pt-osc 将使用的旧表名，避免执行后误认已有的同名旧表
+	if (task.Record.ExecutionParams != nil && task.Record.ExecutionParams.KeepOldTable) || hasResumeOptions(task.Record.GeneratedCommand) {
+		if task.expectedOldTable, err = utils.NextOldTableName(dbConn, task.Record.DatabaseName, task.Record.TargetTableName); err != nil {
+			return
+		}
+	}
 
 	// 步骤2: 创建Docker容器
 	e.updateStage(task, "创建执行容器")
@@ -360,6 +496,17 @@ func (e *ExecutionEngine) executeTask(task *ExecutionTask) {
 		Environment: map[string]string{
 			"MYSQL_PWD": password, // 通过环境变量传递密码
 		},
+		Files: utils.PTTLSFiles(dbConn), // TLS私钥以文件写入容器
+	}
+	// TLS证书通过环境变量传入容器，由命令前置脚本写入文件
+	for k, v := range utils.PTTLSEnvironment(dbConn) {
+		containerConfig.Environment[k] = v
+	}
+	// 切换保护插件源码同样经环境变量传入
+	if task.Record.ExecutionParams != nil && task.Record.ExecutionParams.HoldCutover {
+		for k, v := range utils.PTCutoverGuardEnvironment() {
+			containerConfig.Environment[k] = v
+		}
 	}
 
 	containerID, err := e.dockerService.CreatePTContainer(containerConfig)
@@ -382,37 +529,159 @@ func (e *ExecutionEngine) executeTask(task *ExecutionTask) {
 	// 步骤4: 监控执行进度
 	e.updateStage(task, "正在执行DDL操作")
 
-	// 启动日志监控（本次执行开始前清空旧日志缓存）
-	task.Record.ExecutionLogs = nil
+	// 启动日志监控（本次执行开始前清空进度采样）
+	e.progress.Clear(task.ID)
+	e.recordSample(task, true)
+	task.mutex.Lock()
+	task.watching = true
+	task.lastLogAt = time.Now()
+	task.lastProgressAt = time.Now()
+	task.mutex.Unlock()
 	go e.monitorContainerLogs(task)
 
+	// 独立连接采集实例负载，容器结束后停止
+	loadStop := make(chan struct{})
+	go e.monitorServerLoad(task, dbConn, loadStop)
+
 	// 等待容器完成
 	result, waitErr := e.dockerService.WaitContainer(containerID)
+	close(loadStop)
 	if waitErr != nil {
 		err = fmt.Errorf("等待容器完成失败: %v", waitErr)
 		return
 	}
 
-	// 合并并保存执行日志（stdout/stderr）
-	combinedLogs := result.Output
+	exitCode := result.ExitCode
+	task.exitCode = &exitCode
+
+	// 合并容器输出（stdout/stderr），逐行日志已在监控时写入分块
+	task.output = result.Output
 	if result.Error != "" {
-		if combinedLogs != "" {
-			combinedLogs += "\n"
+		if task.output != "" {
+			task.output += "\n"
 		}
-		combinedLogs += result.Error
-	}
-	if combinedLogs != "" {
-		task.Record.ExecutionLogs = &combinedLogs
+		task.output += result.Error
 	}
 
+	// 记录 pt-osc 历史任务ID，失败后可 --resume 续跑
+	e.capturePTJobID(task, dbConn)
+
 	// 检查执行结果
 	if result.ExitCode != 0 {
 		err = fmt.Errorf("PT工具执行失败，退出码: %d, 错误信息: %s", result.ExitCode, result.Error)
 		return
 	}
 
+	// 复制完成：记录最终采样并停止健康检查
+	task.mutex.Lock()
+	task.watching = false
+	task.Progress = 100.0
+	task.Throttled = false
+	task.processedRows = task.Record.TotalRows
+	task.mutex.Unlock()
+	e.recordSample(task, true)
+
 	// 清理容器
 	e.dockerService.RemoveContainer(containerID, true)
+
+	// 采集执行后表结构快照
+	e.updateStage(task, "采集执行后表结构")
+	after := e.captureSnapshot(task, dbConn, models.SnapshotAfter)
+
+	// 碎片整理：对比前后表空间计算实际回收空间
+	if task.Record.DDLType != nil && *task.Record.DDLType == models.DDLFragment && before != nil && after != nil {
+		reclaimed := spaceOf(before) - spaceOf(after)
+		task.Record.ReclaimedBytes = &reclaimed
+		e.logLine(task, fmt.Sprintf("[%s] 碎片整理完成，表空间 %s -> %s，回收 %s", time.Now().Format("15:04:05"),
+			utils.FormatBytes(spaceOf(before)), utils.FormatBytes(spaceOf(after)), utils.FormatBytes(reclaimed)))
+	}
+
+	// 续跑模式切换后残留的触发器与旧表
+	if cleanErr := e.dropCompletedArtifacts(task, dbConn); cleanErr != nil {
+		e.logLine(task, fmt.Sprintf("[%s] 清理续跑模式残留失败: %v", time.Now().Format("15:04:05"), cleanErr))
+	}
+
+	// 登记保留的旧表
+	if oldErr := e.oldTables.MarkKept(task.Record, dbConn, task.expectedOldTable, task.output); oldErr != nil {
+		e.logLine(task, fmt.Sprintf("[%s] 登记保留旧表失败: %v", time.Now().Format("15:04:05"), oldErr))
+	}
+
+	// 执行后校验：表结构、新旧表行数与可选的校验和
+	e.updateStage(task, "执行后校验")
+	verification = e.verifyExecution(task, dbConn, before, after)
+}
+
+// finalMessage 执行结束时的日志提示
+func finalMessage(status models.ExecutionStatus) string {
+	if status == models.StatusCompletedWithWarnings {
+		return "执行完成（执行后校验存在不一致，请检查校验结果）"
+	}
+	return "执行完成"
+}
+
+// finalReason 最终状态变更的原因
+func finalReason(record *models.ExecutionRecord, verification *VerificationReport) string {
+	switch record.Status {
+	case models.StatusFailed, models.StatusCancelled:
+		if record.ErrorMessage != nil {
+			return *record.ErrorMessage
+		}
+	case models.StatusCompletedWithWarnings:
+		return "执行后校验未通过"
+	}
+	return finalMessage(record.Status)
+}
+
+// captureSnapshot 采集表结构快照，失败仅记录日志不影响执行结果
+func (e *ExecutionEngine) captureSnapshot(task *ExecutionTask, dbConn *utils.DatabaseConnection, phase models.SnapshotPhase) *models.SchemaSnapshot {
+	snapshot, err := e.snapshots.Capture(dbConn, task.Record, phase)
+	if err != nil {
+		e.logLine(task, fmt.Sprintf("[%s] 表结构快照采集失败(%s): %v", time.Now().Format("15:04:05"), phase, err))
+		return nil
+	}
+	return snapshot
+}
+
+// classifyFailure 根据执行日志与错误信息识别失败原因，记录分类与处理建议
+func (e *ExecutionEngine) classifyFailure(task *ExecutionTask, err error) {
+	output := err.Error()
+	if task.output != "" {
+		output = task.output + "\n" + output
+	}
+	failure := utils.ClassifyPTFailure(output)
+	task.Record.FailureCategory = &failure.Category
+	task.Record.FailureLine = &failure.MatchedLine
+	task.Record.FailureHint = &failure.Hint
+	e.logLine(task, fmt.Sprintf("[%s] 失败原因: %s（%s）", time.Now().Format("15:04:05"), failure.Title, failure.Hint))
+}
+
+// capturePTJobID 从 pt-osc 历史表读取本次任务ID（命令未启用 --history 时跳过）
+func (e *ExecutionEngine) capturePTJobID(task *ExecutionTask, dbConn *utils.DatabaseConnection) {
+	if !strings.Contains(task.Record.GeneratedCommand, "--history") {
+		return
+	}
+	jobID, err := utils.LatestPTJobID(dbConn, task.Record.DatabaseName, task.Record.TargetTableName)
+	if err != nil {
+		e.logLine(task, fmt.Sprintf("[%s] 读取 pt-osc 历史任务ID失败: %v", time.Now().Format("15:04:05"), err))
+		return
+	}
+	task.Record.PTJobID = &jobID
+}
+
+// logLine 输出一行执行日志（本地回调、持久化与WebSocket广播）
+func (e *ExecutionEngine) logLine(task *ExecutionTask, line string) {
+	if task.LogCallback != nil {
+		task.LogCallback(line)
+	}
+	task.logs.Append(line)
+	if e.logBroadcaster != nil {
+		e.logBroadcaster(task.ID, line)
+	}
+}
+
+// spaceOf 快照记录的表空间总量（数据 + 索引 + 空闲）
+func spaceOf(snapshot *models.SchemaSnapshot) int64 {
+	return snapshot.DataLength + snapshot.IndexLength + snapshot.DataFree
 }
 
 // updateStage 更新任务阶段
@@ -424,10 +693,11 @@ func (e *ExecutionEngine) updateStage(task *ExecutionTask, stage string) {
 
 	logLine := fmt.Sprintf("[%s] %s", time.Now().Format("15:04:05"), stage)
 
-	// 本地回调
+	// 本地回调与持久化
 	if task.LogCallback != nil {
 		task.LogCallback(logLine)
 	}
+	task.logs.Append(logLine)
 
 	// WebSocket广播
 	if e.logBroadcaster != nil {
@@ -435,51 +705,169 @@ func (e *ExecutionEngine) updateStage(task *ExecutionTask, stage string) {
 	}
 }
 
-// updateProgress 更新任务进度
+// updateProgress 更新任务进度（progress/speed 为0表示本次未解析到该项，沿用上次的值）
 func (e *ExecutionEngine) updateProgress(task *ExecutionTask, progress float64, speed float64) {
 	task.mutex.Lock()
-	defer task.mutex.Unlock()
+	if progress > 0 {
+		if progress != task.Progress {
+			task.lastProgressAt = time.Now()
+		}
+		task.Progress = progress
+		// 出现复制进度说明已恢复复制
+		task.Throttled = false
+		if task.Record.TotalRows > 0 {
+			task.processedRows = int64(progress / 100 * float64(task.Record.TotalRows))
+		}
+	}
+	if speed > 0 {
+		task.Speed = speed
+	}
+	e.broadcastProgress(task)
+	task.mutex.Unlock()
+
+	e.recordSample(task, false)
+}
+
+// setThrottled 更新 pt-osc 暂停状态，状态变化时立即采样
+func (e *ExecutionEngine) setThrottled(task *ExecutionTask, throttled bool) {
+	task.mutex.Lock()
+	changed := task.Throttled != throttled
+	task.Throttled = throttled
+	if changed {
+		e.broadcastProgress(task)
+	}
+	task.mutex.Unlock()
+
+	if changed {
+		e.recordSample(task, true)
+	}
+}
+
+// broadcastProgress 通过WebSocket广播进度（调用方持有 task.mutex）
+func (e *ExecutionEngine) broadcastProgress(task *ExecutionTask) {
+	if e.progressBroadcaster == nil {
+		return
+	}
+	progressData := map[string]interface{}{
+		"execution_id":  task.ID,
+		"status":        string(task.Status),
+		"progress":      task.Progress,
+		"current_speed": task.Speed,
+		"current_stage": task.CurrentStage,
+		"throttled":     task.Throttled,
+		"health":        string(task.Health),
+		"health_reason": task.HealthReason,
+		"timestamp":     time.Now().Format("2006-01-02 15:04:05"),
+	}
+	if task.ServerLoad != nil {
+		progressData["server_load"] = task.ServerLoad
+	}
+	if task.TransactionGuard != nil {
+		progressData["transaction_guard"] = task.TransactionGuard
+	}
+	e.progressBroadcaster(task.ID, progressData)
+}
+
+// recordSample 按最小间隔写入进度采样；force 时忽略间隔限制
+func (e *ExecutionEngine) recordSample(task *ExecutionTask, force bool) {
+	task.mutex.Lock()
+	now := time.Now()
+	if !force && now.Sub(task.lastSampleAt) < progressSampleInterval {
+		task.mutex.Unlock()
+		return
+	}
+
+	// 日志未给出速度时按两次采样间的行数差计算
+	speed := task.Speed
+	if speed == 0 && !task.lastSampleAt.IsZero() {
+		if seconds := now.Sub(task.lastSampleAt).Seconds(); seconds > 0 && task.processedRows >= task.lastSampleRows {
+			speed = float64(task.processedRows-task.lastSampleRows) / seconds
+		}
+	}
+
+	// 更新速度移动平均（暂停期间不计入），低于平均值的一定比例视为降速
+	if !task.Throttled && speed > 0 {
+		task.slow = task.speedSamples >= minSpeedSamples && speed < task.speedAvg*e.cfg.SlowdownRatio
+		if task.speedSamples == 0 {
+			task.speedAvg = speed
+		} else {
+			task.speedAvg = speedAvgAlpha*speed + (1-speedAvgAlpha)*task.speedAvg
+		}
+		task.speedSamples++
+	}
+
+	sample := &models.ExecutionProgressSample{
+		ExecutionID:   task.ID,
+		SampledAt:     now,
+		Progress:      task.Progress,
+		ProcessedRows: task.processedRows,
+		Speed:         speed,
+		Stage:         task.CurrentStage,
+		Throttled:     task.Throttled,
+	}
+	task.lastSampleAt = now
+	task.lastSampleRows = task.processedRows
+	task.mutex.Unlock()
+
+	if err := e.progress.Record(sample); err != nil {
+		fmt.Printf("记录进度采样失败: %v\n", err)
+	}
+}
 
-	task.Progress = progress
-	task.Speed = speed
-
-	// WebSocket广播进度
-	if e.progressBroadcaster != nil {
-		progressData := map[string]interface{}{
-			"execution_id":  task.ID,
-			"status":        string(task.Status),
-			"progress":      progress,
-			"current_speed": speed,
-			"current_stage": task.CurrentStage,
-			"timestamp":     time.Now().Format("2006-01-02 15:04:05"),
+// applyProgressSummary 用进度序列回填已处理行数与平均速度；无有效序列时按总行数/耗时估算
+func (e *ExecutionEngine) applyProgressSummary(task *ExecutionTask, durationSeconds int64) {
+	summary, err := e.progress.Summarize(task.ID)
+	if err == nil {
+		task.Record.ProcessedRows = summary.ProcessedRows
+		if summary.AvgSpeed > 0 {
+			task.Record.AvgSpeed = &summary.AvgSpeed
+			return
 		}
-		e.progressBroadcaster(task.ID, progressData)
+	}
+
+	if task.Record.Status.Succeeded() && durationSeconds > 0 && task.Record.TotalRows > 0 {
+		task.Record.ProcessedRows = task.Record.TotalRows
+		speed := float64(task.Record.TotalRows) / float64(durationSeconds)
+		task.Record.AvgSpeed = &speed
 	}
 }
 
 // monitorContainerLogs 监控容器日志
 func (e *ExecutionEngine) monitorContainerLogs(task *ExecutionTask) {
 	err := e.dockerService.StreamContainerLogs(task.ContainerID, func(logLine string) {
+		task.mutex.Lock()
+		task.lastLogAt = time.Now()
+		task.mutex.Unlock()
+
 		if task.LogCallback != nil {
 			task.LogCallback(logLine)
 		}
+		task.logs.Append(logLine)
 
 		// WebSocket广播日志
 		if e.logBroadcaster != nil {
 			e.logBroadcaster(task.ID, logLine)
 		}
 
-		// 尝试从日志中解析进度信息
+		// 解析暂停状态与进度信息
+		if throttled, ok := parseThrottleFromLog(logLine); ok {
+			e.setThrottled(task, throttled)
+		}
+		if stage := parseCutoverGuardStage(logLine); stage != "" {
+			e.holdCutover(task, stage)
+		}
 		progress, speed := e.parseProgressFromLog(logLine)
-		if progress > 0 {
+		if progress > 0 || speed > 0 {
 			e.updateProgress(task, progress, speed)
 		}
 	})
 
 	if err != nil {
+		line := fmt.Sprintf("日志监控错误: %v", err)
 		if task.LogCallback != nil {
-			task.LogCallback(fmt.Sprintf("日志监控错误: %v", err))
+			task.LogCallback(line)
 		}
+		task.logs.Append(line)
 	}
 }
 
@@ -487,9 +875,22 @@ func (e *ExecutionEngine) monitorContainerLogs(task *ExecutionTask) {
 func (e *ExecutionEngine) parseProgressFromLog(logLine string) (float64, float64) {
 	// 常见格式示例：
 	// "Copying approximately 5000000 rows"
+	// "Copying `db`.`tbl`:  75% 01:23 remain"
 	// "Copied 3750000/5000000 rows (75%)"
 	// "Current copy rate: 5420 rows/sec"
 
+	// pt-osc --progress 输出："Copying `db`.`tbl`:  75% 01:23 remain"
+	if strings.HasPrefix(logLine, "Copying ") {
+		if colon := strings.LastIndex(logLine, ": "); colon >= 0 {
+			fields := strings.Fields(logLine[colon+2:])
+			if len(fields) > 0 && strings.HasSuffix(fields[0], "%") {
+				if p, err := strconv.ParseFloat(strings.TrimSuffix(fields[0], "%"), 64); err == nil {
+					return p, 0
+				}
+			}
+		}
+	}
+
 	// 简单解析括号内百分比
 	if lp := strings.LastIndex(logLine, "("); lp >= 0 && strings.HasSuffix(logLine, ")") {
 		pctStr := strings.TrimSuffix(logLine[lp+1:], ")")
@@ -515,6 +916,142 @@ func (e *ExecutionEngine) parseProgressFromLog(logLine string) (float64, float64
 	return 0, 0
 }
 
+// parseThrottleFromLog 从日志识别 pt-osc 暂停（max-load / 复制延迟）状态
+func parseThrottleFromLog(logLine string) (throttled bool, ok bool) {
+	switch {
+	case strings.Contains(logLine, "Pausing because"),
+		strings.Contains(logLine, "Waiting."),
+		strings.Contains(logLine, "Replica lag is"),
+		strings.Contains(logLine, "Slave lag is"):
+		return true, true
+	}
+	return false, false
+}
+
+// watchdog 定期检查运行中任务的健康状态
+func (e *ExecutionEngine) watchdog() {
+	if e.cfg.WatchdogInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(e.cfg.WatchdogInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-ticker.C:
+			e.mutex.RLock()
+			tasks := make([]*ExecutionTask, 0, len(e.runningTasks))
+			for _, task := range e.runningTasks {
+				tasks = append(tasks, task)
+			}
+			e.mutex.RUnlock()
+
+			now := time.Now()
+			for _, task := range tasks {
+				health, reason := e.evaluateHealth(task, now)
+				e.setHealth(task, health, reason)
+			}
+		}
+	}
+}
+
+// evaluateHealth 判断任务健康状态：无输出 > 暂停 > 进度停滞 > 降速
+func (e *ExecutionEngine) evaluateHealth(task *ExecutionTask, now time.Time) (models.ExecutionHealth, string) {
+	task.mutex.RLock()
+	defer task.mutex.RUnlock()
+
+	if !task.watching {
+		return models.HealthHealthy, ""
+	}
+
+	if silent := now.Sub(task.lastLogAt); e.cfg.StallLogTimeout > 0 && silent >= e.cfg.StallLogTimeout {
+		return models.HealthNoOutput, fmt.Sprintf("已 %s 无日志输出", utils.FormatDuration(int64(silent.Seconds())))
+	}
+	if task.Throttled {
+		return models.HealthThrottled, "pt-osc 因负载或复制延迟暂停复制"
+	}
+	if stuck := now.Sub(task.lastProgressAt); e.cfg.StallProgressTimeout > 0 && stuck >= e.cfg.StallProgressTimeout {
+		return models.HealthNoProgress, fmt.Sprintf("进度 %.2f%% 已 %s 未推进", task.Progress, utils.FormatDuration(int64(stuck.Seconds())))
+	}
+	if task.slow {
+		return models.HealthSlow, fmt.Sprintf("当前速度低于移动平均 %.0f 行/秒的 %.0f%%", task.speedAvg, e.cfg.SlowdownRatio*100)
+	}
+	return models.HealthHealthy, ""
+}
+
+// setHealth 更新健康状态；状态变化时输出日志、广播并记录审计与通知
+func (e *ExecutionEngine) setHealth(task *ExecutionTask, health models.ExecutionHealth, reason string) {
+	task.mutex.Lock()
+	previous := task.Health
+	if previous == health {
+		task.HealthReason = reason
+		task.mutex.Unlock()
+		return
+	}
+	task.Health = health
+	task.HealthReason = reason
+	e.broadcastProgress(task)
+	task.mutex.Unlock()
+
+	message := fmt.Sprintf("执行状态由 %s 变为 %s", previous, health)
+	if reason != "" {
+		message += "：" + reason
+	}
+	e.logLine(task, fmt.Sprintf("[%s] %s", time.Now().Format("15:04:05"), message))
+
+	data := map[string]interface{}{
+		"from":     previous,
+		"to":       health,
+		"reason":   reason,
+		"database": task.Record.DatabaseName,
+		"table":    task.Record.TargetTableName,
+	}
+	e.recordHealthAudit(task, health, data)
+
+	level := "warning"
+	title := fmt.Sprintf("执行异常: %s.%s", task.Record.DatabaseName, task.Record.TargetTableName)
+	if health == models.HealthHealthy {
+		level = "info"
+		title = fmt.Sprintf("执行恢复正常: %s.%s", task.Record.DatabaseName, task.Record.TargetTableName)
+	}
+	e.notifier.Notify(&Notification{
+		Event:       string(models.ActionExecutionHealth),
+		Level:       level,
+		Title:       title,
+		Message:     message,
+		ExecutionID: task.ID,
+		Data:        data,
+	})
+}
+
+// recordHealthAudit 记录健康状态变化的审计事件
+func (e *ExecutionEngine) recordHealthAudit(task *ExecutionTask, health models.ExecutionHealth, data map[string]interface{}) {
+	resourceType := "execution"
+	username := "system"
+	auditLog := &models.AuditLog{
+		Username:     &username,
+		Action:       string(models.ActionExecutionHealth),
+		ResourceType: &resourceType,
+		ResourceID:   &task.ID,
+		Status:       models.AuditStatusSuccess,
+		RiskLevel:    "medium",
+		CreatedAt:    time.Now(),
+	}
+	if health == models.HealthHealthy {
+		auditLog.RiskLevel = "low"
+	}
+	if raw, err := json.Marshal(data); err == nil {
+		payload := json.RawMessage(raw)
+		auditLog.RequestData = &payload
+	}
+	if err := e.audit.CreateAuditLog(auditLog); err != nil {
+		fmt.Printf("记录健康状态审计失败: %v\n", err)
+	}
+}
+
 // Shutdown 关闭执行引擎
 func (e *ExecutionEngine) Shutdown() error {
 	// 取消所有任务
@@ -531,7 +1068,7 @@ func (e *ExecutionEngine) Shutdown() error {
 			// 强制停止所有运行中的任务
 			e.mutex.Lock()
 			for recordID := range e.runningTasks {
-				e.StopExecution(recordID)
+				e.StopExecution(recordID, "")
 			}
 			e.mutex.Unlock()
 			return fmt.Errorf("关闭超时，已强制停止所有任务")
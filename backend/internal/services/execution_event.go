@@ -0,0 +1,70 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/fengzhencai/MySQLer/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// ExecutionTimeline 执行记录的完整时间线：每次执行尝试与全部状态变更
+type ExecutionTimeline struct {
+	ExecutionID string                    `json:"execution_id"`
+	Status      models.ExecutionStatus    `json:"status"`
+	Attempts    []models.ExecutionAttempt `json:"attempts"` // 日志按 attempt 过滤 /logs 获取
+	Events      []models.ExecutionEvent   `json:"events"`
+}
+
+// GetTimeline 获取执行记录的时间线
+func (s *ExecutionService) GetTimeline(id string) (*ExecutionTimeline, error) {
+	var record models.ExecutionRecord
+	if err := s.db.Select("id", "status").First(&record, "id = ?", id).Error; err != nil {
+		return nil, fmt.Errorf("执行记录不存在")
+	}
+
+	attempts, err := s.ListAttempts(id)
+	if err != nil {
+		return nil, err
+	}
+	events := []models.ExecutionEvent{}
+	if err := s.db.Where("execution_id = ?", id).Order("created_at ASC, id ASC").Find(&events).Error; err != nil {
+		return nil, err
+	}
+
+	return &ExecutionTimeline{
+		ExecutionID: record.ID,
+		Status:      record.Status,
+		Attempts:    attempts,
+		Events:      events,
+	}, nil
+}
+
+// GetAttempt 获取单次执行尝试
+func (s *ExecutionService) GetAttempt(id string, attempt int) (*models.ExecutionAttempt, error) {
+	var result models.ExecutionAttempt
+	if err := s.db.Where("execution_id = ? AND attempt = ?", id, attempt).First(&result).Error; err != nil {
+		return nil, fmt.Errorf("执行尝试不存在")
+	}
+	return &result, nil
+}
+
+// recordExecutionEvent 记录执行状态变更（状态未变化时跳过）
+func recordExecutionEvent(db *gorm.DB, record *models.ExecutionRecord, from models.ExecutionStatus, actor, reason string) {
+	if from == record.Status {
+		return
+	}
+	if actor == "" {
+		actor = models.EventActorSystem
+	}
+	event := &models.ExecutionEvent{
+		ExecutionID: record.ID,
+		Attempt:     record.Attempts,
+		FromStatus:  from,
+		ToStatus:    record.Status,
+		Actor:       actor,
+		Reason:      reason,
+	}
+	if err := db.Create(event).Error; err != nil {
+		fmt.Printf("记录执行状态变更失败: %v\n", err)
+	}
+}
@@ -0,0 +1,481 @@
+package services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fengzhencai/MySQLer/backend/internal/config"
+	"github.com/fengzhencai/MySQLer/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// 日志分块：每块最多行数与未满分块的刷新间隔
+const (
+	logChunkLines    = 200
+	logFlushInterval = 2 * time.Second
+)
+
+// 日志分页默认与最大行数
+const (
+	defaultLogPageLimit = 500
+	maxLogPageLimit     = 5000
+)
+
+// logContentBatch 搜索或下载时每批加载的分块数
+const logContentBatch = 20
+
+// ExecutionLogService 执行日志分块存储
+type ExecutionLogService struct {
+	db       *gorm.DB
+	cfg      *config.Config
+	stopChan chan struct{}
+	stopOnce sync.Once
+}
+
+// NewExecutionLogService 创建执行日志服务
+func NewExecutionLogService(db *gorm.DB, cfg *config.Config) *ExecutionLogService {
+	return &ExecutionLogService{
+		db:       db,
+		cfg:      cfg,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// LogQuery 日志查询条件
+type LogQuery struct {
+	Offset  int64  `form:"offset" binding:"omitempty,min=0"` // 起始行（按过滤后的行计）
+	Limit   int    `form:"limit" binding:"omitempty,min=1"`
+	Search  string `form:"search" binding:"omitempty,max=200"` // 正则表达式
+	Attempt int    `form:"attempt" binding:"omitempty,min=1"`  // 仅查看第几次执行
+}
+
+// LogLine 单行日志
+type LogLine struct {
+	LineNo  int64  `json:"line_no"`
+	Attempt int    `json:"attempt"`
+	Text    string `json:"text"`
+}
+
+// LogPage 分页日志
+type LogPage struct {
+	Logs    string    `json:"logs"` // 本页日志文本
+	Lines   []LogLine `json:"lines"`
+	Offset  int64     `json:"offset"`
+	Limit   int       `json:"limit"`
+	Total   int64     `json:"total"` // 过滤后的总行数
+	HasMore bool      `json:"has_more"`
+}
+
+// Query 分页查询执行日志，支持按执行次数与正则过滤
+func (s *ExecutionLogService) Query(executionID string, query *LogQuery) (*LogPage, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultLogPageLimit
+	}
+	if limit > maxLogPageLimit {
+		limit = maxLogPageLimit
+	}
+	pattern, err := compileLogSearch(query.Search)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &LogPage{Lines: []LogLine{}, Offset: query.Offset, Limit: limit}
+	end := query.Offset + int64(limit)
+	err = s.scan(executionID, query.Attempt, pattern, query.Offset, end, func(line LogLine, index int64) {
+		if index >= query.Offset && index < end {
+			page.Lines = append(page.Lines, line)
+		}
+		page.Total = index + 1
+	}, func(skipped int64) {
+		page.Total += skipped
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	texts := make([]string, len(page.Lines))
+	for i, line := range page.Lines {
+		texts[i] = line.Text
+	}
+	page.Logs = strings.Join(texts, "\n")
+	page.HasMore = page.Total > end
+	return page, nil
+}
+
+// Download 将过滤后的全部日志以纯文本写出
+func (s *ExecutionLogService) Download(executionID string, query *LogQuery, w io.Writer) error {
+	pattern, err := compileLogSearch(query.Search)
+	if err != nil {
+		return err
+	}
+	var writeErr error
+	err = s.scan(executionID, query.Attempt, pattern, 0, 0, func(line LogLine, _ int64) {
+		if writeErr == nil {
+			_, writeErr = io.WriteString(w, line.Text+"\n")
+		}
+	}, nil)
+	if err != nil {
+		return err
+	}
+	return writeErr
+}
+
+// Append 直接追加一段日志（执行结束后的补充记录，如旧表清理）
+func (s *ExecutionLogService) Append(executionID string, attempt int, lines ...string) error {
+	if len(lines) == 0 {
+		return nil
+	}
+	seq, next, err := s.tail(executionID)
+	if err != nil {
+		return err
+	}
+	_, _, err = s.insertChunk(executionID, attempt, seq, next, lines)
+	return err
+}
+
+// NewWriter 创建执行期间的日志写入器，续接已有分块的序号与行号
+func (s *ExecutionLogService) NewWriter(executionID string, attempt int) *ExecutionLogWriter {
+	seq, next, err := s.tail(executionID)
+	if err != nil {
+		fmt.Printf("读取执行日志分块失败: %v\n", err)
+	}
+	writer := &ExecutionLogWriter{
+		service:     s,
+		executionID: executionID,
+		attempt:     attempt,
+		nextSeq:     seq,
+		nextLine:    next,
+		stop:        make(chan struct{}),
+	}
+	go writer.flushLoop()
+	return writer
+}
+
+// StartJanitor 启动日志压缩与过期清理任务
+func (s *ExecutionLogService) StartJanitor() {
+	interval := s.cfg.LogJanitorInterval
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stopChan:
+				return
+			case <-ticker.C:
+				s.cleanup()
+			}
+		}
+	}()
+}
+
+// Stop 停止清理任务
+func (s *ExecutionLogService) Stop() {
+	s.stopOnce.Do(func() { close(s.stopChan) })
+}
+
+// cleanup 删除超过保留期限的日志，压缩较早的未压缩分块
+func (s *ExecutionLogService) cleanup() {
+	if s.cfg.LogRetention > 0 {
+		cutoff := time.Now().Add(-s.cfg.LogRetention)
+		if err := s.db.Where("created_at < ?", cutoff).Delete(&models.ExecutionLogChunk{}).Error; err != nil {
+			fmt.Printf("清理过期执行日志失败: %v\n", err)
+		}
+		// 旧版本整段保存在执行记录中的日志
+		s.db.Model(&models.ExecutionRecord{}).
+			Where("end_time < ? AND execution_logs IS NOT NULL", cutoff).
+			Update("execution_logs", nil)
+	}
+
+	if s.cfg.LogCompressAfter <= 0 {
+		return
+	}
+	cutoff := time.Now().Add(-s.cfg.LogCompressAfter)
+	for {
+		var chunks []models.ExecutionLogChunk
+		err := s.db.Where("compressed = ? AND created_at < ?", false, cutoff).
+			Order("id").Limit(100).Find(&chunks).Error
+		if err != nil || len(chunks) == 0 {
+			return
+		}
+		for _, chunk := range chunks {
+			content, err := gzipBytes(chunk.Content)
+			if err != nil {
+				fmt.Printf("压缩执行日志失败: %v\n", err)
+				return
+			}
+			if err := s.db.Model(&chunk).Updates(map[string]interface{}{
+				"content":    content,
+				"compressed": true,
+			}).Error; err != nil {
+				fmt.Printf("压缩执行日志失败: %v\n", err)
+				return
+			}
+		}
+	}
+}
+
+// scan 按行号顺序遍历日志；onLine 收到过滤后的行及其序号
+// 无正则过滤且提供 onSkip 时，不在 [from, to) 范围内的整块只计数不解压（onSkip 收到跳过的行数）
+func (s *ExecutionLogService) scan(executionID string, attempt int, pattern *regexp.Regexp, from, to int64,
+	onLine func(LogLine, int64), onSkip func(int64)) error {
+	db := s.db.Model(&models.ExecutionLogChunk{}).Where("execution_id = ?", executionID)
+	if attempt > 0 {
+		db = db.Where("attempt = ?", attempt)
+	}
+	var chunks []models.ExecutionLogChunk
+	if err := db.Select("id", "seq", "attempt", "first_line", "line_count").Order("seq").Find(&chunks).Error; err != nil {
+		return err
+	}
+	if len(chunks) == 0 {
+		return s.scanLegacy(executionID, attempt, pattern, onLine)
+	}
+
+	index := int64(0)
+	for start := 0; start < len(chunks); start += logContentBatch {
+		stop := start + logContentBatch
+		if stop > len(chunks) {
+			stop = len(chunks)
+		}
+		batch := chunks[start:stop]
+
+		// 仅加载需要解析的分块内容
+		var ids []int64
+		for _, chunk := range batch {
+			if pattern != nil || onSkip == nil || (index+int64(chunk.LineCount) > from && index < to) {
+				ids = append(ids, chunk.ID)
+			}
+			index += int64(chunk.LineCount)
+		}
+		index -= lineCountOf(batch)
+
+		contents := map[int64]models.ExecutionLogChunk{}
+		if len(ids) > 0 {
+			var loaded []models.ExecutionLogChunk
+			if err := s.db.Select("id", "content", "compressed").Where("id IN ?", ids).Find(&loaded).Error; err != nil {
+				return err
+			}
+			for _, chunk := range loaded {
+				contents[chunk.ID] = chunk
+			}
+		}
+
+		for _, chunk := range batch {
+			loaded, ok := contents[chunk.ID]
+			if !ok {
+				onSkip(int64(chunk.LineCount))
+				index += int64(chunk.LineCount)
+				continue
+			}
+			lines, err := chunkLines(&loaded)
+			if err != nil {
+				return fmt.Errorf("读取日志分块 %d 失败: %v", chunk.Seq, err)
+			}
+			for i, text := range lines {
+				if pattern != nil && !pattern.MatchString(text) {
+					continue
+				}
+				onLine(LogLine{LineNo: chunk.FirstLine + int64(i), Attempt: chunk.Attempt, Text: text}, index)
+				index++
+			}
+		}
+	}
+	return nil
+}
+
+// scanLegacy 遍历旧版本保存在执行记录中的整段日志
+func (s *ExecutionLogService) scanLegacy(executionID string, attempt int, pattern *regexp.Regexp, onLine func(LogLine, int64)) error {
+	var record models.ExecutionRecord
+	if err := s.db.Select("execution_logs").First(&record, "id = ?", executionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("执行记录不存在")
+		}
+		return err
+	}
+	if attempt > 0 || record.ExecutionLogs == nil || *record.ExecutionLogs == "" {
+		return nil
+	}
+
+	index := int64(0)
+	for i, text := range strings.Split(*record.ExecutionLogs, "\n") {
+		if pattern != nil && !pattern.MatchString(text) {
+			continue
+		}
+		onLine(LogLine{LineNo: int64(i), Text: text}, index)
+		index++
+	}
+	return nil
+}
+
+// tail 下一个分块序号与行号
+func (s *ExecutionLogService) tail(executionID string) (int, int64, error) {
+	var last models.ExecutionLogChunk
+	err := s.db.Select("seq", "first_line", "line_count").
+		Where("execution_id = ?", executionID).Order("seq DESC").First(&last).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	return last.Seq + 1, last.EndLine(), nil
+}
+
+// insertChunk 写入一个分块；序号冲突（并发追加）时按最新位置重试一次
+func (s *ExecutionLogService) insertChunk(executionID string, attempt, seq int, firstLine int64, lines []string) (int, int64, error) {
+	chunk := &models.ExecutionLogChunk{
+		ExecutionID: executionID,
+		Seq:         seq,
+		Attempt:     attempt,
+		FirstLine:   firstLine,
+		LineCount:   len(lines),
+		Content:     []byte(strings.Join(lines, "\n")),
+	}
+	if err := s.db.Create(chunk).Error; err != nil {
+		var tailErr error
+		if chunk.Seq, chunk.FirstLine, tailErr = s.tail(executionID); tailErr != nil {
+			return seq, firstLine, err
+		}
+		chunk.ID = 0
+		if err := s.db.Create(chunk).Error; err != nil {
+			return seq, firstLine, fmt.Errorf("保存执行日志失败: %v", err)
+		}
+	}
+	return chunk.Seq + 1, chunk.EndLine(), nil
+}
+
+// ExecutionLogWriter 执行期间的日志写入器：按行缓冲，满一块或定时写入
+type ExecutionLogWriter struct {
+	service     *ExecutionLogService
+	executionID string
+	attempt     int
+
+	mutex    sync.Mutex
+	buffer   []string
+	nextSeq  int
+	nextLine int64
+	closed   bool
+	stop     chan struct{}
+}
+
+// Append 追加一行日志（写入器为空时忽略）
+func (w *ExecutionLogWriter) Append(line string) {
+	if w == nil {
+		return
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.buffer = append(w.buffer, line)
+	// 关闭后到达的日志（容器输出尾部）立即写入
+	if w.closed || len(w.buffer) >= logChunkLines {
+		w.flushLocked()
+	}
+}
+
+// Close 写入剩余日志并停止定时刷新
+func (w *ExecutionLogWriter) Close() {
+	if w == nil {
+		return
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.closed {
+		return
+	}
+	w.closed = true
+	close(w.stop)
+	w.flushLocked()
+}
+
+// flushLoop 定时写入未满的分块，避免进程异常退出时丢失日志
+func (w *ExecutionLogWriter) flushLoop() {
+	ticker := time.NewTicker(logFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.mutex.Lock()
+			w.flushLocked()
+			w.mutex.Unlock()
+		}
+	}
+}
+
+// flushLocked 将缓冲写为一个分块（调用方持有锁）
+func (w *ExecutionLogWriter) flushLocked() {
+	if len(w.buffer) == 0 {
+		return
+	}
+	seq, next, err := w.service.insertChunk(w.executionID, w.attempt, w.nextSeq, w.nextLine, w.buffer)
+	if err != nil {
+		// 保留缓冲，下次刷新时重试
+		fmt.Printf("保存执行日志失败: %v\n", err)
+		return
+	}
+	w.nextSeq, w.nextLine = seq, next
+	w.buffer = nil
+}
+
+// compileLogSearch 编译日志搜索正则（为空时不过滤）
+func compileLogSearch(search string) (*regexp.Regexp, error) {
+	if search == "" {
+		return nil, nil
+	}
+	pattern, err := regexp.Compile(search)
+	if err != nil {
+		return nil, fmt.Errorf("无效的搜索表达式: %v", err)
+	}
+	return pattern, nil
+}
+
+// chunkLines 解析分块内容为日志行
+func chunkLines(chunk *models.ExecutionLogChunk) ([]string, error) {
+	content := chunk.Content
+	if chunk.Compressed {
+		reader, err := gzip.NewReader(bytes.NewReader(content))
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		if content, err = io.ReadAll(reader); err != nil {
+			return nil, err
+		}
+	}
+	return strings.Split(string(content), "\n"), nil
+}
+
+// gzipBytes gzip 压缩
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(data); err != nil {
+		gz.Close()
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// lineCountOf 分块的总行数
+func lineCountOf(chunks []models.ExecutionLogChunk) int64 {
+	var total int64
+	for _, chunk := range chunks {
+		total += int64(chunk.LineCount)
+	}
+	return total
+}
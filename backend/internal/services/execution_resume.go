@@ -0,0 +1,209 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fengzhencai/MySQLer/backend/internal/models"
+	"github.com/fengzhencai/MySQLer/backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+// 重试方式
+const (
+	RetryModeRestart = "restart" // 清理残留后从头执行
+	RetryModeResume  = "resume"  // 从中断处续跑（--resume）
+)
+
+// RetryRequest 重试请求
+type RetryRequest struct {
+	Mode string `json:"mode" binding:"omitempty,oneof=restart resume"`
+}
+
+// Retry 重试执行：restart 从头执行（上次残留的新表与触发器由执行引擎在任务开始时清理）；
+// resume 校验残留完整后追加 --resume 续跑
+// 每次重试生成新的执行尝试记录，之前的运行信息保留在尝试记录中
+func (s *ExecutionService) Retry(id string, userID string, mode string) error {
+	var record models.ExecutionRecord
+	if err := s.db.Preload("Connection").First(&record, "id = ?", id).Error; err != nil {
+		return err
+	}
+	if record.Status != models.StatusFailed && record.Status != models.StatusCancelled {
+		return fmt.Errorf("仅失败或已取消的任务允许重试")
+	}
+	if mode == "" {
+		mode = RetryModeRestart
+	}
+	from := record.Status
+
+	if err := prepareRetry(s.crypto, &record, mode); err != nil {
+		return err
+	}
+	// 手动重试取代已计划的自动重试
+	record.NextRetryAt = nil
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := nextAttempt(tx, &record, models.AttemptManual, mode, userID, nil); err != nil {
+			return err
+		}
+		if err := tx.Omit("Connection").Save(&record).Error; err != nil {
+			return err
+		}
+		recordExecutionEvent(tx, &record, from, userID, fmt.Sprintf("手动重试（%s）", mode))
+		return nil
+	})
+}
+
+// ResumeCheck 检查失败或已取消的执行能否从中断处续跑
+func (s *ExecutionService) ResumeCheck(id string) (*utils.ResumeState, error) {
+	var record models.ExecutionRecord
+	if err := s.db.Preload("Connection").First(&record, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("执行记录不存在")
+		}
+		return nil, err
+	}
+	if record.Status != models.StatusFailed && record.Status != models.StatusCancelled {
+		return nil, fmt.Errorf("仅失败或已取消的任务可以续跑")
+	}
+	state, _, err := resumeStateOf(s.crypto, &record)
+	return state, err
+}
+
+// prepareRetry 按重试方式调整命令并将记录重置为pending（不保存，不修改目标库）
+// 从头执行时保留上次的 pt-osc 任务ID，供任务开始时定位并清理残留
+func prepareRetry(crypto *utils.CryptoService, record *models.ExecutionRecord, mode string) error {
+	if mode == RetryModeResume {
+		state, _, err := resumeStateOf(crypto, record)
+		if err != nil {
+			return err
+		}
+		if !state.Resumable {
+			return fmt.Errorf("无法续跑: %s", state.Reason)
+		}
+		record.GeneratedCommand = utils.WithResume(record.GeneratedCommand, state.JobID)
+	} else {
+		record.GeneratedCommand = utils.WithoutResume(record.GeneratedCommand)
+		record.ProcessedRows = 0
+	}
+
+	record.Status = models.StatusPending
+	record.StartTime = nil
+	record.EndTime = nil
+	record.DurationSeconds = nil
+	record.ErrorMessage = nil
+	record.FailureCategory = nil
+	record.FailureLine = nil
+	record.FailureHint = nil
+	return nil
+}
+
+// resumeStateOf 读取执行记录对应的 pt-osc 历史任务状态
+func resumeStateOf(crypto *utils.CryptoService, record *models.ExecutionRecord) (*utils.ResumeState, *utils.DatabaseConnection, error) {
+	if record.PTJobID == nil {
+		return &utils.ResumeState{
+			Triggers: []string{},
+			Reason:   "执行未记录 pt-osc 历史任务ID（未启用 --history）",
+		}, nil, nil
+	}
+	dbConn, err := buildDatabaseConnection(crypto, &record.Connection, record.DatabaseName)
+	if err != nil {
+		return nil, nil, err
+	}
+	state, err := utils.CheckResumeState(dbConn, record.DatabaseName, record.TargetTableName, *record.PTJobID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if state.Resumable && !hasResumeOptions(record.GeneratedCommand) {
+		state.Resumable = false
+		state.Reason = "原命令未保留新表与触发器（未开启 resumable），续跑期间的写入无法同步"
+	}
+	return state, dbConn, nil
+}
+
+// dropResumeArtifacts 从头重试的任务开始时清理上次中断残留的触发器与新表
+// 记录已标记为执行中后调用；目标表上有其他执行进行中时不清理
+func (e *ExecutionEngine) dropResumeArtifacts(task *ExecutionTask) error {
+	record := task.Record
+	if task.Attempt == nil || task.Attempt.Mode != RetryModeRestart || record.PTJobID == nil {
+		return nil
+	}
+	state, dbConn, err := resumeStateOf(e.crypto, record)
+	if err != nil {
+		return fmt.Errorf("检查上次执行残留失败: %v", err)
+	}
+	if dbConn != nil && !state.Done && (len(state.Triggers) > 0 || state.NewTableFound) {
+		var running int64
+		if err := e.db.Model(&models.ExecutionRecord{}).
+			Where("connection_id = ? AND database_name = ? AND target_table_name = ? AND status = ? AND id <> ?",
+				record.ConnectionID, record.DatabaseName, record.TargetTableName, models.StatusRunning, record.ID).
+			Count(&running).Error; err != nil {
+			return err
+		}
+		if running > 0 {
+			return fmt.Errorf("目标表上有其他执行正在进行，无法清理上次执行残留")
+		}
+
+		dropped, err := utils.DropResumeArtifacts(dbConn, record.DatabaseName, record.TargetTableName, state)
+		if err != nil {
+			return fmt.Errorf("清理上次执行残留失败: %v", err)
+		}
+		e.logLine(task, fmt.Sprintf("[%s] 已清理上次执行残留：新表 %s，触发器 %v", time.Now().Format("15:04:05"), state.NewTable, dropped))
+	}
+	record.PTJobID = nil
+	return nil
+}
+
+// dropCompletedArtifacts 续跑模式（--no-drop-triggers）成功切换后删除旧表上的 pt-osc 触发器
+// 未要求保留旧表时一并删除旧表，否则交由旧表登记与过期清理处理
+func (e *ExecutionEngine) dropCompletedArtifacts(task *ExecutionTask, dbConn *utils.DatabaseConnection) error {
+	record := task.Record
+	if !hasResumeOptions(record.GeneratedCommand) {
+		return nil
+	}
+	oldTable := utils.ParseOldTableName(task.output, record.DatabaseName, record.TargetTableName)
+	if oldTable == "" {
+		oldTable = task.expectedOldTable
+	}
+	if oldTable == "" {
+		return fmt.Errorf("无法确定本次切换后的旧表名")
+	}
+	exists, err := utils.TableExists(dbConn, record.DatabaseName, oldTable)
+	if err != nil || !exists {
+		return err
+	}
+
+	dropped, err := utils.DropPTTriggers(dbConn, record.DatabaseName, oldTable)
+	if err != nil {
+		return err
+	}
+	if len(dropped) > 0 {
+		e.logLine(task, fmt.Sprintf("[%s] 已删除旧表 %s 上的触发器 %v", time.Now().Format("15:04:05"), oldTable, dropped))
+	}
+	if record.ExecutionParams != nil && record.ExecutionParams.KeepOldTable {
+		return nil
+	}
+	if err := utils.DropTable(dbConn, record.DatabaseName, oldTable); err != nil {
+		return err
+	}
+	e.logLine(task, fmt.Sprintf("[%s] 已删除旧表 %s", time.Now().Format("15:04:05"), oldTable))
+	return nil
+}
+
+// applyResumeOptions 按配置启用 --history，并按执行参数在失败时保留新表与触发器
+func (s *ExecutionService) applyResumeOptions(options *utils.PTOptions, params *models.ExecutionParams) {
+	options.History = s.cfg.PTHistory
+	options.Resumable = options.History && params != nil && params.Resumable
+}
+
+// hasResumeOptions 命令是否同时启用了历史记录与残留保留
+func hasResumeOptions(command string) bool {
+	for _, option := range []string{"--history", "--no-drop-new-table", "--no-drop-triggers"} {
+		if !strings.Contains(command, option) {
+			return false
+		}
+	}
+	return true
+}
@@ -3,6 +3,8 @@ package services
 import (
 	"errors"
 	"fmt"
+	"io"
+	"strings"
 	"time"
 
 	"github.com/fengzhencai/MySQLer/backend/internal/config"
@@ -18,6 +20,9 @@ type ExecutionService struct {
 	cfg               *config.Config
 	connectionService *ConnectionService
 	crypto            *utils.CryptoService
+	snapshots         *SnapshotService
+	lint              *LintService
+	logs              *ExecutionLogService
 }
 
 // NewExecutionService 创建执行服务
@@ -27,6 +32,9 @@ func NewExecutionService(db *gorm.DB, cfg *config.Config, connectionService *Con
 		cfg:               cfg,
 		connectionService: connectionService,
 		crypto:            utils.NewCryptoService(cfg.EncryptionKey),
+		snapshots:         NewSnapshotService(db),
+		lint:              NewLintService(db),
+		logs:              NewExecutionLogService(db, cfg),
 	}
 }
 
@@ -38,6 +46,10 @@ type CreateExecutionRequest struct {
 	DDLType         *models.DDLType         `json:"ddl_type" binding:"required"`
 	OriginalDDL     *string                 `json:"original_ddl" binding:"omitempty,max=2000"`
 	ExecutionParams *models.ExecutionParams `json:"execution_params"`
+	BatchID         *string                 `json:"-"` // 由批量执行创建时填充
+	ChangeID        *string                 `json:"-"` // 由变更发布创建时填充
+	MigrationID     *string                 `json:"-"` // 由迁移导入创建时填充
+	RevertOf        *string                 `json:"-"` // 由回滚创建时填充
 }
 
 // PreviewCommandRequest 预览命令请求
@@ -52,12 +64,16 @@ type PreviewCommandRequest struct {
 
 // PreviewCommandResponse 预览命令响应
 type PreviewCommandResponse struct {
-	Command              string                 `json:"command"`
-	RiskAnalysis         map[string]interface{} `json:"risk_analysis"`
-	TableInfo            *utils.TableInfo       `json:"table_info"`
-	EstimatedTime        string                 `json:"estimated_time"`
-	RecommendedChunkSize int                    `json:"recommended_chunk_size"`
-	NoCheckAlter         bool                   `json:"no_check_alter"`
+	Command              string                   `json:"command"`
+	RiskAnalysis         map[string]interface{}   `json:"risk_analysis"`
+	TableInfo            *utils.TableInfo         `json:"table_info"`
+	EstimatedTime        string                   `json:"estimated_time"`
+	RecommendedChunkSize int                      `json:"recommended_chunk_size"`
+	Estimate             *utils.ExecutionEstimate `json:"estimate"` // 基于历史执行的耗时区间与chunk-size依据
+	NoCheckAlter         bool                     `json:"no_check_alter"`
+	Rollback             *utils.RollbackPlan      `json:"rollback"`
+	LintViolations       []utils.LintViolation    `json:"lint_violations"`
+	LintBlocked          bool                     `json:"lint_blocked"` // 存在 error 级别违规，创建将被拒绝
 }
 
 // List 获取执行记录列表（分页与过滤）
@@ -78,6 +94,12 @@ func (s *ExecutionService) ListWithFilters(params map[string]interface{}) (recor
 	if v, ok := params["connection_id"].(string); ok && v != "" {
 		db = db.Where("connection_id = ?", v)
 	}
+	if v, ok := params["batch_id"].(string); ok && v != "" {
+		db = db.Where("batch_id = ?", v)
+	}
+	if v, ok := params["failure_category"].(string); ok && v != "" {
+		db = db.Where("failure_category = ?", v)
+	}
 	if v, ok := params["start_date"].(string); ok && v != "" {
 		db = db.Where("created_at >= ?", v)
 	}
@@ -121,25 +143,13 @@ func (s *ExecutionService) PreviewCommand(req *PreviewCommandRequest) (*PreviewC
 		return nil, err
 	}
 
-	// 2. 解密密码
-	password, err := s.crypto.Decrypt(connection.Password)
+	// 2. 解密密码并构建数据库连接配置
+	dbConn, err := buildDatabaseConnection(s.crypto, &connection, req.DatabaseName)
 	if err != nil {
-		return nil, fmt.Errorf("密码解密失败: %v", err)
-	}
-
-	// 3. 构建数据库连接配置
-	dbConn := &utils.DatabaseConnection{
-		Host:           connection.Host,
-		Port:           connection.Port,
-		Username:       connection.Username,
-		Password:       password,
-		DatabaseName:   req.DatabaseName,
-		ConnectTimeout: connection.ConnectTimeout,
-		Charset:        connection.Charset,
-		UseSSL:         connection.UseSSL,
+		return nil, err
 	}
 
-	// 4. 获取表信息
+	// 3. 获取表信息
 	tables, err := utils.GetTableList(dbConn, req.DatabaseName)
 	if err != nil {
 		return nil, fmt.Errorf("获取表信息失败: %v", err)
@@ -170,7 +180,7 @@ func (s *ExecutionService) PreviewCommand(req *PreviewCommandRequest) (*PreviewC
 		return nil, fmt.Errorf("表 %s 不存在", req.TableName)
 	}
 
-	// 5. 构建PT命令
+	// 4. 构建PT命令
 	builder := utils.NewPTCommandBuilder(dbConn, tableInfo)
 
 	// 设置执行参数
@@ -183,19 +193,21 @@ func (s *ExecutionService) PreviewCommand(req *PreviewCommandRequest) (*PreviewC
 			Execute:      true,
 			Print:        true,
 			Statistics:   true,
-			DropOldTable: true,
+			DropOldTable: !req.ExecutionParams.KeepOldTable,
 			NoCheckAlter: req.ExecutionParams.NoCheckAlter,
 		}
+		s.applyCutoverGuard(ptOptions, req.ExecutionParams)
 		// 将锁等待超时映射到 --set-vars
 		if req.ExecutionParams.LockWaitTimeout > 0 {
 			ptOptions.SetVars = fmt.Sprintf("lock_wait_timeout=%d", req.ExecutionParams.LockWaitTimeout)
 		}
 		builder.SetOptions(ptOptions)
 	}
+	s.applyResumeOptions(builder.Options, req.ExecutionParams)
 
 	var riskAnalysis map[string]interface{}
 
-	// 6. 根据DDL类型构建命令
+	// 5. 根据DDL类型构建命令
 	var command string
 	switch req.DDLType {
 	case "fragment":
@@ -215,10 +227,15 @@ func (s *ExecutionService) PreviewCommand(req *PreviewCommandRequest) (*PreviewC
 		return nil, fmt.Errorf("不支持的DDL类型: %s", req.DDLType)
 	}
 
-	// 7. 风险分析
+	// 6. 风险分析（索引变更附带索引分析结论）
 	riskAnalysis = builder.AnalyzeDDLRisk()
+	if req.DDLType == "custom" {
+		s.attachIndexAnalysis(riskAnalysis, dbConn, req.DatabaseName, req.TableName, *req.OriginalDDL)
+	}
+	s.attachLoadCheck(riskAnalysis, dbConn, req.ExecutionParams)
+	s.attachTransactionGuard(riskAnalysis, dbConn, req.DatabaseName, req.TableName, req.ExecutionParams)
 
-	// 8. 预览命令（隐藏密码）
+	// 7. 预览命令（隐藏密码）
 	previewCommand, err := builder.PreviewCommand()
 	if err != nil {
 		return nil, fmt.Errorf("生成预览命令失败: %v", err)
@@ -229,16 +246,27 @@ func (s *ExecutionService) PreviewCommand(req *PreviewCommandRequest) (*PreviewC
 		return nil, fmt.Errorf("生成的命令为空")
 	}
 
-	// 9. 获取推荐的chunk-size
-	recommendedChunkSize := builder.GetRecommendedChunkSize()
+	// 8. 基于该连接的历史执行估算耗时并推荐chunk-size（无历史时沿用默认规则）
+	estimate := s.estimateFromHistory(connection.ID, tableInfo.Rows, builder.GetRecommendedChunkSize())
+	riskAnalysis["estimated_time"] = estimate.EstimatedTime
+
+	// 9. SQL审核
+	violations, err := s.lint.Lint(&connection, dbConn, req.DatabaseName, req.TableName, req.DDLType, req.OriginalDDL)
+	if err != nil {
+		return nil, fmt.Errorf("SQL审核失败: %v", err)
+	}
 
 	return &PreviewCommandResponse{
 		Command:              previewCommand,
 		RiskAnalysis:         riskAnalysis,
 		TableInfo:            tableInfo,
 		EstimatedTime:        riskAnalysis["estimated_time"].(string),
-		RecommendedChunkSize: recommendedChunkSize,
+		RecommendedChunkSize: estimate.RecommendedChunkSize,
+		Estimate:             estimate,
 		NoCheckAlter:         req.ExecutionParams != nil && req.ExecutionParams.NoCheckAlter,
+		Rollback:             s.buildRollbackPlan(dbConn, req.DatabaseName, req.TableName, req.DDLType, req.OriginalDDL),
+		LintViolations:       violations,
+		LintBlocked:          utils.HasLintErrors(violations),
 	}, nil
 }
 
@@ -254,25 +282,13 @@ func (s *ExecutionService) Create(req *CreateExecutionRequest, userID string) (*
 		return nil, err
 	}
 
-	// 2. 解密密码
-	password, err := s.crypto.Decrypt(connection.Password)
+	// 2. 解密密码并构建数据库连接配置
+	dbConn, err := buildDatabaseConnection(s.crypto, &connection, req.DatabaseName)
 	if err != nil {
-		return nil, fmt.Errorf("密码解密失败: %v", err)
-	}
-
-	// 3. 构建数据库连接配置
-	dbConn := &utils.DatabaseConnection{
-		Host:           connection.Host,
-		Port:           connection.Port,
-		Username:       connection.Username,
-		Password:       password,
-		DatabaseName:   req.DatabaseName,
-		ConnectTimeout: connection.ConnectTimeout,
-		Charset:        connection.Charset,
-		UseSSL:         connection.UseSSL,
+		return nil, err
 	}
 
-	// 4. 获取表信息
+	// 3. 获取表信息
 	tables, err := utils.GetTableList(dbConn, req.DatabaseName)
 	if err != nil {
 		return nil, fmt.Errorf("获取表信息失败: %v", err)
@@ -303,7 +319,7 @@ func (s *ExecutionService) Create(req *CreateExecutionRequest, userID string) (*
 		return nil, fmt.Errorf("表 %s 不存在", req.TableName)
 	}
 
-	// 5. 构建PT命令
+	// 4. 构建PT命令
 	builder := utils.NewPTCommandBuilder(dbConn, tableInfo)
 
 	// 设置执行参数
@@ -316,18 +332,20 @@ func (s *ExecutionService) Create(req *CreateExecutionRequest, userID string) (*
 			Execute:      true,
 			Print:        true,
 			Statistics:   true,
-			DropOldTable: true,
+			DropOldTable: !req.ExecutionParams.KeepOldTable,
 			NoCheckAlter: req.ExecutionParams.NoCheckAlter,
 		}
+		s.applyCutoverGuard(ptOptions, req.ExecutionParams)
 		if req.ExecutionParams.LockWaitTimeout > 0 {
 			ptOptions.SetVars = fmt.Sprintf("lock_wait_timeout=%d", req.ExecutionParams.LockWaitTimeout)
 		}
 		builder.SetOptions(ptOptions)
 	}
+	s.applyResumeOptions(builder.Options, req.ExecutionParams)
 
 	var command string
 
-	// 6. 根据DDL类型构建命令
+	// 5. 根据DDL类型构建命令
 	if req.DDLType != nil {
 		switch *req.DDLType {
 		case models.DDLFragment:
@@ -346,6 +364,17 @@ func (s *ExecutionService) Create(req *CreateExecutionRequest, userID string) (*
 		return nil, fmt.Errorf("构建PT命令失败: %v", err)
 	}
 
+	// 6. SQL审核（回滚执行用于恢复原结构，不做拦截）
+	if req.RevertOf == nil {
+		violations, err := s.lint.Lint(&connection, dbConn, req.DatabaseName, req.TableName, string(*req.DDLType), req.OriginalDDL)
+		if err != nil {
+			return nil, fmt.Errorf("SQL审核失败: %v", err)
+		}
+		if utils.HasLintErrors(violations) {
+			return nil, lintError(violations)
+		}
+	}
+
 	// 7. 创建执行记录
 	record := &models.ExecutionRecord{
 		ID:               uuid.New().String(),
@@ -358,9 +387,21 @@ func (s *ExecutionService) Create(req *CreateExecutionRequest, userID string) (*
 		ExecutionParams:  req.ExecutionParams,
 		Status:           models.StatusPending,
 		TotalRows:        tableInfo.Rows,
+		BatchID:          req.BatchID,
+		ChangeID:         req.ChangeID,
+		MigrationID:      req.MigrationID,
+		RevertOf:         req.RevertOf,
 		CreatedBy:        userID,
 	}
 
+	// 生成回滚语句（失败不影响创建）
+	if plan := s.buildRollbackPlan(dbConn, req.DatabaseName, req.TableName, string(*req.DDLType), req.OriginalDDL); plan != nil {
+		record.Revertible = plan.Revertible
+		if plan.RollbackDDL != "" {
+			record.RollbackDDL = &plan.RollbackDDL
+		}
+	}
+
 	// 设置默认执行参数
 	if record.ExecutionParams == nil {
 		record.ExecutionParams = &models.ExecutionParams{
@@ -375,6 +416,7 @@ func (s *ExecutionService) Create(req *CreateExecutionRequest, userID string) (*
 	if err := s.db.Create(record).Error; err != nil {
 		return nil, fmt.Errorf("创建执行记录失败: %v", err)
 	}
+	recordExecutionEvent(s.db, record, "", userID, "创建执行")
 
 	return record, nil
 }
@@ -386,8 +428,8 @@ func (s *ExecutionService) GetByID(id string) (*models.ExecutionRecord, error) {
 	return &record, err
 }
 
-// Stop 停止执行
-func (s *ExecutionService) Stop(id string) error {
+// Stop 停止执行（operator 为操作人ID）
+func (s *ExecutionService) Stop(id string, operator string) error {
 	// 调用执行引擎停止任务需通过引擎，服务层更新状态作为兜底
 	// 这里仅更新记录状态为cancelled（如果仍处于running）
 	var record models.ExecutionRecord
@@ -400,40 +442,426 @@ func (s *ExecutionService) Stop(id string) error {
 		record.EndTime = &now
 		duration := int(now.Sub(*record.StartTime).Seconds())
 		record.DurationSeconds = &duration
-		return s.db.Save(&record).Error
+		if err := s.db.Save(&record).Error; err != nil {
+			return err
+		}
+		recordExecutionEvent(s.db, &record, models.StatusRunning, operator, "手动停止")
+		return nil
+	}
+	// 失败后等待自动重试的任务：取消重试计划
+	if record.Status == models.StatusFailed && record.NextRetryAt != nil {
+		return cancelScheduledRetry(s.db, &record)
 	}
 	return fmt.Errorf("当前状态无法停止: %s", record.Status)
 }
 
-// Retry 重试执行
-func (s *ExecutionService) Retry(id string, userID string) error {
-	// 将记录状态重置为pending，并清理运行态字段
+// FailureCategories 失败分类及处理建议
+func (s *ExecutionService) FailureCategories() []utils.FailureClassification {
+	return utils.FailureCategories()
+}
+
+// GetLogs 分页获取执行日志（支持按执行次数与正则过滤）
+func (s *ExecutionService) GetLogs(id string, query *LogQuery) (*LogPage, error) {
+	return s.logs.Query(id, query)
+}
+
+// DownloadLogs 以纯文本写出执行日志
+func (s *ExecutionService) DownloadLogs(id string, query *LogQuery, w io.Writer) error {
+	return s.logs.Download(id, query, w)
+}
+
+// buildRollbackPlan 基于当前表结构生成回滚方案，无法生成时返回 nil
+func (s *ExecutionService) buildRollbackPlan(dbConn *utils.DatabaseConnection, database, table, ddlType string, originalDDL *string) *utils.RollbackPlan {
+	if ddlType == string(models.DDLFragment) || originalDDL == nil || *originalDDL == "" {
+		return nil
+	}
+
+	createSQL, err := utils.GetCreateTable(dbConn, database, table)
+	if err != nil {
+		return nil
+	}
+	definition, err := utils.ParseCreateTable(createSQL)
+	if err != nil {
+		return nil
+	}
+	plan, err := utils.GenerateRollbackDDL(definition, table, *originalDDL)
+	if err != nil {
+		return nil
+	}
+	return plan
+}
+
+// revertDDLType 回滚执行对应的DDL类型
+func revertDDLType(ddlType *models.DDLType) models.DDLType {
+	if ddlType == nil {
+		return models.DDLOther
+	}
+	switch *ddlType {
+	case models.DDLAddColumn:
+		return models.DDLDropColumn
+	case models.DDLAddIndex:
+		return models.DDLDropIndex
+	case models.DDLDropIndex:
+		return models.DDLAddIndex
+	case models.DDLModifyColumn:
+		return models.DDLModifyColumn
+	default:
+		return models.DDLOther
+	}
+}
+
+// Revert 为已完成的执行创建回滚执行（新的执行记录，通过 revert_of 关联原执行）
+func (s *ExecutionService) Revert(id string, userID string) (*models.ExecutionRecord, error) {
 	var record models.ExecutionRecord
 	if err := s.db.First(&record, "id = ?", id).Error; err != nil {
-		return err
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("执行记录不存在")
+		}
+		return nil, err
+	}
+	if !record.Status.Succeeded() {
+		return nil, fmt.Errorf("仅已完成的任务允许回滚")
+	}
+	if !record.Revertible || record.RollbackDDL == nil {
+		return nil, fmt.Errorf("该变更不支持自动回滚")
+	}
+
+	// 同一执行只允许存在一个有效的回滚
+	var existing int64
+	s.db.Model(&models.ExecutionRecord{}).
+		Where("revert_of = ? AND status IN ?", id, []models.ExecutionStatus{models.StatusPending, models.StatusRunning, models.StatusCompleted, models.StatusCompletedWithWarnings}).
+		Count(&existing)
+	if existing > 0 {
+		return nil, fmt.Errorf("该执行已存在回滚任务")
 	}
-	if record.Status != models.StatusFailed && record.Status != models.StatusCancelled {
-		return fmt.Errorf("仅失败或已取消的任务允许重试")
+
+	// 优先使用执行前快照重新生成回滚语句，保证与变更前结构一致
+	rollbackDDL := *record.RollbackDDL
+	if snapshot, err := s.snapshots.GetSnapshot(id, models.SnapshotBefore); err == nil && record.OriginalDDL != nil {
+		if definition, err := utils.ParseCreateTable(snapshot.CreateTableSQL); err == nil {
+			if plan, err := utils.GenerateRollbackDDL(definition, record.TargetTableName, *record.OriginalDDL); err == nil {
+				if !plan.Revertible {
+					return nil, fmt.Errorf("该变更不支持自动回滚: %s", strings.Join(plan.Irreversible, "; "))
+				}
+				rollbackDDL = plan.RollbackDDL
+			}
+		}
 	}
-	record.Status = models.StatusPending
-	record.StartTime = nil
-	record.EndTime = nil
-	record.DurationSeconds = nil
-	record.ErrorMessage = nil
-	return s.db.Save(&record).Error
+
+	ddlType := revertDDLType(record.DDLType)
+	return s.Create(&CreateExecutionRequest{
+		ConnectionID:    record.ConnectionID,
+		TableName:       record.TargetTableName,
+		DatabaseName:    record.DatabaseName,
+		DDLType:         &ddlType,
+		OriginalDDL:     &rollbackDDL,
+		ExecutionParams: record.ExecutionParams,
+		RevertOf:        &record.ID,
+	}, userID)
 }
 
-// GetLogs 获取执行日志
-func (s *ExecutionService) GetLogs(id string) (string, error) {
-	var record models.ExecutionRecord
-	err := s.db.Select("execution_logs").First(&record, "id = ?", id).Error
+// DeclarativeRequest 声明式变更请求：提交目标建表语句，自动生成 ALTER
+type DeclarativeRequest struct {
+	ConnectionID    string                  `json:"connection_id" binding:"required,uuid4"`
+	TableName       string                  `json:"table_name" binding:"required,min=1,max=200"`
+	DatabaseName    string                  `json:"database_name" binding:"required,min=1,max=100"`
+	TargetSQL       string                  `json:"target_sql" binding:"required"`                        // 目标 CREATE TABLE
+	Action          string                  `json:"action" binding:"omitempty,oneof=diff preview create"` // 默认 diff
+	ExecutionParams *models.ExecutionParams `json:"execution_params"`
+}
+
+// DeclarativeResponse 声明式变更结果
+type DeclarativeResponse struct {
+	Diff      *utils.TableDiff        `json:"diff"`
+	DDLType   models.DDLType          `json:"ddl_type"`
+	Preview   *PreviewCommandResponse `json:"preview,omitempty"`
+	Execution *models.ExecutionRecord `json:"execution,omitempty"`
+}
+
+// Declarative 比较目标建表语句与线上结构，生成最小 ALTER，并可直接预览或创建执行
+func (s *ExecutionService) Declarative(req *DeclarativeRequest, userID string) (*DeclarativeResponse, error) {
+	target, err := utils.ParseCreateTable(req.TargetSQL)
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("解析目标建表语句失败: %v", err)
 	}
 
-	if record.ExecutionLogs != nil {
-		return *record.ExecutionLogs, nil
+	var connection models.Connection
+	if err := s.db.First(&connection, "id = ?", req.ConnectionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("连接不存在")
+		}
+		return nil, err
+	}
+	dbConn, err := buildDatabaseConnection(s.crypto, &connection, req.DatabaseName)
+	if err != nil {
+		return nil, err
 	}
 
-	return "", nil
+	exists, err := utils.TableExists(dbConn, req.DatabaseName, req.TableName)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("表 %s 不存在，声明式变更仅支持已有表", req.TableName)
+	}
+	createSQL, err := utils.GetCreateTable(dbConn, req.DatabaseName, req.TableName)
+	if err != nil {
+		return nil, err
+	}
+	current, err := utils.ParseCreateTable(createSQL)
+	if err != nil {
+		return nil, fmt.Errorf("解析线上表结构失败: %v", err)
+	}
+
+	diff := utils.DiffTableDefinitions(current, target, req.TableName)
+	if !strings.EqualFold(target.Name, req.TableName) {
+		diff.Warnings = append(diff.Warnings, fmt.Sprintf("目标建表语句中的表名 %s 与 %s 不一致，已按 %s 比较", target.Name, req.TableName, req.TableName))
+	}
+
+	response := &DeclarativeResponse{Diff: diff, DDLType: models.DDLOther}
+	if diff.AlterSQL == "" {
+		return response, nil
+	}
+	if cleaned, err := utils.CleanAlterSQL(diff.AlterSQL); err == nil {
+		response.DDLType = inferDDLType(utils.ParseAlterClauses(cleaned))
+	}
+
+	switch req.Action {
+	case "preview":
+		response.Preview, err = s.PreviewCommand(&PreviewCommandRequest{
+			ConnectionID:    req.ConnectionID,
+			TableName:       req.TableName,
+			DatabaseName:    req.DatabaseName,
+			DDLType:         "custom",
+			OriginalDDL:     &diff.AlterSQL,
+			ExecutionParams: req.ExecutionParams,
+		})
+	case "create":
+		ddlType := response.DDLType
+		response.Execution, err = s.Create(&CreateExecutionRequest{
+			ConnectionID:    req.ConnectionID,
+			TableName:       req.TableName,
+			DatabaseName:    req.DatabaseName,
+			DDLType:         &ddlType,
+			OriginalDDL:     &diff.AlterSQL,
+			ExecutionParams: req.ExecutionParams,
+		}, userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// inferDDLType 根据 ALTER 子句推断执行记录的DDL类型（多种操作混合时为 other）
+func inferDDLType(clauses []*utils.AlterClause) models.DDLType {
+	kinds := map[string]models.DDLType{
+		utils.AlterAddColumn:    models.DDLAddColumn,
+		utils.AlterDropColumn:   models.DDLDropColumn,
+		utils.AlterModifyColumn: models.DDLModifyColumn,
+		utils.AlterChangeColumn: models.DDLModifyColumn,
+		utils.AlterAddIndex:     models.DDLAddIndex,
+		utils.AlterDropIndex:    models.DDLDropIndex,
+	}
+
+	var result models.DDLType
+	for _, clause := range clauses {
+		ddlType, ok := kinds[clause.Kind]
+		if !ok || (result != "" && result != ddlType) {
+			return models.DDLOther
+		}
+		result = ddlType
+	}
+	if result == "" {
+		return models.DDLOther
+	}
+	return result
+}
+
+// attachIndexAnalysis 为新增/删除索引的变更附加索引分析结论与相应提示
+func (s *ExecutionService) attachIndexAnalysis(risk map[string]interface{}, dbConn *utils.DatabaseConnection, database, table, originalDDL string) {
+	cleaned, err := utils.CleanAlterSQL(originalDDL)
+	if err != nil {
+		return
+	}
+	var indexClauses []*utils.AlterClause
+	for _, clause := range utils.ParseAlterClauses(cleaned) {
+		if clause.Kind == utils.AlterAddIndex || clause.Kind == utils.AlterDropIndex {
+			indexClauses = append(indexClauses, clause)
+		}
+	}
+	if len(indexClauses) == 0 {
+		return
+	}
+
+	warnings, _ := risk["warnings"].([]string)
+	suggestions, _ := risk["suggestions"].([]string)
+	defer func() {
+		risk["warnings"] = warnings
+		risk["suggestions"] = suggestions
+	}()
+
+	analysis, err := utils.AnalyzeIndexes(dbConn, database, table)
+	if err != nil {
+		warnings = append(warnings, fmt.Sprintf("索引分析失败: %v", err))
+		return
+	}
+	risk["index_analysis"] = analysis
+
+	stats := make(map[string]*utils.IndexStat, len(analysis.Indexes))
+	for _, idx := range analysis.Indexes {
+		stats[strings.ToLower(idx.Name)] = idx
+	}
+	redundant := make(map[string]utils.IndexFinding)
+	for _, finding := range analysis.Findings {
+		if finding.Kind != utils.IndexFindingUnused {
+			redundant[strings.ToLower(finding.Index)] = finding
+		}
+	}
+
+	for _, clause := range indexClauses {
+		switch clause.Kind {
+		case utils.AlterDropIndex:
+			idx, ok := stats[strings.ToLower(clause.Name)]
+			if !ok {
+				continue
+			}
+			if finding, ok := redundant[strings.ToLower(clause.Name)]; ok {
+				suggestions = append(suggestions, fmt.Sprintf("%s，删除后查询可由 %s 覆盖", finding.Message, finding.CoveredBy))
+			} else if idx.Reads != nil && *idx.Reads > 0 {
+				warnings = append(warnings, fmt.Sprintf("索引 %s 自实例启动以来被读取 %d 次，且无其他索引覆盖，删除可能影响查询性能", idx.Name, *idx.Reads))
+				if risk["level"] == "low" {
+					risk["level"] = "medium"
+				}
+			} else if idx.Unused != nil && *idx.Unused {
+				suggestions = append(suggestions, fmt.Sprintf("索引 %s 自实例启动以来未被使用", idx.Name))
+			}
+			if idx.Unique {
+				warnings = append(warnings, fmt.Sprintf("索引 %s 为唯一索引，删除后将不再保证数据唯一性", idx.Name))
+			}
+
+		case utils.AlterAddIndex:
+			columns := utils.IndexDefinitionColumns(clause.Definition)
+			if len(columns) == 0 {
+				continue
+			}
+			if covered := utils.IndexCoveredBy(analysis.Indexes, columns); covered != "" && !strings.HasPrefix(strings.ToUpper(strings.TrimSpace(clause.Definition)), "UNIQUE") {
+				warnings = append(warnings, fmt.Sprintf("新增索引 (%s) 已被现有索引 %s 覆盖，属于冗余索引", strings.Join(columns, ", "), covered))
+			}
+			for _, idx := range utils.IndexesMadeRedundant(analysis.Indexes, columns) {
+				suggestions = append(suggestions, fmt.Sprintf("新增索引后，现有索引 %s (%s) 将成为冗余索引，可考虑删除", idx.Name, strings.Join(idx.Columns, ", ")))
+			}
+		}
+	}
+}
+
+// historySampleLimit 估算时参考的最近已完成执行数
+const historySampleLimit = 50
+
+// estimateFromHistory 以该连接最近完成的执行为样本估算耗时与chunk-size
+func (s *ExecutionService) estimateFromHistory(connectionID string, rows int64, fallbackChunkSize int) *utils.ExecutionEstimate {
+	var records []models.ExecutionRecord
+	s.db.Select("id", "total_rows", "processed_rows", "duration_seconds", "execution_params").
+		Where("connection_id = ? AND status IN ? AND duration_seconds > 0 AND total_rows > 0", connectionID,
+			[]models.ExecutionStatus{models.StatusCompleted, models.StatusCompletedWithWarnings}).
+		Order("end_time DESC").Limit(historySampleLimit).Find(&records)
+
+	samples := make([]utils.ThroughputSample, 0, len(records))
+	for _, record := range records {
+		sample := utils.ThroughputSample{
+			Rows:    record.TotalRows,
+			Seconds: *record.DurationSeconds,
+		}
+		if record.ProcessedRows > 0 {
+			sample.Rows = record.ProcessedRows
+		}
+		if record.ExecutionParams != nil {
+			sample.ChunkSize = record.ExecutionParams.ChunkSize
+		}
+		samples = append(samples, sample)
+	}
+
+	return utils.EstimateExecution(rows, samples, fallbackChunkSize)
+}
+
+// attachLoadCheck 采样实例当前负载，接近或超过 max-load/critical-load 时附加提示
+func (s *ExecutionService) attachLoadCheck(risk map[string]interface{}, dbConn *utils.DatabaseConnection, params *models.ExecutionParams) {
+	maxLoad, criticalLoad := LoadThresholds(params, s.cfg)
+	load, err := utils.SampleServerLoad(dbConn, utils.ThresholdVariables(maxLoad, criticalLoad))
+	if err != nil {
+		return
+	}
+
+	loadWarnings := utils.CheckLoadThresholds(load, maxLoad, criticalLoad)
+	risk["server_load"] = &ServerLoadStatus{
+		Current:      load,
+		MaxLoad:      maxLoad,
+		CriticalLoad: criticalLoad,
+		Warnings:     loadWarnings,
+	}
+	if len(loadWarnings) == 0 {
+		return
+	}
+
+	warnings, _ := risk["warnings"].([]string)
+	for _, warning := range loadWarnings {
+		warnings = append(warnings, warning.Message+"，pt-osc 将频繁暂停或中止，建议在负载较低时执行")
+		if warning.Exceeded && warning.Kind == "critical_load" {
+			risk["level"] = "high"
+		} else if risk["level"] == "low" {
+			risk["level"] = "medium"
+		}
+	}
+	risk["warnings"] = warnings
+}
+
+// attachTransactionGuard 检查目标表上的长事务，存在时附加提示（切换需获取元数据锁，可能被长事务阻塞）
+func (s *ExecutionService) attachTransactionGuard(risk map[string]interface{}, dbConn *utils.DatabaseConnection, database, table string, params *models.ExecutionParams) {
+	report, err := utils.CheckTransactionGuard(dbConn, database, table, longTrxSeconds(params, s.cfg))
+	if err != nil {
+		return
+	}
+
+	risk["transaction_guard"] = report
+	if !report.Blocked() {
+		return
+	}
+
+	warnings, _ := risk["warnings"].([]string)
+	for _, trx := range report.Transactions {
+		warnings = append(warnings, describeTransaction(trx))
+	}
+	if params == nil || !params.HoldCutover {
+		warnings = append(warnings, "建议开启切换保护（hold_cutover），等待长事务结束后再切换")
+	}
+	risk["warnings"] = warnings
+	if risk["level"] == "low" {
+		risk["level"] = "medium"
+	}
+}
+
+// applyCutoverGuard 将切换保护参数写入pt选项（未设置的取值使用默认配置）
+func (s *ExecutionService) applyCutoverGuard(options *utils.PTOptions, params *models.ExecutionParams) {
+	if !params.HoldCutover {
+		return
+	}
+	options.CutoverGuard = true
+	options.LongTrxSeconds = longTrxSeconds(params, s.cfg)
+	options.CutoverTimeout = params.CutoverTimeout
+	if options.CutoverTimeout <= 0 {
+		options.CutoverTimeout = int(s.cfg.CutoverWaitTimeout.Seconds())
+	}
+	options.CutoverOnTimeout = utils.CutoverTimeoutProceed
+	if params.CutoverOnTimeout == utils.CutoverTimeoutAbort {
+		options.CutoverOnTimeout = utils.CutoverTimeoutAbort
+	}
+}
+
+// longTrxSeconds 长事务判定秒数（执行参数优先）
+func longTrxSeconds(params *models.ExecutionParams, cfg *config.Config) int {
+	if params != nil && params.LongTrxSeconds > 0 {
+		return params.LongTrxSeconds
+	}
+	return int(cfg.LongTransactionThreshold.Seconds())
 }
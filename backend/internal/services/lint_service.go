@@ -0,0 +1,151 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fengzhencai/MySQLer/backend/internal/models"
+	"github.com/fengzhencai/MySQLer/backend/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LintService SQL审核规则服务
+type LintService struct {
+	db *gorm.DB
+}
+
+// NewLintService 创建SQL审核服务
+func NewLintService(db *gorm.DB) *LintService {
+	return &LintService{db: db}
+}
+
+// LintRuleUpdate 单条规则配置更新
+type LintRuleUpdate struct {
+	ID        string `json:"id" binding:"required"`
+	Enabled   *bool  `json:"enabled"`
+	Severity  string `json:"severity" binding:"omitempty,oneof=error warn"`
+	Threshold *int   `json:"threshold" binding:"omitempty,min=0"`
+}
+
+// UpdateLintRulesRequest 批量更新规则配置请求
+type UpdateLintRulesRequest struct {
+	Rules []LintRuleUpdate `json:"rules" binding:"required,min=1,dive"`
+}
+
+// GetRules 获取环境下生效的规则（内置默认值叠加环境配置）
+func (s *LintService) GetRules(env models.Environment) ([]utils.LintRule, error) {
+	if err := validateEnvironment(env); err != nil {
+		return nil, err
+	}
+
+	var settings []models.LintRuleSetting
+	if err := s.db.Where("environment = ?", env).Find(&settings).Error; err != nil {
+		return nil, err
+	}
+	overrides := make(map[string]models.LintRuleSetting, len(settings))
+	for _, setting := range settings {
+		overrides[setting.RuleID] = setting
+	}
+
+	rules := utils.DefaultLintRules()
+	for i := range rules {
+		if setting, ok := overrides[rules[i].ID]; ok {
+			rules[i].Enabled = setting.Enabled
+			rules[i].Severity = setting.Severity
+			if setting.Threshold > 0 {
+				rules[i].Threshold = setting.Threshold
+			}
+		}
+	}
+	return rules, nil
+}
+
+// UpdateRules 更新环境下的规则配置
+func (s *LintService) UpdateRules(env models.Environment, req *UpdateLintRulesRequest, userID string) ([]utils.LintRule, error) {
+	rules, err := s.GetRules(env)
+	if err != nil {
+		return nil, err
+	}
+	current := make(map[string]utils.LintRule, len(rules))
+	for _, rule := range rules {
+		current[rule.ID] = rule
+	}
+
+	for _, update := range req.Rules {
+		rule, ok := current[update.ID]
+		if !ok {
+			return nil, fmt.Errorf("未知的审核规则: %s", update.ID)
+		}
+		if update.Enabled != nil {
+			rule.Enabled = *update.Enabled
+		}
+		if update.Severity != "" {
+			rule.Severity = update.Severity
+		}
+		if update.Threshold != nil {
+			rule.Threshold = *update.Threshold
+		}
+
+		setting := models.LintRuleSetting{
+			Environment: env,
+			RuleID:      rule.ID,
+			Enabled:     rule.Enabled,
+			Severity:    rule.Severity,
+			Threshold:   rule.Threshold,
+			UpdatedBy:   userID,
+		}
+		err := s.db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "environment"}, {Name: "rule_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"enabled", "severity", "threshold", "updated_by", "updated_at"}),
+		}).Create(&setting).Error
+		if err != nil {
+			return nil, fmt.Errorf("保存规则 %s 失败: %v", rule.ID, err)
+		}
+	}
+
+	return s.GetRules(env)
+}
+
+// Lint 按连接所属环境的规则审核DDL；碎片整理仅检查表本身
+func (s *LintService) Lint(connection *models.Connection, dbConn *utils.DatabaseConnection, database, table, ddlType string, originalDDL *string) ([]utils.LintViolation, error) {
+	rules, err := s.GetRules(connection.Environment)
+	if err != nil {
+		return nil, err
+	}
+
+	createSQL, err := utils.GetCreateTable(dbConn, database, table)
+	if err != nil {
+		return nil, fmt.Errorf("获取表结构失败: %v", err)
+	}
+	definition, err := utils.ParseCreateTable(createSQL)
+	if err != nil {
+		return nil, err
+	}
+
+	alterSQL := ""
+	if ddlType != string(models.DDLFragment) && originalDDL != nil {
+		alterSQL = *originalDDL
+	}
+	return utils.LintAlter(definition, alterSQL, rules)
+}
+
+// lintError 汇总 error 级别违规为错误信息
+func lintError(violations []utils.LintViolation) error {
+	var messages []string
+	for _, v := range violations {
+		if v.Severity == utils.LintSeverityError {
+			messages = append(messages, v.Message)
+		}
+	}
+	return fmt.Errorf("SQL审核未通过: %s", strings.Join(messages, "; "))
+}
+
+// validateEnvironment 校验环境取值
+func validateEnvironment(env models.Environment) error {
+	switch env {
+	case models.EnvProduction, models.EnvTest, models.EnvDevelopment:
+		return nil
+	}
+	return fmt.Errorf("无效的环境: %s", env)
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fengzhencai/MySQLer/backend/internal/utils"
+)
+
+// ServerLoadStatus 执行期间目标实例的最新负载及其对照阈值
+type ServerLoadStatus struct {
+	Current      *utils.ServerLoad     `json:"current"`
+	MaxLoad      []utils.LoadThreshold `json:"max_load"`
+	CriticalLoad []utils.LoadThreshold `json:"critical_load"`
+	Warnings     []utils.LoadWarning   `json:"warnings"`
+}
+
+// checkServerLoad 执行前检查实例负载是否已接近 max-load / critical-load，仅输出提示
+func (e *ExecutionEngine) checkServerLoad(task *ExecutionTask, dbConn *utils.DatabaseConnection) {
+	maxLoad, criticalLoad := LoadThresholds(task.Record.ExecutionParams, e.cfg)
+	load, err := utils.SampleServerLoad(dbConn, utils.ThresholdVariables(maxLoad, criticalLoad))
+	if err != nil {
+		e.logLine(task, fmt.Sprintf("[%s] 实例负载检查失败: %v", time.Now().Format("15:04:05"), err))
+		return
+	}
+	for _, warning := range utils.CheckLoadThresholds(load, maxLoad, criticalLoad) {
+		e.logLine(task, fmt.Sprintf("[%s] 负载预警: %s", time.Now().Format("15:04:05"), warning.Message))
+	}
+}
+
+// monitorServerLoad 在独立连接上按间隔采集目标实例负载，直到 stopCh 关闭
+func (e *ExecutionEngine) monitorServerLoad(task *ExecutionTask, dbConn *utils.DatabaseConnection, stopCh <-chan struct{}) {
+	if e.cfg.LoadSampleInterval <= 0 {
+		return
+	}
+
+	maxLoad, criticalLoad := LoadThresholds(task.Record.ExecutionParams, e.cfg)
+	monitor, err := utils.NewLoadMonitor(dbConn, utils.ThresholdVariables(maxLoad, criticalLoad))
+	if err != nil {
+		e.logLine(task, fmt.Sprintf("[%s] 负载监控连接失败: %v", time.Now().Format("15:04:05"), err))
+		return
+	}
+	defer monitor.Close()
+
+	ticker := time.NewTicker(e.cfg.LoadSampleInterval)
+	defer ticker.Stop()
+
+	var sampleFailed bool
+	active := map[string]bool{}
+	blocking := map[int64]bool{}
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-task.Context.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// 长事务检查与负载采样共用监控连接，结果随下方广播下发
+		blocking = e.updateTransactionGuard(task, monitor, blocking)
+
+		load, err := monitor.Sample(task.Context)
+		if err != nil {
+			// 连续失败只记录一次
+			if !sampleFailed {
+				e.logLine(task, fmt.Sprintf("[%s] 负载采样失败: %v", time.Now().Format("15:04:05"), err))
+			}
+			sampleFailed = true
+			continue
+		}
+		sampleFailed = false
+
+		if err := e.progress.RecordLoad(task.ID, load); err != nil {
+			fmt.Printf("记录负载采样失败: %v\n", err)
+		}
+
+		warnings := utils.CheckLoadThresholds(load, maxLoad, criticalLoad)
+		task.mutex.Lock()
+		task.ServerLoad = &ServerLoadStatus{
+			Current:      load,
+			MaxLoad:      maxLoad,
+			CriticalLoad: criticalLoad,
+			Warnings:     warnings,
+		}
+		e.broadcastProgress(task)
+		task.mutex.Unlock()
+
+		// 新出现的阈值预警写入日志
+		current := map[string]bool{}
+		for _, warning := range warnings {
+			key := fmt.Sprintf("%s/%s/%t", warning.Kind, warning.Variable, warning.Exceeded)
+			current[key] = true
+			if !active[key] {
+				e.logLine(task, fmt.Sprintf("[%s] 负载预警: %s", time.Now().Format("15:04:05"), warning.Message))
+			}
+		}
+		active = current
+	}
+}
+
+// 切换保护插件对应的执行阶段
+const (
+	stageCutoverWaiting = "等待长事务结束后切换"
+	stageCutoverSwap    = "切换新旧表"
+)
+
+// checkTransactions 执行前检查目标表上的长事务，仅输出提示并记录到任务状态
+func (e *ExecutionEngine) checkTransactions(task *ExecutionTask, dbConn *utils.DatabaseConnection) {
+	report, err := utils.CheckTransactionGuard(dbConn, task.Record.DatabaseName, task.Record.TargetTableName,
+		longTrxSeconds(task.Record.ExecutionParams, e.cfg))
+	if err != nil {
+		e.logLine(task, fmt.Sprintf("[%s] 长事务检查失败: %v", time.Now().Format("15:04:05"), err))
+		return
+	}
+
+	task.mutex.Lock()
+	task.TransactionGuard = report
+	task.mutex.Unlock()
+
+	for _, trx := range report.Transactions {
+		e.logLine(task, fmt.Sprintf("[%s] 长事务预警: %s", time.Now().Format("15:04:05"), describeTransaction(trx)))
+	}
+}
+
+// updateTransactionGuard 复用负载监控连接刷新长事务检查结果，阻塞会话变化时输出日志
+func (e *ExecutionEngine) updateTransactionGuard(task *ExecutionTask, monitor *utils.LoadMonitor, known map[int64]bool) map[int64]bool {
+	report, err := monitor.TransactionGuard(task.Context, task.Record.DatabaseName, task.Record.TargetTableName,
+		longTrxSeconds(task.Record.ExecutionParams, e.cfg))
+	if err != nil {
+		return known
+	}
+
+	task.mutex.Lock()
+	task.TransactionGuard = report
+	task.mutex.Unlock()
+
+	current := map[int64]bool{}
+	for _, trx := range report.Transactions {
+		current[trx.ThreadID] = true
+		if !known[trx.ThreadID] {
+			e.logLine(task, fmt.Sprintf("[%s] 长事务预警: %s", time.Now().Format("15:04:05"), describeTransaction(trx)))
+		}
+	}
+	if len(current) == 0 && len(known) > 0 {
+		e.logLine(task, fmt.Sprintf("[%s] 目标表上的长事务已结束", time.Now().Format("15:04:05")))
+	}
+	return current
+}
+
+// holdCutover 根据切换保护插件输出更新阶段；等待期间属于预期停顿，不计入进度停滞
+func (e *ExecutionEngine) holdCutover(task *ExecutionTask, stage string) {
+	task.mutex.Lock()
+	changed := task.CurrentStage != stage
+	if stage == stageCutoverWaiting {
+		task.lastProgressAt = time.Now()
+	}
+	task.mutex.Unlock()
+
+	if changed {
+		e.updateStage(task, stage)
+	}
+}
+
+// parseCutoverGuardStage 从切换保护插件日志识别阶段，非插件日志返回空
+func parseCutoverGuardStage(logLine string) string {
+	if !strings.Contains(logLine, utils.CutoverGuardLogPrefix) {
+		return ""
+	}
+	if strings.Contains(logLine, "waiting for long transactions") {
+		return stageCutoverWaiting
+	}
+	if strings.Contains(logLine, "swapping tables") {
+		return stageCutoverSwap
+	}
+	return ""
+}
+
+// describeTransaction 长事务的简要描述
+func describeTransaction(trx utils.BlockingTransaction) string {
+	desc := fmt.Sprintf("会话 %d（%s@%s）事务已持续 %s，锁定 %d 行", trx.ThreadID, trx.User, trx.Host,
+		utils.FormatDuration(trx.TrxSeconds), trx.RowsLocked)
+	if trx.Query != "" {
+		desc += "，当前语句: " + truncateSQL(trx.Query)
+	}
+	return desc + "；切换时需获取元数据锁，可能被其阻塞"
+}
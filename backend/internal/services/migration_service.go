@@ -0,0 +1,536 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fengzhencai/MySQLer/backend/internal/config"
+	"github.com/fengzhencai/MySQLer/backend/internal/models"
+	"github.com/fengzhencai/MySQLer/backend/internal/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// 迁移版本状态（在台账状态基础上补充未应用的情况）
+const (
+	MigrationVersionNotApplied  = "not_applied"  // 尚未生成执行记录
+	MigrationVersionUnsupported = "unsupported"  // 含不支持的语句，需人工处理
+	MigrationVersionChanged     = "changed"      // 已应用后文件内容发生变化
+	MigrationVersionEmpty       = "no_statement" // 文件中没有可执行语句
+)
+
+// MigrationService 版本化迁移导入服务
+type MigrationService struct {
+	db               *gorm.DB
+	cfg              *config.Config
+	executionService *ExecutionService
+}
+
+// NewMigrationService 创建迁移导入服务
+func NewMigrationService(db *gorm.DB, cfg *config.Config, executionService *ExecutionService) *MigrationService {
+	return &MigrationService{
+		db:               db,
+		cfg:              cfg,
+		executionService: executionService,
+	}
+}
+
+// CreateMigrationSourceRequest 登记本地目录请求
+type CreateMigrationSourceRequest struct {
+	Name        string  `json:"name" binding:"required,min=1,max=100"`
+	Path        string  `json:"path" binding:"required,min=1,max=500"`
+	Description *string `json:"description" binding:"omitempty,max=200"`
+}
+
+// MigrationTargetRequest 迁移目标（连接 + 数据库）
+type MigrationTargetRequest struct {
+	ConnectionID string `json:"connection_id" form:"connection_id" binding:"required,uuid4"`
+	DatabaseName string `json:"database_name" form:"database_name" binding:"required,min=1,max=100"`
+}
+
+// PlanMigrationRequest 为未应用版本生成执行记录的请求
+type PlanMigrationRequest struct {
+	MigrationTargetRequest
+	TargetVersion   string                  `json:"target_version"`   // 只处理不超过该版本的文件，空表示全部
+	SkipUnsupported bool                    `json:"skip_unsupported"` // 忽略不支持的语句继续生成
+	ExecutionParams *models.ExecutionParams `json:"execution_params"`
+}
+
+// BaselineMigrationRequest 将不超过指定版本的文件标记为已应用
+type BaselineMigrationRequest struct {
+	MigrationTargetRequest
+	Version string `json:"version" binding:"required,max=50"`
+}
+
+// MigrationVersionStatus 单个版本在目标库上的状态
+type MigrationVersionStatus struct {
+	*utils.MigrationFile
+	Status string                  `json:"status"`
+	Ledger *models.MigrationLedger `json:"ledger,omitempty"`
+}
+
+// MigrationPlanResult 生成结果
+type MigrationPlanResult struct {
+	Planned []models.MigrationLedger `json:"planned"`
+	Skipped []string                 `json:"skipped"`
+}
+
+// ListSources 获取迁移来源列表
+func (s *MigrationService) ListSources() ([]models.MigrationSource, error) {
+	var sources []models.MigrationSource
+	err := s.db.Order("created_at DESC").Find(&sources).Error
+	return sources, err
+}
+
+// GetSource 获取迁移来源
+func (s *MigrationService) GetSource(id string) (*models.MigrationSource, error) {
+	var source models.MigrationSource
+	if err := s.db.First(&source, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("迁移来源不存在")
+		}
+		return nil, err
+	}
+	return &source, nil
+}
+
+// CreateSource 登记服务器本地目录作为迁移来源（目录须位于允许的根目录内）
+func (s *MigrationService) CreateSource(req *CreateMigrationSourceRequest, userID string) (*models.MigrationSource, error) {
+	path, err := s.allowedSourcePath(req.Path)
+	if err != nil {
+		return nil, err
+	}
+	files, err := utils.ScanMigrationDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	source := &models.MigrationSource{
+		ID:          uuid.New().String(),
+		Name:        req.Name,
+		SourceType:  models.MigrationSourceDirectory,
+		Path:        path,
+		Description: req.Description,
+		FileCount:   len(files),
+		ScannedAt:   &now,
+		CreatedBy:   userID,
+	}
+	if err := s.db.Create(source).Error; err != nil {
+		return nil, fmt.Errorf("登记迁移来源失败: %v", err)
+	}
+	return source, nil
+}
+
+// UploadSource 上传 tar / tar.gz 包作为迁移来源
+func (s *MigrationService) UploadSource(name string, description *string, archive io.Reader, userID string) (*models.MigrationSource, error) {
+	id := uuid.New().String()
+	dir, err := filepath.Abs(filepath.Join(s.cfg.MigrationDir, id))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := utils.ExtractTarball(archive, dir); err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	files, err := utils.ScanMigrationDir(dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	if len(files) == 0 {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("压缩包中没有版本化的 .sql 文件")
+	}
+
+	now := time.Now()
+	source := &models.MigrationSource{
+		ID:          id,
+		Name:        name,
+		SourceType:  models.MigrationSourceArchive,
+		Path:        dir,
+		Description: description,
+		FileCount:   len(files),
+		ScannedAt:   &now,
+		CreatedBy:   userID,
+	}
+	if err := s.db.Create(source).Error; err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("登记迁移来源失败: %v", err)
+	}
+	return source, nil
+}
+
+// DeleteSource 删除迁移来源（上传包的解压目录一并删除，台账保留）
+func (s *MigrationService) DeleteSource(id string) error {
+	source, err := s.GetSource(id)
+	if err != nil {
+		return err
+	}
+
+	var count int64
+	s.db.Model(&models.ExecutionRecord{}).
+		Joins("JOIN migration_ledger ON migration_ledger.id = execution_records.migration_id").
+		Where("migration_ledger.source_id = ? AND execution_records.status IN ?", id,
+			[]models.ExecutionStatus{models.StatusPending, models.StatusRunning}).
+		Count(&count)
+	if count > 0 {
+		return fmt.Errorf("该来源存在未结束的执行，无法删除")
+	}
+
+	if err := s.db.Delete(source).Error; err != nil {
+		return err
+	}
+	if source.SourceType == models.MigrationSourceArchive {
+		os.RemoveAll(source.Path)
+	}
+	return nil
+}
+
+// allowedSourcePath 解析目录的真实路径并校验其位于允许的根目录内
+func (s *MigrationService) allowedSourcePath(path string) (string, error) {
+	resolved, err := resolvePath(path)
+	if err != nil {
+		return "", fmt.Errorf("目录路径无效: %v", err)
+	}
+
+	roots := s.cfg.MigrationSourceRoots
+	if len(roots) == 0 {
+		roots = []string{s.cfg.MigrationDir}
+	}
+	for _, root := range roots {
+		resolvedRoot, err := resolvePath(root)
+		if err != nil {
+			continue
+		}
+		if resolved == resolvedRoot || strings.HasPrefix(resolved, resolvedRoot+string(os.PathSeparator)) {
+			return resolved, nil
+		}
+	}
+	return "", fmt.Errorf("目录不在允许的迁移来源根目录内: %s", strings.Join(roots, ", "))
+}
+
+// resolvePath 转为绝对路径并解析符号链接
+func resolvePath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(abs)
+}
+
+// ListFiles 扫描并解析来源中的迁移文件
+func (s *MigrationService) ListFiles(id string) ([]*utils.MigrationFile, error) {
+	source, err := s.GetSource(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// 根目录配置收紧后，不再扫描范围外的已登记目录
+	if source.SourceType == models.MigrationSourceDirectory {
+		if _, err := s.allowedSourcePath(source.Path); err != nil {
+			return nil, err
+		}
+	}
+	files, err := utils.ScanMigrationDir(source.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	s.db.Model(source).Updates(map[string]interface{}{
+		"file_count": len(files),
+		"scanned_at": &now,
+	})
+	return files, nil
+}
+
+// GetStatus 获取各版本在目标库上的应用状态
+func (s *MigrationService) GetStatus(id string, target *MigrationTargetRequest) ([]MigrationVersionStatus, error) {
+	files, err := s.ListFiles(id)
+	if err != nil {
+		return nil, err
+	}
+	ledgers, err := s.loadLedgers(id, target)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]MigrationVersionStatus, 0, len(files))
+	for _, file := range files {
+		status := MigrationVersionStatus{MigrationFile: file}
+		if ledger, ok := ledgers[file.Version]; ok {
+			status.Ledger = ledger
+			status.Status = string(ledger.Status)
+			if (ledger.Status == models.MigrationApplied || ledger.Status == models.MigrationBaselined) && ledger.Checksum != file.Checksum {
+				status.Status = MigrationVersionChanged
+			}
+		} else {
+			status.Status = unappliedStatus(file)
+		}
+		result = append(result, status)
+	}
+	return result, nil
+}
+
+// Plan 为目标库上未应用的版本按顺序生成待执行记录
+func (s *MigrationService) Plan(id string, req *PlanMigrationRequest, userID string) (*MigrationPlanResult, error) {
+	files, err := s.ListFiles(id)
+	if err != nil {
+		return nil, err
+	}
+	ledgers, err := s.loadLedgers(id, &req.MigrationTargetRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MigrationPlanResult{}
+	for _, file := range files {
+		if req.TargetVersion != "" && utils.CompareMigrationVersions(file.Version, req.TargetVersion) > 0 {
+			break
+		}
+
+		ledger, exists := ledgers[file.Version]
+		if exists && ledger.Status != models.MigrationFailed {
+			continue
+		}
+
+		if file.Unsupported > 0 && !req.SkipUnsupported {
+			// 后续版本可能依赖该版本，停止生成
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s: 含 %d 条不支持的语句，已停止生成后续版本", file.Name, file.Unsupported))
+			break
+		}
+
+		planned, warnings, err := s.planFile(id, file, ledger, req, userID)
+		if err != nil {
+			return result, fmt.Errorf("版本 %s 生成执行失败: %v", file.Version, err)
+		}
+		result.Skipped = append(result.Skipped, warnings...)
+		if planned != nil {
+			result.Planned = append(result.Planned, *planned)
+		}
+	}
+
+	return result, nil
+}
+
+// Baseline 将不超过指定版本且尚未登记的文件标记为已应用
+func (s *MigrationService) Baseline(id string, req *BaselineMigrationRequest, userID string) ([]models.MigrationLedger, error) {
+	files, err := s.ListFiles(id)
+	if err != nil {
+		return nil, err
+	}
+	ledgers, err := s.loadLedgers(id, &req.MigrationTargetRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []models.MigrationLedger
+	now := time.Now()
+	for _, file := range files {
+		if utils.CompareMigrationVersions(file.Version, req.Version) > 0 {
+			break
+		}
+		if _, exists := ledgers[file.Version]; exists {
+			continue
+		}
+		ledger := models.MigrationLedger{
+			ID:           uuid.New().String(),
+			SourceID:     id,
+			Version:      file.Version,
+			ConnectionID: req.ConnectionID,
+			DatabaseName: req.DatabaseName,
+			FileName:     file.Name,
+			Checksum:     file.Checksum,
+			Status:       models.MigrationBaselined,
+			AppliedAt:    &now,
+			CreatedBy:    userID,
+		}
+		if err := s.db.Create(&ledger).Error; err != nil {
+			return result, fmt.Errorf("登记版本 %s 失败: %v", file.Version, err)
+		}
+		result = append(result, ledger)
+	}
+	return result, nil
+}
+
+// planFile 为单个版本的 ALTER 语句生成执行记录；失败时回滚该版本已创建的记录
+func (s *MigrationService) planFile(sourceID string, file *utils.MigrationFile, ledger *models.MigrationLedger, req *PlanMigrationRequest, userID string) (*models.MigrationLedger, []string, error) {
+	var warnings []string
+	var statements []*utils.MigrationStatement
+	for _, stmt := range file.Statements {
+		if !stmt.Supported {
+			warnings = append(warnings, fmt.Sprintf("%s 第 %d 条语句不支持，已忽略: %s", file.Name, stmt.Index+1, truncateSQL(stmt.SQL)))
+			continue
+		}
+		if stmt.Schema != "" && !strings.EqualFold(stmt.Schema, req.DatabaseName) {
+			warnings = append(warnings, fmt.Sprintf("%s 第 %d 条语句指定了库 %s，将在 %s 上执行", file.Name, stmt.Index+1, stmt.Schema, req.DatabaseName))
+		}
+		statements = append(statements, stmt)
+	}
+	if len(statements) == 0 {
+		warnings = append(warnings, fmt.Sprintf("%s 没有可执行的 ALTER 语句", file.Name))
+		return nil, warnings, nil
+	}
+
+	// 重新生成失败的版本时跳过已成功执行的语句
+	if ledger != nil {
+		completed := make(map[string]bool)
+		for _, record := range ledger.Executions {
+			if record.Status.Succeeded() && record.OriginalDDL != nil {
+				completed[*record.OriginalDDL] = true
+			}
+		}
+		remaining := statements[:0]
+		for _, stmt := range statements {
+			if completed[stmt.SQL] {
+				warnings = append(warnings, fmt.Sprintf("%s 第 %d 条语句已执行成功，跳过", file.Name, stmt.Index+1))
+				continue
+			}
+			remaining = append(remaining, stmt)
+		}
+		statements = remaining
+	}
+
+	if ledger == nil {
+		ledger = &models.MigrationLedger{
+			ID:           uuid.New().String(),
+			SourceID:     sourceID,
+			Version:      file.Version,
+			ConnectionID: req.ConnectionID,
+			DatabaseName: req.DatabaseName,
+			CreatedBy:    userID,
+		}
+	}
+	ledger.FileName = file.Name
+	ledger.Checksum = file.Checksum
+	now := time.Now()
+	ledger.Status = models.MigrationPending
+	ledger.PlannedAt = &now
+	ledger.AppliedAt = nil
+	if len(statements) == 0 {
+		ledger.Status = models.MigrationApplied
+		ledger.AppliedAt = &now
+	}
+	if err := s.db.Omit("Executions").Save(ledger).Error; err != nil {
+		return nil, warnings, err
+	}
+
+	var created []string
+	for _, stmt := range statements {
+		sql := stmt.SQL
+		ddlType := models.DDLOther
+		if cleaned, err := utils.CleanAlterSQL(sql); err == nil {
+			ddlType = inferDDLType(utils.ParseAlterClauses(cleaned))
+		}
+
+		record, err := s.executionService.Create(&CreateExecutionRequest{
+			ConnectionID:    req.ConnectionID,
+			TableName:       stmt.Table,
+			DatabaseName:    req.DatabaseName,
+			DDLType:         &ddlType,
+			OriginalDDL:     &sql,
+			ExecutionParams: req.ExecutionParams,
+			MigrationID:     &ledger.ID,
+		}, userID)
+		if err != nil {
+			if len(created) > 0 {
+				s.db.Delete(&models.ExecutionRecord{}, "id IN ?", created)
+			}
+			s.db.Model(ledger).Update("status", models.MigrationFailed)
+			return nil, warnings, fmt.Errorf("第 %d 条语句: %v", stmt.Index+1, err)
+		}
+		created = append(created, record.ID)
+	}
+
+	return ledger, warnings, nil
+}
+
+// loadLedgers 加载目标库的台账并根据执行记录同步状态
+func (s *MigrationService) loadLedgers(sourceID string, target *MigrationTargetRequest) (map[string]*models.MigrationLedger, error) {
+	var ledgers []models.MigrationLedger
+	err := s.db.Preload("Executions", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "migration_id", "table_name", "status", "end_time", "error_message", "original_ddl", "created_at").Order("created_at")
+	}).Where("source_id = ? AND connection_id = ? AND database_name = ?",
+		sourceID, target.ConnectionID, target.DatabaseName).Find(&ledgers).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]*models.MigrationLedger, len(ledgers))
+	for i := range ledgers {
+		ledger := &ledgers[i]
+		if ledger.Status == models.MigrationPending {
+			s.syncLedger(ledger)
+		}
+		result[ledger.Version] = ledger
+	}
+	return result, nil
+}
+
+// syncLedger 最近一次生成的执行全部完成则标记为已应用，任一失败或取消则标记为失败
+func (s *MigrationService) syncLedger(ledger *models.MigrationLedger) {
+	var records []models.ExecutionRecord
+	for _, record := range ledger.Executions {
+		if ledger.PlannedAt == nil || !record.CreatedAt.Before(ledger.PlannedAt.Truncate(time.Second)) {
+			records = append(records, record)
+		}
+	}
+	if len(records) == 0 {
+		return
+	}
+
+	status := models.MigrationApplied
+	var appliedAt *time.Time
+	for _, record := range records {
+		switch record.Status {
+		case models.StatusFailed, models.StatusCancelled:
+			status = models.MigrationFailed
+		case models.StatusCompleted, models.StatusCompletedWithWarnings:
+			if record.EndTime != nil && (appliedAt == nil || record.EndTime.After(*appliedAt)) {
+				appliedAt = record.EndTime
+			}
+		default:
+			if status != models.MigrationFailed {
+				status = models.MigrationPending
+			}
+		}
+	}
+	if status == ledger.Status {
+		return
+	}
+
+	updates := map[string]interface{}{"status": status}
+	ledger.Status = status
+	if status == models.MigrationApplied {
+		ledger.AppliedAt = appliedAt
+		updates["applied_at"] = appliedAt
+	}
+	s.db.Model(&models.MigrationLedger{}).Where("id = ?", ledger.ID).Updates(updates)
+}
+
+// unappliedStatus 未登记版本的状态
+func unappliedStatus(file *utils.MigrationFile) string {
+	switch {
+	case len(file.Statements) == 0:
+		return MigrationVersionEmpty
+	case file.Unsupported > 0:
+		return MigrationVersionUnsupported
+	}
+	return MigrationVersionNotApplied
+}
+
+// truncateSQL 截断过长的语句用于提示
+func truncateSQL(sql string) string {
+	sql = strings.Join(strings.Fields(sql), " ")
+	if len([]rune(sql)) > 80 {
+		return string([]rune(sql)[:80]) + "..."
+	}
+	return sql
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/fengzhencai/MySQLer/backend/internal/config"
+)
+
+// Notification 告警通知内容
+type Notification struct {
+	Event       string                 `json:"event"`
+	Level       string                 `json:"level"` // info / warning / critical
+	Title       string                 `json:"title"`
+	Message     string                 `json:"message"`
+	ExecutionID string                 `json:"execution_id,omitempty"`
+	Data        map[string]interface{} `json:"data,omitempty"`
+	Timestamp   string                 `json:"timestamp"`
+}
+
+// NotificationService 告警通知服务（Webhook）
+type NotificationService struct {
+	cfg    *config.Config
+	client *http.Client
+}
+
+// NewNotificationService 创建通知服务
+func NewNotificationService(cfg *config.Config) *NotificationService {
+	return &NotificationService{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Enabled 是否配置了通知Webhook
+func (s *NotificationService) Enabled() bool {
+	return s.cfg.AlertWebhookURL != ""
+}
+
+// Notify 异步发送通知，失败仅记录日志
+func (s *NotificationService) Notify(notification *Notification) {
+	if !s.Enabled() {
+		return
+	}
+	if notification.Timestamp == "" {
+		notification.Timestamp = time.Now().Format("2006-01-02 15:04:05")
+	}
+
+	go func() {
+		if err := s.send(notification); err != nil {
+			fmt.Printf("发送告警通知失败: %v\n", err)
+		}
+	}()
+}
+
+// send 以JSON POST到Webhook
+func (s *NotificationService) send(notification *Notification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Post(s.cfg.AlertWebhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("Webhook返回状态码 %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,292 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fengzhencai/MySQLer/backend/internal/config"
+	"github.com/fengzhencai/MySQLer/backend/internal/models"
+	"github.com/fengzhencai/MySQLer/backend/internal/utils"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// SwapBackWarning 切回旧表的数据丢失提示
+const SwapBackWarning = "旧表在切换后不再接收写入，切换后写入新表的数据将全部丢失"
+
+// OldTableService 旧表保留、切回与过期清理服务
+type OldTableService struct {
+	db     *gorm.DB
+	cfg    *config.Config
+	crypto *utils.CryptoService
+	audit  *AuditService
+	logs   *ExecutionLogService
+
+	stopChan chan struct{}
+	stopOnce sync.Once
+}
+
+// NewOldTableService 创建旧表服务
+func NewOldTableService(db *gorm.DB, cfg *config.Config) *OldTableService {
+	return &OldTableService{
+		db:       db,
+		cfg:      cfg,
+		crypto:   utils.NewCryptoService(cfg.EncryptionKey),
+		audit:    NewAuditService(db, cfg),
+		logs:     NewExecutionLogService(db, cfg),
+		stopChan: make(chan struct{}),
+	}
+}
+
+// SwapBackResult 切回旧表结果
+type SwapBackResult struct {
+	ExecutionID    string     `json:"execution_id"`
+	RestoredTable  string     `json:"restored_table"`  // 被恢复为正式表的旧表
+	DisplacedTable string     `json:"displaced_table"` // 被替换下来的新表（保留至过期）
+	ExpireAt       *time.Time `json:"expire_at"`
+	Warning        string     `json:"warning"`
+}
+
+// retention 计算旧表保留时长
+func (s *OldTableService) retention(params *models.ExecutionParams) time.Duration {
+	if params != nil && params.OldTableRetain > 0 {
+		return time.Duration(params.OldTableRetain) * time.Hour
+	}
+	return s.cfg.OldTableRetention
+}
+
+// MarkKept 执行成功后登记保留的旧表（仅修改记录字段，由调用方保存）
+// 旧表名优先取 pt-osc 输出中的 RENAME 语句，否则使用执行前预测的名称
+func (s *OldTableService) MarkKept(record *models.ExecutionRecord, dbConn *utils.DatabaseConnection, expected, output string) error {
+	if record.ExecutionParams == nil || !record.ExecutionParams.KeepOldTable {
+		return nil
+	}
+
+	oldTable := utils.ParseOldTableName(output, record.DatabaseName, record.TargetTableName)
+	if oldTable == "" {
+		oldTable = expected
+	}
+	if oldTable == "" {
+		return fmt.Errorf("无法确定本次保留的旧表名")
+	}
+	exists, err := utils.TableExists(dbConn, record.DatabaseName, oldTable)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("旧表 %s 不存在", oldTable)
+	}
+
+	status := models.OldTableKept
+	expireAt := time.Now().Add(s.retention(record.ExecutionParams))
+	record.OldTableName = &oldTable
+	record.OldTableStatus = &status
+	record.OldTableExpireAt = &expireAt
+	return nil
+}
+
+// SwapBack 原子地将旧表切换回正式表，新表改名保留至过期
+func (s *OldTableService) SwapBack(id string, reason string, userID string) (*SwapBackResult, error) {
+	if strings.TrimSpace(reason) == "" {
+		return nil, fmt.Errorf("请填写切回旧表的原因")
+	}
+
+	var record models.ExecutionRecord
+	if err := s.db.Preload("Connection").First(&record, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("执行记录不存在")
+		}
+		return nil, err
+	}
+	if !record.Status.Succeeded() {
+		return nil, fmt.Errorf("仅已完成的任务允许切回旧表")
+	}
+	if record.OldTableName == nil || record.OldTableStatus == nil || *record.OldTableStatus != models.OldTableKept {
+		return nil, fmt.Errorf("该执行没有可切回的旧表")
+	}
+
+	// 之后已有变更完成时切回会一并撤销这些变更，拒绝切回
+	var later int64
+	if err := s.db.Model(&models.ExecutionRecord{}).
+		Where("connection_id = ? AND database_name = ? AND target_table_name = ? AND id <> ? AND status IN ? AND end_time > ?",
+			record.ConnectionID, record.DatabaseName, record.TargetTableName, record.ID,
+			[]models.ExecutionStatus{models.StatusCompleted, models.StatusCompletedWithWarnings}, record.EndTime).
+		Count(&later).Error; err != nil {
+		return nil, err
+	}
+	if later > 0 {
+		return nil, fmt.Errorf("该表在本次执行之后已有其他变更完成，切回旧表会撤销这些变更，已拒绝")
+	}
+
+	dbConn, err := buildDatabaseConnection(s.crypto, &record.Connection, record.DatabaseName)
+	if err != nil {
+		return nil, err
+	}
+
+	oldTable := *record.OldTableName
+	exists, err := utils.TableExists(dbConn, record.DatabaseName, oldTable)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("旧表 %s 已不存在", oldTable)
+	}
+
+	displaced := fmt.Sprintf("_%s_swapped", record.TargetTableName)
+	if len(displaced) > 64 {
+		return nil, fmt.Errorf("表名过长，无法生成替换表名")
+	}
+	if exists, err := utils.TableExists(dbConn, record.DatabaseName, displaced); err != nil {
+		return nil, err
+	} else if exists {
+		return nil, fmt.Errorf("表 %s 已存在，请先处理后再切回", displaced)
+	}
+
+	// 单条 RENAME TABLE 语句保证切换的原子性
+	err = utils.RenameTables(dbConn, record.DatabaseName, []utils.TableRename{
+		{From: record.TargetTableName, To: displaced},
+		{From: oldTable, To: record.TargetTableName},
+	})
+	s.recordAudit(&record, userID, models.ActionOldTableSwapBack, map[string]interface{}{
+		"reason":          reason,
+		"restored_table":  oldTable,
+		"displaced_table": displaced,
+	}, err)
+	if err != nil {
+		return nil, err
+	}
+
+	status := models.OldTableRestored
+	if err := s.db.Model(&models.ExecutionRecord{}).Where("id = ?", record.ID).Updates(map[string]interface{}{
+		"old_table_name":   displaced,
+		"old_table_status": status,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("更新执行记录失败: %v", err)
+	}
+	s.appendHistory(&record, fmt.Sprintf("已将旧表 %s 切换回 %s，原表改名为 %s", oldTable, record.TargetTableName, displaced))
+
+	return &SwapBackResult{
+		ExecutionID:    record.ID,
+		RestoredTable:  oldTable,
+		DisplacedTable: displaced,
+		ExpireAt:       record.OldTableExpireAt,
+		Warning:        SwapBackWarning,
+	}, nil
+}
+
+// StartJanitor 启动过期旧表清理任务
+func (s *OldTableService) StartJanitor() {
+	interval := s.cfg.OldTableJanitorInterval
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stopChan:
+				return
+			case <-ticker.C:
+				s.cleanupExpired()
+			}
+		}
+	}()
+}
+
+// Stop 停止清理任务
+func (s *OldTableService) Stop() {
+	s.stopOnce.Do(func() { close(s.stopChan) })
+}
+
+// cleanupExpired 删除已过期的旧表
+func (s *OldTableService) cleanupExpired() {
+	var records []models.ExecutionRecord
+	err := s.db.Preload("Connection").
+		Where("old_table_status IN ? AND old_table_expire_at <= ?",
+			[]models.OldTableStatus{models.OldTableKept, models.OldTableRestored}, time.Now()).
+		Find(&records).Error
+	if err != nil {
+		logrus.Errorf("查询过期旧表失败: %v", err)
+		return
+	}
+
+	for i := range records {
+		record := &records[i]
+		if err := s.dropOldTable(record); err != nil {
+			logrus.Errorf("删除过期旧表失败 (execution=%s): %v", record.ID, err)
+		}
+	}
+}
+
+// dropOldTable 删除单条执行保留的旧表并记录历史
+func (s *OldTableService) dropOldTable(record *models.ExecutionRecord) error {
+	if record.OldTableName == nil {
+		return nil
+	}
+
+	dbConn, err := buildDatabaseConnection(s.crypto, &record.Connection, record.DatabaseName)
+	if err != nil {
+		return err
+	}
+
+	oldTable := *record.OldTableName
+	err = utils.DropTable(dbConn, record.DatabaseName, oldTable)
+	s.recordAudit(record, "", models.ActionOldTableDrop, map[string]interface{}{"old_table": oldTable}, err)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.Model(&models.ExecutionRecord{}).Where("id = ?", record.ID).
+		Update("old_table_status", models.OldTableDropped).Error; err != nil {
+		return err
+	}
+	s.appendHistory(record, fmt.Sprintf("旧表 %s 已超过保留期限，已自动删除", oldTable))
+	return nil
+}
+
+// appendHistory 在执行日志末尾追加一条历史记录
+func (s *OldTableService) appendHistory(record *models.ExecutionRecord, message string) {
+	line := fmt.Sprintf("[%s] %s", time.Now().Format("2006-01-02 15:04:05"), message)
+	if err := s.logs.Append(record.ID, record.Attempts, line); err != nil {
+		fmt.Printf("追加执行日志失败: %v\n", err)
+	}
+}
+
+// recordAudit 记录旧表操作审计
+func (s *OldTableService) recordAudit(record *models.ExecutionRecord, userID string, action models.AuditAction, data map[string]interface{}, opErr error) {
+	auditLog := &models.AuditLog{
+		Action:       string(action),
+		ResourceType: stringPtr("execution"),
+		ResourceID:   &record.ID,
+		Status:       models.AuditStatusSuccess,
+		RiskLevel:    "high",
+		CreatedAt:    time.Now(),
+	}
+	if userID != "" {
+		auditLog.UserID = &userID
+	} else {
+		auditLog.Username = stringPtr("system")
+	}
+	if opErr != nil {
+		auditLog.Status = models.AuditStatusFailed
+		errorMsg := opErr.Error()
+		auditLog.ErrorMsg = &errorMsg
+	}
+	if data != nil {
+		data["database"] = record.DatabaseName
+		data["table"] = record.TargetTableName
+		if raw, err := json.Marshal(data); err == nil {
+			payload := json.RawMessage(raw)
+			auditLog.RequestData = &payload
+		}
+	}
+	if err := s.audit.CreateAuditLog(auditLog); err != nil {
+		logrus.Errorf("记录审计日志失败: %v", err)
+	}
+}
@@ -184,6 +184,18 @@ func (s *PermissionService) CheckExecutionPermission(userID, connectionID, table
 	return nil
 }
 
+// EnsureDefaultPermissions 权限表为空时写入默认权限（已有数据不覆盖）
+func (s *PermissionService) EnsureDefaultPermissions() error {
+	var count int64
+	if err := s.db.Model(&models.Permission{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return s.InitializeDefaultPermissions()
+}
+
 // InitializeDefaultPermissions 初始化默认权限数据
 func (s *PermissionService) InitializeDefaultPermissions() error {
 	// 1. 创建权限记录
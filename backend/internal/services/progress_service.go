@@ -0,0 +1,167 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/fengzhencai/MySQLer/backend/internal/config"
+	"github.com/fengzhencai/MySQLer/backend/internal/models"
+	"github.com/fengzhencai/MySQLer/backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+// defaultSeriesPoints 进度序列默认返回的最大点数
+const defaultSeriesPoints = 500
+
+// ProgressService 执行进度与实例负载时间序列服务
+type ProgressService struct {
+	db  *gorm.DB
+	cfg *config.Config
+}
+
+// NewProgressService 创建进度序列服务
+func NewProgressService(db *gorm.DB, cfg *config.Config) *ProgressService {
+	return &ProgressService{db: db, cfg: cfg}
+}
+
+// LoadSeries 执行期间的负载序列及其对照阈值
+type LoadSeries struct {
+	MaxLoad      []utils.LoadThreshold        `json:"max_load"`
+	CriticalLoad []utils.LoadThreshold        `json:"critical_load"`
+	Samples      []models.ExecutionLoadSample `json:"samples"`
+}
+
+// ProgressSummary 由进度序列计算的执行汇总
+type ProgressSummary struct {
+	ProcessedRows int64
+	AvgSpeed      float64 // 首尾采样之间的平均速度（行/秒），不足两个采样时为0
+}
+
+// Record 写入一条进度采样
+func (s *ProgressService) Record(sample *models.ExecutionProgressSample) error {
+	if err := s.db.Create(sample).Error; err != nil {
+		return fmt.Errorf("保存进度采样失败: %v", err)
+	}
+	return nil
+}
+
+// RecordLoad 写入一条负载采样
+func (s *ProgressService) RecordLoad(executionID string, load *utils.ServerLoad) error {
+	sample := &models.ExecutionLoadSample{
+		ExecutionID:        executionID,
+		SampledAt:          load.SampledAt,
+		ThreadsRunning:     load.ThreadsRunning,
+		ThreadsConnected:   load.ThreadsConnected,
+		HistoryListLength:  load.HistoryListLength,
+		ReplicationLag:     load.ReplicationLag,
+		RowsReadPerSec:     load.RowsReadPerSec,
+		RowsInsertedPerSec: load.RowsInsertedPerSec,
+		RowsUpdatedPerSec:  load.RowsUpdatedPerSec,
+		RowsDeletedPerSec:  load.RowsDeletedPerSec,
+	}
+	if err := s.db.Create(sample).Error; err != nil {
+		return fmt.Errorf("保存负载采样失败: %v", err)
+	}
+	return nil
+}
+
+// Clear 清除执行的历史进度与负载采样（重试时重新记录）
+func (s *ProgressService) Clear(executionID string) error {
+	if err := s.db.Where("execution_id = ?", executionID).Delete(&models.ExecutionProgressSample{}).Error; err != nil {
+		return err
+	}
+	return s.db.Where("execution_id = ?", executionID).Delete(&models.ExecutionLoadSample{}).Error
+}
+
+// ListSeries 获取执行的进度序列，超过 maxPoints 时按步长抽样（始终保留首尾与暂停状态变化点）
+func (s *ProgressService) ListSeries(executionID string, maxPoints int) ([]models.ExecutionProgressSample, error) {
+	var samples []models.ExecutionProgressSample
+	if err := s.db.Where("execution_id = ?", executionID).Order("sampled_at, id").Find(&samples).Error; err != nil {
+		return nil, err
+	}
+
+	if maxPoints <= 0 {
+		maxPoints = defaultSeriesPoints
+	}
+	if len(samples) <= maxPoints {
+		return samples, nil
+	}
+
+	step := (len(samples) + maxPoints - 1) / maxPoints
+	result := make([]models.ExecutionProgressSample, 0, maxPoints+1)
+	for i, sample := range samples {
+		last := i == len(samples)-1
+		throttleChanged := i > 0 && sample.Throttled != samples[i-1].Throttled
+		if i%step == 0 || last || throttleChanged {
+			result = append(result, sample)
+		}
+	}
+	return result, nil
+}
+
+// GetLoadSeries 获取执行期间的负载序列，超过 maxPoints 时按步长抽样
+func (s *ProgressService) GetLoadSeries(executionID string, maxPoints int) (*LoadSeries, error) {
+	var record models.ExecutionRecord
+	if err := s.db.Select("id", "execution_params").First(&record, "id = ?", executionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("执行记录不存在")
+		}
+		return nil, err
+	}
+
+	var samples []models.ExecutionLoadSample
+	if err := s.db.Where("execution_id = ?", executionID).Order("sampled_at, id").Find(&samples).Error; err != nil {
+		return nil, err
+	}
+	if maxPoints <= 0 {
+		maxPoints = defaultSeriesPoints
+	}
+	if len(samples) > maxPoints {
+		step := (len(samples) + maxPoints - 1) / maxPoints
+		sampled := make([]models.ExecutionLoadSample, 0, maxPoints+1)
+		for i, sample := range samples {
+			if i%step == 0 || i == len(samples)-1 {
+				sampled = append(sampled, sample)
+			}
+		}
+		samples = sampled
+	}
+
+	maxLoad, criticalLoad := LoadThresholds(record.ExecutionParams, s.cfg)
+	return &LoadSeries{
+		MaxLoad:      maxLoad,
+		CriticalLoad: criticalLoad,
+		Samples:      samples,
+	}, nil
+}
+
+// LoadThresholds 解析执行参数中的 max-load / critical-load，未设置时使用默认配置
+func LoadThresholds(params *models.ExecutionParams, cfg *config.Config) (maxLoad, criticalLoad []utils.LoadThreshold) {
+	maxSpec, criticalSpec := cfg.PTDefaultMaxLoad, cfg.PTDefaultCriticalLoad
+	if params != nil {
+		if params.MaxLoad != "" {
+			maxSpec = params.MaxLoad
+		}
+		if params.CriticalLoad != "" {
+			criticalSpec = params.CriticalLoad
+		}
+	}
+	return utils.ParseLoadThresholds(maxSpec), utils.ParseLoadThresholds(criticalSpec)
+}
+
+// Summarize 根据进度序列计算已处理行数与平均速度
+func (s *ProgressService) Summarize(executionID string) (*ProgressSummary, error) {
+	var first, last models.ExecutionProgressSample
+	if err := s.db.Where("execution_id = ?", executionID).Order("sampled_at, id").First(&first).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Where("execution_id = ?", executionID).Order("sampled_at DESC, id DESC").First(&last).Error; err != nil {
+		return nil, err
+	}
+
+	summary := &ProgressSummary{ProcessedRows: last.ProcessedRows}
+	if seconds := last.SampledAt.Sub(first.SampledAt).Seconds(); seconds > 0 && last.ProcessedRows > first.ProcessedRows {
+		summary.AvgSpeed = float64(last.ProcessedRows-first.ProcessedRows) / seconds
+	}
+	return summary, nil
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fengzhencai/MySQLer/backend/internal/models"
+	"github.com/fengzhencai/MySQLer/backend/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultRetriableCategories 默认可自动重试的失败分类（通常为暂时性问题）
+var defaultRetriableCategories = []string{
+	utils.FailureCriticalLoad,
+	utils.FailureLockWaitTimeout,
+	utils.FailureContainer,
+}
+
+// RetryPolicyService 自动重试策略服务
+type RetryPolicyService struct {
+	db *gorm.DB
+}
+
+// NewRetryPolicyService 创建自动重试策略服务
+func NewRetryPolicyService(db *gorm.DB) *RetryPolicyService {
+	return &RetryPolicyService{db: db}
+}
+
+// UpdateRetryPolicyRequest 更新重试策略请求
+type UpdateRetryPolicyRequest struct {
+	Enabled             *bool    `json:"enabled"`
+	MaxAttempts         *int     `json:"max_attempts" binding:"omitempty,min=1,max=10"`
+	BackoffSeconds      *int     `json:"backoff_seconds" binding:"omitempty,min=0,max=86400"`
+	BackoffMultiplier   *float64 `json:"backoff_multiplier" binding:"omitempty,min=1,max=10"`
+	MaxBackoffSeconds   *int     `json:"max_backoff_seconds" binding:"omitempty,min=0,max=86400"`
+	RetriableCategories []string `json:"retriable_categories"`
+}
+
+// defaultRetryPolicy 内置默认策略（默认关闭，需管理员按环境开启）
+func defaultRetryPolicy(env models.Environment) *models.RetryPolicy {
+	return &models.RetryPolicy{
+		Environment:         env,
+		Enabled:             false,
+		MaxAttempts:         3,
+		BackoffSeconds:      60,
+		BackoffMultiplier:   2,
+		MaxBackoffSeconds:   900,
+		RetriableCategories: strings.Join(defaultRetriableCategories, ","),
+	}
+}
+
+// Get 获取环境下生效的重试策略（未配置时返回默认值）
+func (s *RetryPolicyService) Get(env models.Environment) (*models.RetryPolicy, error) {
+	if err := validateEnvironment(env); err != nil {
+		return nil, err
+	}
+
+	var policy models.RetryPolicy
+	err := s.db.Where("environment = ?", env).First(&policy).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return defaultRetryPolicy(env), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// Update 更新环境下的重试策略
+func (s *RetryPolicyService) Update(env models.Environment, req *UpdateRetryPolicyRequest, userID string) (*models.RetryPolicy, error) {
+	policy, err := s.Get(env)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Enabled != nil {
+		policy.Enabled = *req.Enabled
+	}
+	if req.MaxAttempts != nil {
+		policy.MaxAttempts = *req.MaxAttempts
+	}
+	if req.BackoffSeconds != nil {
+		policy.BackoffSeconds = *req.BackoffSeconds
+	}
+	if req.BackoffMultiplier != nil {
+		policy.BackoffMultiplier = *req.BackoffMultiplier
+	}
+	if req.MaxBackoffSeconds != nil {
+		policy.MaxBackoffSeconds = *req.MaxBackoffSeconds
+	}
+	if req.RetriableCategories != nil {
+		categories, err := validateFailureCategories(req.RetriableCategories)
+		if err != nil {
+			return nil, err
+		}
+		policy.RetriableCategories = strings.Join(categories, ",")
+	}
+	policy.UpdatedBy = userID
+
+	err = s.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "environment"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "max_attempts", "backoff_seconds", "backoff_multiplier",
+			"max_backoff_seconds", "retriable_categories", "updated_by", "updated_at"}),
+	}).Create(policy).Error
+	if err != nil {
+		return nil, fmt.Errorf("保存重试策略失败: %v", err)
+	}
+	return s.Get(env)
+}
+
+// validateFailureCategories 校验失败分类并去重
+func validateFailureCategories(categories []string) ([]string, error) {
+	known := map[string]bool{}
+	for _, category := range utils.FailureCategories() {
+		known[category.Category] = true
+	}
+
+	seen := map[string]bool{}
+	var result []string
+	for _, category := range categories {
+		category = strings.TrimSpace(category)
+		if !known[category] {
+			return nil, fmt.Errorf("未知的失败分类: %s", category)
+		}
+		if !seen[category] {
+			seen[category] = true
+			result = append(result, category)
+		}
+	}
+	return result, nil
+}
@@ -128,7 +128,7 @@ func (s *SafetyService) checkDDLTypeRisk(ddlType models.DDLType, originalDDL str
 	case "drop_column":
 		result.RiskLevel = "high"
 		result.Warnings = append(result.Warnings, "删除列操作不可逆，数据将永久丢失")
-		result.Suggestions = append(result.Suggestions, "建议先备份相关数据")
+		result.Suggestions = append(result.Suggestions, "执行前将自动导出主键与被删除列的数据，可在需要时恢复到重新添加的列")
 
 	case "drop_index":
 		result.RiskLevel = "medium"
@@ -11,8 +11,21 @@ type Services struct {
 	Connection      *ConnectionService
 	Execution       *ExecutionService
 	ExecutionEngine *ExecutionEngine
+	Batch           *BatchService
+	Snapshot        *SnapshotService
+	Progress        *ProgressService
+	OldTable        *OldTableService
+	ColumnBackup    *ColumnBackupService
+	Drift           *DriftService
+	Change          *ChangeService
+	Migration       *MigrationService
+	Lint            *LintService
 	User            *UserService
 	Audit           *AuditService
+	Permission      *PermissionService
+	BlockingSession *BlockingSessionService
+	RetryPolicy     *RetryPolicyService
+	ExecutionLog    *ExecutionLogService
 	MVP             *MVPService
 }
 
@@ -26,13 +39,50 @@ func NewServices(db *gorm.DB, cfg *config.Config) (*Services, error) {
 		return nil, err
 	}
 
+	executionService := NewExecutionService(db, cfg, connectionService)
+
+	// 启动过期旧表清理
+	oldTableService := NewOldTableService(db, cfg)
+	oldTableService.StartJanitor()
+
+	// 初始化默认角色权限
+	permissionService := NewPermissionService(db)
+	if err := permissionService.EnsureDefaultPermissions(); err != nil {
+		return nil, err
+	}
+
+	// 启动执行日志压缩与过期清理
+	executionLogService := NewExecutionLogService(db, cfg)
+	executionLogService.StartJanitor()
+
+	// 收尾上次进程中断的批量执行
+	batchService := NewBatchService(db, cfg, executionService, executionEngine)
+	batchService.RecoverInterrupted()
+
+	// 启动定时漂移检查
+	driftService := NewDriftService(db, cfg)
+	driftService.StartScheduler()
+
 	return &Services{
 		Auth:            NewAuthService(db, cfg),
 		Connection:      connectionService,
-		Execution:       NewExecutionService(db, cfg, connectionService),
+		Execution:       executionService,
 		ExecutionEngine: executionEngine,
+		Batch:           batchService,
+		Snapshot:        NewSnapshotService(db),
+		Progress:        NewProgressService(db, cfg),
+		OldTable:        oldTableService,
+		ColumnBackup:    NewColumnBackupService(db, cfg),
+		Drift:           driftService,
+		Change:          NewChangeService(db, cfg, executionService, executionEngine),
+		Migration:       NewMigrationService(db, cfg, executionService),
+		Lint:            NewLintService(db),
 		User:            NewUserService(db, cfg),
 		Audit:           NewAuditService(db, cfg),
 		MVP:             NewMVPService(cfg),
+		Permission:      permissionService,
+		BlockingSession: NewBlockingSessionService(db, cfg),
+		RetryPolicy:     NewRetryPolicyService(db),
+		ExecutionLog:    executionLogService,
 	}, nil
 }
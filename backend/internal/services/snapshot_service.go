@@ -0,0 +1,122 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/fengzhencai/MySQLer/backend/internal/models"
+	"github.com/fengzhencai/MySQLer/backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+// autoIncrementPattern 建表语句中的自增计数（对比时忽略）
+var autoIncrementPattern = regexp.MustCompile(` AUTO_INCREMENT=\d+`)
+
+// SnapshotService 表结构快照服务
+type SnapshotService struct {
+	db *gorm.DB
+}
+
+// NewSnapshotService 创建快照服务
+func NewSnapshotService(db *gorm.DB) *SnapshotService {
+	return &SnapshotService{db: db}
+}
+
+// SchemaDiffResponse 执行前后结构差异
+type SchemaDiffResponse struct {
+	ExecutionID string                 `json:"execution_id"`
+	Before      *models.SchemaSnapshot `json:"before"`
+	After       *models.SchemaSnapshot `json:"after"`
+	Diff        string                 `json:"diff"`    // unified diff
+	Changed     bool                   `json:"changed"` // 建表语句是否发生变化
+}
+
+// Capture 采集指定执行的表结构快照（SHOW CREATE TABLE + 行数/大小）
+func (s *SnapshotService) Capture(dbConn *utils.DatabaseConnection, record *models.ExecutionRecord, phase models.SnapshotPhase) (*models.SchemaSnapshot, error) {
+	createSQL, err := utils.GetCreateTable(dbConn, record.DatabaseName, record.TargetTableName)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &models.SchemaSnapshot{
+		ExecutionID:     record.ID,
+		Phase:           phase,
+		DatabaseName:    record.DatabaseName,
+		TargetTableName: record.TargetTableName,
+		CreateTableSQL:  createSQL,
+		CapturedAt:      time.Now(),
+	}
+
+	// 行数与大小来自 information_schema（估算值）
+	if space, err := utils.GetTableSpace(dbConn, record.DatabaseName, record.TargetTableName); err == nil {
+		snapshot.TableRows = space.TableRows
+		snapshot.DataLength = space.DataLength
+		snapshot.IndexLength = space.IndexLength
+		snapshot.DataFree = space.DataFree
+	}
+
+	// 同一阶段只保留最新一份（重试时覆盖）
+	s.db.Where("execution_id = ? AND phase = ?", record.ID, phase).Delete(&models.SchemaSnapshot{})
+	if err := s.db.Create(snapshot).Error; err != nil {
+		return nil, fmt.Errorf("保存结构快照失败: %v", err)
+	}
+
+	return snapshot, nil
+}
+
+// GetSnapshot 获取指定阶段的快照
+func (s *SnapshotService) GetSnapshot(executionID string, phase models.SnapshotPhase) (*models.SchemaSnapshot, error) {
+	var snapshot models.SchemaSnapshot
+	err := s.db.Where("execution_id = ? AND phase = ?", executionID, phase).
+		Order("id DESC").First(&snapshot).Error
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// ListSnapshots 获取执行的全部快照
+func (s *SnapshotService) ListSnapshots(executionID string) ([]models.SchemaSnapshot, error) {
+	var snapshots []models.SchemaSnapshot
+	err := s.db.Where("execution_id = ?", executionID).Order("id").Find(&snapshots).Error
+	return snapshots, err
+}
+
+// GetDiff 获取执行前后建表语句的 unified diff
+func (s *SnapshotService) GetDiff(executionID string) (*SchemaDiffResponse, error) {
+	before, err := s.GetSnapshot(executionID, models.SnapshotBefore)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("未找到执行前快照")
+		}
+		return nil, err
+	}
+
+	response := &SchemaDiffResponse{
+		ExecutionID: executionID,
+		Before:      before,
+	}
+
+	after, err := s.GetSnapshot(executionID, models.SnapshotAfter)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// 尚未完成，仅返回执行前快照
+			return response, nil
+		}
+		return nil, err
+	}
+
+	response.After = after
+	response.Diff = utils.UnifiedDiff(
+		fmt.Sprintf("%s.%s (before)", before.DatabaseName, before.TargetTableName),
+		fmt.Sprintf("%s.%s (after)", after.DatabaseName, after.TargetTableName),
+		autoIncrementPattern.ReplaceAllString(before.CreateTableSQL, ""),
+		autoIncrementPattern.ReplaceAllString(after.CreateTableSQL, ""),
+		3,
+	)
+	response.Changed = response.Diff != ""
+
+	return response, nil
+}
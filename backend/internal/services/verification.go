@@ -0,0 +1,140 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fengzhencai/MySQLer/backend/internal/models"
+	"github.com/fengzhencai/MySQLer/backend/internal/utils"
+)
+
+// VerificationReport 执行后校验结果
+type VerificationReport struct {
+	Passed     bool                      `json:"passed"`
+	Checks     []utils.VerificationCheck `json:"checks"`
+	VerifiedAt time.Time                 `json:"verified_at"`
+}
+
+// add 追加校验项，未跳过且未通过时整体判为不通过
+func (r *VerificationReport) add(checks ...utils.VerificationCheck) {
+	for _, check := range checks {
+		if !check.Passed && !check.Skipped {
+			r.Passed = false
+		}
+		r.Checks = append(r.Checks, check)
+	}
+}
+
+// verifyExecution 执行完成后校验表结构、新旧表行数及（可选）分块校验和
+func (e *ExecutionEngine) verifyExecution(task *ExecutionTask, dbConn *utils.DatabaseConnection, before, after *models.SchemaSnapshot) *VerificationReport {
+	record := task.Record
+	report := &VerificationReport{Passed: true, Checks: []utils.VerificationCheck{}, VerifiedAt: time.Now()}
+
+	beforeDef := parseSnapshot(before)
+	afterDef := parseSnapshot(after)
+
+	// 1. 执行后表结构包含 ALTER 中的变更
+	if record.OriginalDDL != nil && *record.OriginalDDL != "" {
+		if afterDef == nil {
+			report.add(utils.VerificationCheck{Kind: utils.VerifySchema, Target: record.TargetTableName, Message: "无法获取执行后的表结构"})
+		} else if checks, err := utils.VerifySchemaChanges(beforeDef, afterDef, *record.OriginalDDL); err != nil {
+			report.add(utils.VerificationCheck{Kind: utils.VerifySchema, Target: record.TargetTableName, Passed: true, Skipped: true,
+				Message: fmt.Sprintf("无法解析 ALTER 语句: %v", err)})
+		} else {
+			report.add(checks...)
+		}
+	}
+
+	// 2. 保留旧表时比较新旧表行数
+	oldTable := ""
+	if record.OldTableName != nil && record.OldTableStatus != nil && *record.OldTableStatus == models.OldTableKept {
+		oldTable = *record.OldTableName
+	}
+	if oldTable != "" {
+		check, err := utils.CompareRowCounts(dbConn, record.DatabaseName, oldTable, record.TargetTableName, stablePrimaryKey(beforeDef, afterDef))
+		if err != nil {
+			check = &utils.VerificationCheck{Kind: utils.VerifyRowCount, Target: oldTable, Message: err.Error()}
+		}
+		report.add(*check)
+	}
+
+	// 3. 可选：按主键分块比较校验和
+	if record.ExecutionParams != nil && record.ExecutionParams.VerifyChecksum {
+		report.add(e.compareChecksums(task, dbConn, oldTable, beforeDef, afterDef))
+	}
+
+	for _, check := range report.Checks {
+		if !check.Passed && !check.Skipped {
+			e.logLine(task, fmt.Sprintf("[%s] 校验不一致(%s): %s %s", time.Now().Format("15:04:05"), check.Kind, check.Target, check.Message))
+		}
+	}
+
+	if raw, err := json.Marshal(report); err == nil {
+		payload := json.RawMessage(raw)
+		record.Verification = &payload
+	}
+	return report
+}
+
+// compareChecksums 比较新旧表中结构未变化的列的校验和
+func (e *ExecutionEngine) compareChecksums(task *ExecutionTask, dbConn *utils.DatabaseConnection, oldTable string, before, after *utils.TableDefinition) utils.VerificationCheck {
+	record := task.Record
+	skipped := func(message string) utils.VerificationCheck {
+		return utils.VerificationCheck{Kind: utils.VerifyChecksum, Target: record.TargetTableName, Passed: true, Skipped: true, Message: message}
+	}
+	if oldTable == "" {
+		return skipped("未保留旧表，无法比较校验和")
+	}
+	if before == nil || after == nil {
+		return skipped("缺少执行前后的表结构，无法确定比较列")
+	}
+
+	pk := stablePrimaryKey(before, after)
+	if pk == nil {
+		return skipped("表没有主键或主键发生变化，跳过校验和比较")
+	}
+	var columns []string
+	for _, column := range after.Columns {
+		if previous := before.FindColumn(column.Name); previous != nil && previous.Definition == column.Definition {
+			columns = append(columns, column.Name)
+		}
+	}
+
+	e.updateStage(task, "比较新旧表校验和")
+	check, err := utils.CompareChecksums(dbConn, record.DatabaseName, oldTable, record.TargetTableName, columns, pk, e.cfg.VerifyChecksumChunkSize)
+	if err != nil {
+		return utils.VerificationCheck{Kind: utils.VerifyChecksum, Target: oldTable, Message: err.Error()}
+	}
+	return *check
+}
+
+// stablePrimaryKey 执行前后未变化的主键列，缺少表结构或主键变化时返回 nil
+func stablePrimaryKey(before, after *utils.TableDefinition) []string {
+	if before == nil || after == nil {
+		return nil
+	}
+	beforePK, afterPK := before.PrimaryKey(), after.PrimaryKey()
+	if beforePK == nil || afterPK == nil || len(beforePK.Columns) != len(afterPK.Columns) {
+		return nil
+	}
+	for i := range afterPK.Columns {
+		if !strings.EqualFold(beforePK.Columns[i], afterPK.Columns[i]) {
+			return nil
+		}
+	}
+	return afterPK.Columns
+}
+
+// parseSnapshot 解析快照中的建表语句，失败时返回 nil
+func parseSnapshot(snapshot *models.SchemaSnapshot) *utils.TableDefinition {
+	if snapshot == nil || snapshot.CreateTableSQL == "" {
+		return nil
+	}
+	def, err := utils.ParseCreateTable(snapshot.CreateTableSQL)
+	if err != nil {
+		return nil
+	}
+	return def
+}
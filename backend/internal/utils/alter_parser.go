@@ -0,0 +1,180 @@
+package utils
+
+import (
+	"strings"
+)
+
+// ALTER 子句类型
+const (
+	AlterAddColumn      = "add_column"
+	AlterDropColumn     = "drop_column"
+	AlterModifyColumn   = "modify_column"
+	AlterChangeColumn   = "change_column"
+	AlterColumnDefault  = "alter_column_default"
+	AlterRenameColumn   = "rename_column"
+	AlterAddIndex       = "add_index"
+	AlterDropIndex      = "drop_index"
+	AlterRenameIndex    = "rename_index"
+	AlterAddPrimaryKey  = "add_primary_key"
+	AlterDropPrimaryKey = "drop_primary_key"
+	AlterAddForeignKey  = "add_foreign_key"
+	AlterDropForeignKey = "drop_foreign_key"
+	AlterTableOption    = "table_option"
+	AlterConvertCharset = "convert_charset"
+	AlterUnknown        = "unknown"
+)
+
+// AlterClause 解析后的单个 ALTER 子句
+type AlterClause struct {
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`       // 列名/索引名/外键名/选项名
+	NewName    string `json:"new_name"`   // CHANGE/RENAME 的新名称
+	Definition string `json:"definition"` // 列定义、索引定义或选项值
+	Position   string `json:"position"`   // FIRST / AFTER `col`
+	Raw        string `json:"raw"`
+}
+
+// CleanAlterSQL 校验并规范化 ALTER 语句，返回去掉 "ALTER TABLE t" 前缀后的子句列表文本
+func CleanAlterSQL(alterSQL string) (string, error) {
+	b := &PTCommandBuilder{}
+	return b.validateAndCleanAlterSQL(alterSQL)
+}
+
+// ParseAlterClauses 将已规范化的 ALTER 子句文本拆分并识别类型
+func ParseAlterClauses(clauses string) []*AlterClause {
+	var result []*AlterClause
+	for _, raw := range splitTopLevel(clauses, ',') {
+		if raw == "" {
+			continue
+		}
+		result = append(result, parseAlterClause(raw))
+	}
+	return result
+}
+
+// parseAlterClause 识别单个子句
+func parseAlterClause(raw string) *AlterClause {
+	clause := &AlterClause{Kind: AlterUnknown, Raw: raw}
+	s := strings.TrimSpace(raw)
+
+	switch {
+	case hasPrefixFold(s, "ADD"):
+		rest := trimPrefixFold(s, "ADD")
+		if index := parseIndexElement(rest); index != nil {
+			if index.Kind == IndexKindPrimary {
+				clause.Kind = AlterAddPrimaryKey
+			} else {
+				clause.Kind = AlterAddIndex
+			}
+			clause.Name = index.Name
+			clause.Definition = index.Definition
+			return clause
+		}
+		if isConstraintElement(rest) {
+			clause.Definition = rest
+			if !strings.Contains(strings.ToUpper(rest), "FOREIGN KEY") {
+				// CHECK 等约束
+				return clause
+			}
+			clause.Kind = AlterAddForeignKey
+			if symbol := trimPrefixFold(rest, "CONSTRAINT"); symbol != rest && !hasPrefixFold(symbol, "FOREIGN") {
+				clause.Name, _ = readIdentifier(symbol)
+			}
+			return clause
+		}
+		rest = trimPrefixFold(rest, "COLUMN")
+		if strings.HasPrefix(rest, "(") {
+			// ADD (col1 ..., col2 ...) 形式暂不拆分
+			return clause
+		}
+		clause.Kind = AlterAddColumn
+		clause.Name, clause.Definition = readIdentifier(rest)
+		clause.Definition, clause.Position = splitColumnPosition(clause.Definition)
+
+	case hasPrefixFold(s, "DROP"):
+		rest := trimPrefixFold(s, "DROP")
+		switch {
+		case hasPrefixFold(rest, "PRIMARY"):
+			clause.Kind = AlterDropPrimaryKey
+			clause.Name = "PRIMARY"
+		case hasPrefixFold(rest, "FOREIGN"):
+			clause.Kind = AlterDropForeignKey
+			clause.Name, _ = readIdentifier(trimPrefixFold(trimPrefixFold(rest, "FOREIGN"), "KEY"))
+		case hasPrefixFold(rest, "INDEX"), hasPrefixFold(rest, "KEY"):
+			clause.Kind = AlterDropIndex
+			clause.Name, _ = readIdentifier(trimPrefixFold(trimPrefixFold(rest, "INDEX"), "KEY"))
+		default:
+			clause.Kind = AlterDropColumn
+			clause.Name, _ = readIdentifier(trimPrefixFold(rest, "COLUMN"))
+		}
+
+	case hasPrefixFold(s, "MODIFY"):
+		clause.Kind = AlterModifyColumn
+		clause.Name, clause.Definition = readIdentifier(trimPrefixFold(trimPrefixFold(s, "MODIFY"), "COLUMN"))
+		clause.Definition, clause.Position = splitColumnPosition(clause.Definition)
+
+	case hasPrefixFold(s, "CHANGE"):
+		clause.Kind = AlterChangeColumn
+		var rest string
+		clause.Name, rest = readIdentifier(trimPrefixFold(trimPrefixFold(s, "CHANGE"), "COLUMN"))
+		clause.NewName, clause.Definition = readIdentifier(rest)
+		clause.Definition, clause.Position = splitColumnPosition(clause.Definition)
+
+	case hasPrefixFold(s, "ALTER"):
+		rest := trimPrefixFold(trimPrefixFold(s, "ALTER"), "COLUMN")
+		clause.Name, clause.Definition = readIdentifier(rest)
+		if hasPrefixFold(clause.Definition, "SET") || hasPrefixFold(clause.Definition, "DROP") {
+			clause.Kind = AlterColumnDefault
+		}
+
+	case hasPrefixFold(s, "RENAME"):
+		rest := trimPrefixFold(s, "RENAME")
+		switch {
+		case hasPrefixFold(rest, "COLUMN"):
+			clause.Kind = AlterRenameColumn
+			clause.Name, clause.NewName = parseRenamePair(trimPrefixFold(rest, "COLUMN"))
+		case hasPrefixFold(rest, "INDEX"), hasPrefixFold(rest, "KEY"):
+			clause.Kind = AlterRenameIndex
+			clause.Name, clause.NewName = parseRenamePair(trimPrefixFold(trimPrefixFold(rest, "INDEX"), "KEY"))
+		}
+
+	case hasPrefixFold(s, "CONVERT"):
+		clause.Kind = AlterConvertCharset
+		clause.Definition = s
+
+	default:
+		def := &TableDefinition{Options: map[string]string{}}
+		parseTableOptions(s, def)
+		if len(def.Options) == 1 {
+			for key, value := range def.Options {
+				clause.Kind = AlterTableOption
+				clause.Name = key
+				clause.Definition = value
+			}
+		}
+	}
+
+	return clause
+}
+
+// splitColumnPosition 拆出列定义末尾的 FIRST / AFTER `col`
+func splitColumnPosition(definition string) (string, string) {
+	upper := strings.ToUpper(definition)
+	if strings.HasSuffix(upper, " FIRST") {
+		return strings.TrimSpace(definition[:len(definition)-len(" FIRST")]), "FIRST"
+	}
+	if idx := strings.LastIndex(upper, " AFTER "); idx >= 0 {
+		name, rest := readIdentifier(definition[idx+len(" AFTER "):])
+		if rest == "" && name != "" {
+			return strings.TrimSpace(definition[:idx]), "AFTER " + QuoteIdentifier(name)
+		}
+	}
+	return definition, ""
+}
+
+// parseRenamePair 解析 "a TO b"
+func parseRenamePair(s string) (string, string) {
+	from, rest := readIdentifier(s)
+	to, _ := readIdentifier(trimPrefixFold(rest, "TO"))
+	return from, to
+}
@@ -0,0 +1,249 @@
+package utils
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// protectedUsers 系统线程使用的账号（复制、事件调度等），不允许终止
+var protectedUsers = map[string]bool{
+	"system user":     true,
+	"event_scheduler": true,
+}
+
+// protectedCommands 复制与后台线程的命令类型，不允许终止
+var protectedCommands = map[string]bool{
+	"binlog dump":      true,
+	"binlog dump gtid": true,
+	"daemon":           true,
+	"register slave":   true,
+	"register replica": true,
+}
+
+// BlockingSession 阻塞目标表切换的会话
+type BlockingSession struct {
+	ThreadID     int64  `json:"thread_id"`
+	User         string `json:"user"`
+	Host         string `json:"host"`
+	DB           string `json:"db"`
+	Command      string `json:"command"`
+	Time         int64  `json:"time"`
+	State        string `json:"state"`
+	Query        string `json:"query"`
+	TrxSeconds   *int64 `json:"trx_seconds,omitempty"`   // 无活动事务时为空
+	MetadataLock string `json:"metadata_lock,omitempty"` // 持有的目标表元数据锁类型
+	Reason       string `json:"reason"`                  // 被判定为阻塞的原因
+
+	Killable  bool   `json:"killable"`
+	Protected string `json:"protected,omitempty"` // 不可终止的原因
+}
+
+// BlockingSessionList 目标表上的阻塞会话
+type BlockingSessionList struct {
+	Database               string            `json:"database"`
+	Table                  string            `json:"table"`
+	MetadataLocksAvailable bool              `json:"metadata_locks_available"`
+	WaitingSessions        int               `json:"waiting_sessions"` // 正在等待该表元数据锁的会话数
+	Sessions               []BlockingSession `json:"sessions"`
+}
+
+// ListBlockingSessions 列出阻塞目标表切换的会话
+// metadata_locks 可用时取持有该表元数据锁的会话，否则回退为运行超过 minTrxSeconds 的事务
+func ListBlockingSessions(conn *DatabaseConnection, database, table string, minTrxSeconds int) (*BlockingSessionList, error) {
+	db, err := openDatabase(conn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	ctx, cancel := createTimeoutContext(conn.ConnectTimeout)
+	defer cancel()
+
+	var self int64
+	if err := db.QueryRowContext(ctx, "SELECT CONNECTION_ID()").Scan(&self); err != nil {
+		return nil, err
+	}
+
+	processes, err := processList(ctx, db, 0)
+	if err != nil {
+		return nil, err
+	}
+	transactions, err := transactionAges(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BlockingSessionList{
+		Database: database,
+		Table:    table,
+		Sessions: []BlockingSession{},
+	}
+
+	locks, available := grantedMetadataLocks(ctx, db, database, table)
+	result.MetadataLocksAvailable = available
+	for _, session := range processes {
+		if session.ThreadID == self {
+			continue
+		}
+		if seconds, ok := transactions[session.ThreadID]; ok {
+			s := seconds
+			session.TrxSeconds = &s
+		}
+
+		switch {
+		case available && locks[session.ThreadID] != "":
+			session.MetadataLock = locks[session.ThreadID]
+			session.Reason = fmt.Sprintf("持有 %s.%s 的 %s 元数据锁", database, table, session.MetadataLock)
+		case !available && session.TrxSeconds != nil && *session.TrxSeconds >= int64(minTrxSeconds):
+			session.Reason = fmt.Sprintf("事务已持续 %s（metadata_locks 不可用，无法确认是否涉及目标表）", FormatDuration(*session.TrxSeconds))
+		default:
+			if session.State == "Waiting for table metadata lock" && strings.Contains(session.Query, table) {
+				result.WaitingSessions++
+			}
+			continue
+		}
+		result.Sessions = append(result.Sessions, session)
+	}
+	return result, nil
+}
+
+// KillSession 终止指定会话；复制线程、系统线程及当前连接不允许终止
+// 返回终止前的会话信息（含正在执行的语句）
+func KillSession(conn *DatabaseConnection, threadID int64) (*BlockingSession, error) {
+	db, err := openDatabase(conn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	ctx, cancel := createTimeoutContext(conn.ConnectTimeout)
+	defer cancel()
+
+	processes, err := processList(ctx, db, threadID)
+	if err != nil {
+		return nil, err
+	}
+	if len(processes) == 0 {
+		return nil, fmt.Errorf("会话 %d 不存在或已结束", threadID)
+	}
+	session := processes[0]
+
+	var self int64
+	if err := db.QueryRowContext(ctx, "SELECT CONNECTION_ID()").Scan(&self); err != nil {
+		return nil, err
+	}
+	if session.ThreadID == self {
+		return &session, fmt.Errorf("不能终止当前连接")
+	}
+	if !session.Killable {
+		return &session, fmt.Errorf("会话 %d 为%s，不允许终止", threadID, session.Protected)
+	}
+
+	// KILL 不支持占位符，threadID 为整数可直接拼接
+	if _, err := db.ExecContext(ctx, fmt.Sprintf("KILL %d", threadID)); err != nil {
+		return &session, fmt.Errorf("终止会话 %d 失败: %v", threadID, err)
+	}
+	return &session, nil
+}
+
+// processList 读取 processlist（threadID 为0时返回全部会话），并标记受保护的系统线程
+func processList(ctx context.Context, db *sql.DB, threadID int64) ([]BlockingSession, error) {
+	query := `SELECT id, IFNULL(user, ''), IFNULL(host, ''), IFNULL(db, ''), IFNULL(command, ''),
+		IFNULL(time, 0), IFNULL(state, ''), IFNULL(info, '')
+		FROM information_schema.processlist`
+	var args []interface{}
+	if threadID > 0 {
+		query += " WHERE id = ?"
+		args = append(args, threadID)
+	}
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询会话列表失败: %v", err)
+	}
+	defer rows.Close()
+
+	var sessions []BlockingSession
+	for rows.Next() {
+		var session BlockingSession
+		if err := rows.Scan(&session.ThreadID, &session.User, &session.Host, &session.DB, &session.Command,
+			&session.Time, &session.State, &session.Query); err != nil {
+			return nil, err
+		}
+		session.Protected = protectedReason(&session)
+		session.Killable = session.Protected == ""
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+// protectedReason 判断会话是否为复制或系统线程，返回不可终止的原因
+func protectedReason(session *BlockingSession) string {
+	state := strings.ToLower(session.State)
+	switch {
+	case protectedUsers[strings.ToLower(session.User)]:
+		return "系统线程"
+	case protectedCommands[strings.ToLower(session.Command)]:
+		return "复制线程"
+	case strings.Contains(state, "master") || strings.Contains(state, "source") ||
+		strings.Contains(state, "slave") || strings.Contains(state, "replica") || strings.Contains(state, "relay log"):
+		return "复制线程"
+	}
+	return ""
+}
+
+// transactionAges 活动事务的持续秒数（按会话ID）
+func transactionAges(ctx context.Context, db *sql.DB) (map[int64]int64, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT trx_mysql_thread_id, TIMESTAMPDIFF(SECOND, trx_started, NOW())
+		FROM information_schema.innodb_trx`)
+	if err != nil {
+		return nil, fmt.Errorf("查询活动事务失败: %v", err)
+	}
+	defer rows.Close()
+
+	ages := map[int64]int64{}
+	for rows.Next() {
+		var id, seconds int64
+		if err := rows.Scan(&id, &seconds); err != nil {
+			return nil, err
+		}
+		ages[id] = seconds
+	}
+	return ages, rows.Err()
+}
+
+// grantedMetadataLocks 已获得目标表元数据锁的会话及锁类型
+func grantedMetadataLocks(ctx context.Context, db *sql.DB, database, table string) (map[int64]string, bool) {
+	var enabled string
+	err := db.QueryRowContext(ctx,
+		"SELECT enabled FROM performance_schema.setup_instruments WHERE name = ?", mdlInstrument).Scan(&enabled)
+	if err != nil || !strings.EqualFold(enabled, "YES") {
+		return nil, false
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT t.processlist_id, ml.lock_type
+		FROM performance_schema.metadata_locks ml
+		JOIN performance_schema.threads t ON t.thread_id = ml.owner_thread_id
+		WHERE ml.object_type = 'TABLE' AND ml.object_schema = ? AND ml.object_name = ?
+			AND ml.lock_status = 'GRANTED' AND t.processlist_id IS NOT NULL`,
+		database, table)
+	if err != nil {
+		return nil, false
+	}
+	defer rows.Close()
+
+	locks := map[int64]string{}
+	for rows.Next() {
+		var id int64
+		var lockType string
+		if err := rows.Scan(&id, &lockType); err == nil {
+			locks[id] = lockType
+		}
+	}
+	return locks, true
+}
@@ -0,0 +1,493 @@
+package utils
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 列备份文件格式
+const (
+	BackupFormatCSV = "csv" // CSV，末尾 __null 列逐列标记 NULL
+	BackupFormatSQL = "sql" // 每行一条 INSERT 语句
+)
+
+// csvNullColumn CSV 末尾的 NULL 标记列，值为每列一位的 0/1 串（1 表示 NULL）
+const csvNullColumn = "__null"
+
+// ColumnBackupResult 列备份结果
+type ColumnBackupResult struct {
+	FilePath string `json:"file_path"`
+	Checksum string `json:"checksum"` // 压缩文件的 sha256
+	FileSize int64  `json:"file_size"`
+	Rows     int64  `json:"rows"`
+}
+
+// BackupColumns 流式导出主键与指定列到 gzip 压缩文件
+func BackupColumns(conn *DatabaseConnection, database, table string, keyColumns, columns []string, format, path string) (*ColumnBackupResult, error) {
+	if len(keyColumns) == 0 {
+		return nil, fmt.Errorf("表 %s 缺少主键，无法备份列数据", table)
+	}
+	if format != BackupFormatCSV && format != BackupFormatSQL {
+		return nil, fmt.Errorf("不支持的备份格式: %s", format)
+	}
+
+	db, err := openDatabase(conn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	allColumns := append(append([]string{}, keyColumns...), columns...)
+	quoted := make([]string, len(allColumns))
+	for i, c := range allColumns {
+		quoted[i] = QuoteIdentifier(c)
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s.%s", strings.Join(quoted, ", "), QuoteIdentifier(database), QuoteIdentifier(table))
+
+	// 导出可能耗时较长，不设置查询超时
+	rows, err := db.QueryContext(context.Background(), query)
+	if err != nil {
+		return nil, fmt.Errorf("读取列数据失败: %v", err)
+	}
+	defer rows.Close()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return nil, fmt.Errorf("创建备份目录失败: %v", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0640)
+	if err != nil {
+		return nil, fmt.Errorf("创建备份文件失败: %v", err)
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	counter := &countingWriter{w: io.MultiWriter(file, hasher)}
+	gz := gzip.NewWriter(counter)
+
+	count, err := writeBackupRows(rows, gz, format, table, allColumns)
+	if err != nil {
+		gz.Close()
+		os.Remove(path)
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		os.Remove(path)
+		return nil, fmt.Errorf("写入备份文件失败: %v", err)
+	}
+
+	return &ColumnBackupResult{
+		FilePath: path,
+		Checksum: hex.EncodeToString(hasher.Sum(nil)),
+		FileSize: counter.n,
+		Rows:     count,
+	}, nil
+}
+
+// writeBackupRows 按格式写出查询结果
+func writeBackupRows(rows *sql.Rows, w io.Writer, format, table string, columns []string) (int64, error) {
+	values := make([]sql.RawBytes, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	var csvWriter *csv.Writer
+	var sqlWriter *bufio.Writer
+	var insertPrefix string
+	if format == BackupFormatCSV {
+		csvWriter = csv.NewWriter(w)
+		if err := csvWriter.Write(append(append([]string{}, columns...), csvNullColumn)); err != nil {
+			return 0, fmt.Errorf("写入备份文件失败: %v", err)
+		}
+	} else {
+		sqlWriter = bufio.NewWriter(w)
+		quoted := make([]string, len(columns))
+		for i, c := range columns {
+			quoted[i] = QuoteIdentifier(c)
+		}
+		insertPrefix = fmt.Sprintf("INSERT INTO %s (%s) VALUES (", QuoteIdentifier(table), strings.Join(quoted, ", "))
+	}
+
+	var count int64
+	record := make([]string, len(columns))
+	nullFlags := make([]byte, len(columns))
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return count, fmt.Errorf("读取列数据失败: %v", err)
+		}
+
+		if csvWriter != nil {
+			for i, v := range values {
+				if v == nil {
+					record[i] = ""
+					nullFlags[i] = '1'
+				} else {
+					record[i] = string(v)
+					nullFlags[i] = '0'
+				}
+			}
+			if err := csvWriter.Write(append(record, string(nullFlags))); err != nil {
+				return count, fmt.Errorf("写入备份文件失败: %v", err)
+			}
+		} else {
+			for i, v := range values {
+				if v == nil {
+					record[i] = "NULL"
+				} else {
+					record[i] = quoteSQLString(string(v))
+				}
+			}
+			if _, err := sqlWriter.WriteString(insertPrefix + strings.Join(record, ", ") + ");\n"); err != nil {
+				return count, fmt.Errorf("写入备份文件失败: %v", err)
+			}
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, fmt.Errorf("读取列数据失败: %v", err)
+	}
+
+	if csvWriter != nil {
+		csvWriter.Flush()
+		if err := csvWriter.Error(); err != nil {
+			return count, fmt.Errorf("写入备份文件失败: %v", err)
+		}
+	} else if err := sqlWriter.Flush(); err != nil {
+		return count, fmt.Errorf("写入备份文件失败: %v", err)
+	}
+
+	return count, nil
+}
+
+// ReadColumnBackup 逐行读取备份文件，回调参数为列名与对应值（nil 表示 NULL）
+func ReadColumnBackup(path, format string, fn func(columns []string, values []*string) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("打开备份文件失败: %v", err)
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("读取备份文件失败: %v", err)
+	}
+	defer gz.Close()
+
+	if format == BackupFormatCSV {
+		reader := csv.NewReader(gz)
+		columns, err := reader.Read()
+		if err != nil {
+			return fmt.Errorf("读取备份文件头失败: %v", err)
+		}
+		if len(columns) == 0 || columns[len(columns)-1] != csvNullColumn {
+			return fmt.Errorf("备份文件格式错误: 缺少 NULL 标记列")
+		}
+		columns = columns[:len(columns)-1]
+		for {
+			record, err := reader.Read()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("读取备份文件失败: %v", err)
+			}
+			values := make([]*string, len(columns))
+			flags := record[len(record)-1]
+			if len(record) != len(columns)+1 || len(flags) != len(columns) {
+				return fmt.Errorf("备份文件格式错误: NULL 标记与列数不符")
+			}
+			for i := range columns {
+				if flags[i] != '1' {
+					values[i] = &record[i]
+				}
+			}
+			if err := fn(columns, values); err != nil {
+				return err
+			}
+		}
+	}
+
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		columns, values, err := parseInsertLine(line)
+		if err != nil {
+			return err
+		}
+		if err := fn(columns, values); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("读取备份文件失败: %v", err)
+	}
+	return nil
+}
+
+// FileChecksum 计算文件的 sha256
+func FileChecksum(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// parseInsertLine 解析备份文件中的单行 INSERT 语句
+func parseInsertLine(line string) ([]string, []*string, error) {
+	open := strings.Index(line, "(")
+	if open < 0 {
+		return nil, nil, fmt.Errorf("无法解析备份行: %s", line)
+	}
+	closeIdx := matchParen(line, open)
+	if closeIdx < 0 {
+		return nil, nil, fmt.Errorf("无法解析备份行: %s", line)
+	}
+	var columns []string
+	for _, c := range splitTopLevel(line[open+1:closeIdx], ',') {
+		columns = append(columns, unquoteIdentifier(c))
+	}
+
+	rest := line[closeIdx+1:]
+	valuesOpen := strings.Index(rest, "(")
+	if valuesOpen < 0 {
+		return nil, nil, fmt.Errorf("无法解析备份行: %s", line)
+	}
+	valuesClose := matchParen(rest, valuesOpen)
+	if valuesClose < 0 {
+		return nil, nil, fmt.Errorf("无法解析备份行: %s", line)
+	}
+	parts := splitTopLevel(rest[valuesOpen+1:valuesClose], ',')
+	if len(parts) != len(columns) {
+		return nil, nil, fmt.Errorf("备份行列数不匹配: %s", line)
+	}
+
+	values := make([]*string, len(parts))
+	for i, part := range parts {
+		if strings.EqualFold(part, "NULL") {
+			continue
+		}
+		value := unquoteSQLString(part)
+		values[i] = &value
+	}
+	return columns, values, nil
+}
+
+// quoteSQLString 将字符串转义为 SQL 字面量
+func quoteSQLString(s string) string {
+	var sb strings.Builder
+	sb.Grow(len(s) + 2)
+	sb.WriteByte('\'')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case 0:
+			sb.WriteString(`\0`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case 0x1a:
+			sb.WriteString(`\Z`)
+		case '\'', '\\':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	sb.WriteByte('\'')
+	return sb.String()
+}
+
+// unquoteSQLString 还原 quoteSQLString 生成的字面量
+func unquoteSQLString(s string) string {
+	if len(s) < 2 || s[0] != '\'' || s[len(s)-1] != '\'' {
+		return s
+	}
+	s = s[1 : len(s)-1]
+	var sb strings.Builder
+	sb.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i+1 >= len(s) {
+			sb.WriteByte(c)
+			continue
+		}
+		i++
+		switch s[i] {
+		case '0':
+			sb.WriteByte(0)
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 'Z':
+			sb.WriteByte(0x1a)
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return sb.String()
+}
+
+// countingWriter 统计写入字节数
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// RestoreColumns 将备份文件中的列值按主键写回表中（mapping: 备份列名 -> 目标列名）
+func RestoreColumns(conn *DatabaseConnection, database, table string, keyColumns []string, mapping map[string]string, path, format string, batchSize int, progress func(int64)) (int64, error) {
+	if batchSize <= 0 {
+		batchSize = 1000
+	}
+
+	db, err := openDatabase(conn)
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	var (
+		tx        *sql.Tx
+		stmt      *sql.Stmt
+		keyIdx    []int
+		valueIdx  []int
+		restored  int64
+		batchRows int
+		updateSQL string
+	)
+
+	commit := func() error {
+		if tx == nil {
+			return nil
+		}
+		stmt.Close()
+		err := tx.Commit()
+		tx, stmt = nil, nil
+		batchRows = 0
+		return err
+	}
+
+	err = ReadColumnBackup(path, format, func(columns []string, values []*string) error {
+		// 首行：根据列名构建 UPDATE 语句
+		if keyIdx == nil {
+			var sets, conds []string
+			for _, key := range keyColumns {
+				idx := indexOf(columns, key)
+				if idx < 0 {
+					return fmt.Errorf("备份文件缺少主键列 %s", key)
+				}
+				keyIdx = append(keyIdx, idx)
+				conds = append(conds, QuoteIdentifier(key)+" = ?")
+			}
+			for i, c := range columns {
+				target, ok := mapping[c]
+				if !ok || indexOf(keyColumns, c) >= 0 {
+					continue
+				}
+				valueIdx = append(valueIdx, i)
+				sets = append(sets, QuoteIdentifier(target)+" = ?")
+			}
+			if len(sets) == 0 {
+				return fmt.Errorf("没有需要恢复的列")
+			}
+			updateSQL = fmt.Sprintf("UPDATE %s.%s SET %s WHERE %s",
+				QuoteIdentifier(database), QuoteIdentifier(table), strings.Join(sets, ", "), strings.Join(conds, " AND "))
+		}
+
+		if tx == nil {
+			var err error
+			if tx, err = db.Begin(); err != nil {
+				return fmt.Errorf("开启事务失败: %v", err)
+			}
+			if stmt, err = tx.Prepare(updateSQL); err != nil {
+				tx.Rollback()
+				tx = nil
+				return fmt.Errorf("准备更新语句失败: %v", err)
+			}
+		}
+
+		args := make([]interface{}, 0, len(valueIdx)+len(keyIdx))
+		for _, i := range valueIdx {
+			args = append(args, nullableValue(values[i]))
+		}
+		for _, i := range keyIdx {
+			args = append(args, nullableValue(values[i]))
+		}
+		if _, err := stmt.Exec(args...); err != nil {
+			return fmt.Errorf("写回列数据失败: %v", err)
+		}
+
+		restored++
+		batchRows++
+		if batchRows >= batchSize {
+			if err := commit(); err != nil {
+				return fmt.Errorf("提交事务失败: %v", err)
+			}
+			if progress != nil {
+				progress(restored)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if tx != nil {
+			stmt.Close()
+			tx.Rollback()
+		}
+		return restored - int64(batchRows), err
+	}
+
+	if err := commit(); err != nil {
+		return restored - int64(batchRows), fmt.Errorf("提交事务失败: %v", err)
+	}
+	if progress != nil {
+		progress(restored)
+	}
+	return restored, nil
+}
+
+// nullableValue 将 *string 转换为 SQL 参数
+func nullableValue(v *string) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+// indexOf 查找字符串位置（忽略大小写）
+func indexOf(list []string, s string) int {
+	for i, v := range list {
+		if strings.EqualFold(v, s) {
+			return i
+		}
+	}
+	return -1
+}
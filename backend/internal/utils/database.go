@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/go-sql-driver/mysql"
@@ -20,6 +21,13 @@ type DatabaseConnection struct {
 	ConnectTimeout int
 	Charset        string
 	UseSSL         bool
+
+	// TLS配置
+	SSLMode       string // disabled/preferred/required/verify_ca/verify_identity
+	SSLCA         string // CA证书（PEM）
+	SSLCert       string // 客户端证书（PEM）
+	SSLKey        string // 客户端私钥（PEM）
+	SSLServerName string // 校验证书时使用的服务端名称
 }
 
 // TestConnection 测试数据库连接
@@ -29,7 +37,10 @@ func TestConnection(conn *DatabaseConnection) error {
 	}
 
 	// 构建DSN
-	dsn := buildDSN(conn)
+	dsn, err := buildDSN(conn)
+	if err != nil {
+		return err
+	}
 
 	// 连接数据库
 	db, err := sql.Open("mysql", dsn)
@@ -57,7 +68,10 @@ func TestConnection(conn *DatabaseConnection) error {
 
 // GetDatabaseInfo 获取数据库基本信息
 func GetDatabaseInfo(conn *DatabaseConnection) (map[string]interface{}, error) {
-	dsn := buildDSN(conn)
+	dsn, err := buildDSN(conn)
+	if err != nil {
+		return nil, err
+	}
 
 	db, err := sql.Open("mysql", dsn)
 	if err != nil {
@@ -99,7 +113,10 @@ func GetDatabaseInfo(conn *DatabaseConnection) (map[string]interface{}, error) {
 
 // GetDatabaseList 获取数据库列表
 func GetDatabaseList(conn *DatabaseConnection) ([]string, error) {
-	dsn := buildDSN(conn)
+	dsn, err := buildDSN(conn)
+	if err != nil {
+		return nil, err
+	}
 
 	db, err := sql.Open("mysql", dsn)
 	if err != nil {
@@ -138,7 +155,10 @@ func GetDatabaseList(conn *DatabaseConnection) ([]string, error) {
 
 // GetTableList 获取指定数据库的表列表
 func GetTableList(conn *DatabaseConnection, database string) ([]map[string]interface{}, error) {
-	dsn := buildDSN(conn)
+	dsn, err := buildDSN(conn)
+	if err != nil {
+		return nil, err
+	}
 
 	db, err := sql.Open("mysql", dsn)
 	if err != nil {
@@ -203,7 +223,10 @@ func GetTableList(conn *DatabaseConnection, database string) ([]map[string]inter
 
 // GetTableSchema 获取指定表的列与索引结构
 func GetTableSchema(conn *DatabaseConnection, database string, table string) (map[string]interface{}, error) {
-	dsn := buildDSN(conn)
+	dsn, err := buildDSN(conn)
+	if err != nil {
+		return nil, err
+	}
 
 	db, err := sql.Open("mysql", dsn)
 	if err != nil {
@@ -311,7 +334,7 @@ func GetTableSchema(conn *DatabaseConnection, database string, table string) (ma
 }
 
 // buildDSN 构建MySQL DSN
-func buildDSN(conn *DatabaseConnection) string {
+func buildDSN(conn *DatabaseConnection) (string, error) {
 	config := mysql.Config{
 		User:   conn.Username,
 		Passwd: conn.Password,
@@ -329,11 +352,16 @@ func buildDSN(conn *DatabaseConnection) string {
 		AllowNativePasswords: true,
 	}
 
-	if conn.UseSSL {
-		config.TLSConfig = "true"
+	tlsName, err := registerTLSConfig(conn)
+	if err != nil {
+		return "", err
+	}
+	if tlsName != "" {
+		config.TLSConfig = tlsName
+		config.AllowFallbackToPlaintext = conn.TLSMode() == SSLModePreferred
 	}
 
-	return config.FormatDSN()
+	return config.FormatDSN(), nil
 }
 
 // createTimeoutContext 创建带超时的context
@@ -344,3 +372,33 @@ func createTimeoutContext(timeoutSeconds int) (context.Context, context.CancelFu
 	}
 	return context.WithTimeout(context.Background(), timeout)
 }
+
+// GetCreateTable 获取指定表的 SHOW CREATE TABLE 结果
+func GetCreateTable(conn *DatabaseConnection, database string, table string) (string, error) {
+	dsn, err := buildDSN(conn)
+	if err != nil {
+		return "", err
+	}
+
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return "", fmt.Errorf("创建数据库连接失败: %v", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := createTimeoutContext(conn.ConnectTimeout)
+	defer cancel()
+
+	var name, createSQL string
+	query := fmt.Sprintf("SHOW CREATE TABLE %s.%s", QuoteIdentifier(database), QuoteIdentifier(table))
+	if err := db.QueryRowContext(ctx, query).Scan(&name, &createSQL); err != nil {
+		return "", fmt.Errorf("获取建表语句失败: %v", err)
+	}
+
+	return createSQL, nil
+}
+
+// QuoteIdentifier 使用反引号包裹标识符（转义内部反引号）
+func QuoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
@@ -4,7 +4,9 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"time"
@@ -22,6 +24,8 @@ type PTContainerConfig struct {
 	MemoryLimit int64             `json:"memory_limit"`
 	NetworkMode string            `json:"network_mode"`
 	AutoRemove  bool              `json:"auto_remove"`
+	// 启动前经 docker cp 写入容器的文件（容器内路径 -> 内容），不出现在命令行与 docker inspect 中
+	Files map[string]string `json:"-"`
 }
 
 // PTContainerResult 容器执行结果
@@ -97,9 +101,41 @@ func (d *DockerService) CreatePTContainer(config *PTContainerConfig) (string, er
 	if containerID == "" {
 		return "", fmt.Errorf("empty container id from docker create")
 	}
+
+	if err := d.copyFiles(containerID, config.Files); err != nil {
+		_ = d.RemoveContainer(containerID, true)
+		return "", err
+	}
 	return containerID, nil
 }
 
+// copyFiles 将文件经宿主机临时目录（仅当前用户可访问）复制进容器，复制后删除临时文件
+func (d *DockerService) copyFiles(containerID string, files map[string]string) error {
+	if len(files) == 0 {
+		return nil
+	}
+
+	dir, err := os.MkdirTemp("", "mysqler-files-")
+	if err != nil {
+		return fmt.Errorf("创建临时目录失败: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for target, content := range files {
+		// 容器内以 root 属主写入，需对容器内的非 root 用户可读；宿主机上由 0700 临时目录保护
+		local := filepath.Join(dir, filepath.Base(target))
+		if err := os.WriteFile(local, []byte(content), 0644); err != nil {
+			return fmt.Errorf("写入临时文件失败: %v", err)
+		}
+		cmd := exec.Command(dockerBinary(), "cp", local, containerID+":"+target)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("docker cp failed: %v, output: %s", err, string(out))
+		}
+		_ = os.Remove(local)
+	}
+	return nil
+}
+
 // StartContainer 启动容器
 func (d *DockerService) StartContainer(containerID string) error {
 	cmd := exec.Command(dockerBinary(), "start", containerID)
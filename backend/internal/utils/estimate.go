@@ -0,0 +1,215 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// DefaultRowsPerSecond 无历史数据时假设的处理速度
+const DefaultRowsPerSecond = 1000
+
+// 估算来源
+const (
+	EstimateSourceHistory = "history" // 基于该连接的历史执行
+	EstimateSourceDefault = "default" // 无历史数据，使用默认速度
+)
+
+// 估算置信度
+const (
+	ConfidenceHigh   = "high"   // 样本 >= 10
+	ConfidenceMedium = "medium" // 样本 >= 3
+	ConfidenceLow    = "low"
+)
+
+// 参与估算的样本下限：过小的表主要耗时在容器启动与切换，速度不具代表性
+const (
+	minSampleRows    = 1000
+	minSampleSeconds = 1
+)
+
+// ThroughputSample 一次已完成执行的吞吐样本
+type ThroughputSample struct {
+	Rows      int64 `json:"rows"`
+	Seconds   int   `json:"seconds"`
+	ChunkSize int   `json:"chunk_size"`
+}
+
+// RowsPerSecond 样本的处理速度
+func (s ThroughputSample) RowsPerSecond() float64 {
+	return float64(s.Rows) / float64(s.Seconds)
+}
+
+// ChunkSizeStat 某一chunk-size下的历史表现
+type ChunkSizeStat struct {
+	ChunkSize     int     `json:"chunk_size"`
+	Samples       int     `json:"samples"`
+	RowsPerSecond float64 `json:"rows_per_second"` // 中位数
+}
+
+// ExecutionEstimate 执行时间估算
+type ExecutionEstimate struct {
+	Source        string  `json:"source"`
+	Confidence    string  `json:"confidence"`
+	SampleCount   int     `json:"sample_count"`
+	RowsPerSecond float64 `json:"rows_per_second"` // 估算采用的速度（中位数）
+
+	EstimatedSeconds int64  `json:"estimated_seconds"`
+	MinSeconds       int64  `json:"min_seconds"` // 置信区间下限
+	MaxSeconds       int64  `json:"max_seconds"` // 置信区间上限
+	EstimatedTime    string `json:"estimated_time"`
+	Range            string `json:"range"`
+
+	RecommendedChunkSize int             `json:"recommended_chunk_size"`
+	ChunkSizeSource      string          `json:"chunk_size_source"`
+	ChunkSizeStats       []ChunkSizeStat `json:"chunk_size_stats,omitempty"`
+}
+
+// EstimateExecution 基于历史吞吐样本估算执行时间并推荐chunk-size
+// 估算取样本速度中位数，区间取四分位（样本较少时至少放宽 ±20%/50%）；
+// chunk-size 取历史中位速度最高者，无可用样本时使用 fallbackChunkSize。
+func EstimateExecution(rows int64, samples []ThroughputSample, fallbackChunkSize int) *ExecutionEstimate {
+	var valid []ThroughputSample
+	for _, sample := range samples {
+		if sample.Rows >= minSampleRows && sample.Seconds >= minSampleSeconds {
+			valid = append(valid, sample)
+		}
+	}
+
+	estimate := &ExecutionEstimate{
+		Source:               EstimateSourceHistory,
+		SampleCount:          len(valid),
+		RecommendedChunkSize: fallbackChunkSize,
+		ChunkSizeSource:      EstimateSourceDefault,
+	}
+
+	var median, low, high float64
+	if len(valid) == 0 {
+		estimate.Source = EstimateSourceDefault
+		estimate.Confidence = ConfidenceLow
+		median = DefaultRowsPerSecond
+		low, high = median*0.5, median*2
+	} else {
+		speeds := make([]float64, 0, len(valid))
+		for _, sample := range valid {
+			speeds = append(speeds, sample.RowsPerSecond())
+		}
+		sort.Float64s(speeds)
+
+		median = percentile(speeds, 0.5)
+		low, high = percentile(speeds, 0.25), percentile(speeds, 0.75)
+
+		switch {
+		case len(valid) >= 10:
+			estimate.Confidence = ConfidenceHigh
+			low, high = math.Min(low, median*0.8), math.Max(high, median*1.2)
+		case len(valid) >= 3:
+			estimate.Confidence = ConfidenceMedium
+			low, high = math.Min(low, median*0.7), math.Max(high, median*1.3)
+		default:
+			estimate.Confidence = ConfidenceLow
+			low, high = math.Min(low, median*0.5), math.Max(high, median*1.5)
+		}
+
+		estimate.ChunkSizeStats = chunkSizeStats(valid)
+		if best := bestChunkSize(estimate.ChunkSizeStats); best > 0 {
+			estimate.RecommendedChunkSize = best
+			estimate.ChunkSizeSource = EstimateSourceHistory
+		}
+	}
+	estimate.RowsPerSecond = math.Round(median*100) / 100
+
+	if rows <= 0 {
+		estimate.EstimatedTime = "unknown"
+		estimate.Range = "unknown"
+		return estimate
+	}
+
+	estimate.EstimatedSeconds = secondsFor(rows, median)
+	estimate.MinSeconds = secondsFor(rows, high)
+	estimate.MaxSeconds = secondsFor(rows, low)
+	estimate.EstimatedTime = FormatDuration(estimate.EstimatedSeconds)
+	estimate.Range = fmt.Sprintf("%s ~ %s", FormatDuration(estimate.MinSeconds), FormatDuration(estimate.MaxSeconds))
+	return estimate
+}
+
+// FormatDuration 将秒数格式化为可读时长
+func FormatDuration(seconds int64) string {
+	if seconds < 60 {
+		return fmt.Sprintf("%d秒", seconds)
+	} else if seconds < 3600 {
+		return fmt.Sprintf("%d分钟", seconds/60)
+	}
+	hours := seconds / 3600
+	minutes := (seconds % 3600) / 60
+	return fmt.Sprintf("%d小时%d分钟", hours, minutes)
+}
+
+// chunkSizeStats 按chunk-size分组统计历史速度
+func chunkSizeStats(samples []ThroughputSample) []ChunkSizeStat {
+	grouped := make(map[int][]float64)
+	for _, sample := range samples {
+		if sample.ChunkSize > 0 {
+			grouped[sample.ChunkSize] = append(grouped[sample.ChunkSize], sample.RowsPerSecond())
+		}
+	}
+
+	stats := make([]ChunkSizeStat, 0, len(grouped))
+	for size, speeds := range grouped {
+		sort.Float64s(speeds)
+		stats = append(stats, ChunkSizeStat{
+			ChunkSize:     size,
+			Samples:       len(speeds),
+			RowsPerSecond: math.Round(percentile(speeds, 0.5)*100) / 100,
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].ChunkSize < stats[j].ChunkSize })
+	return stats
+}
+
+// bestChunkSize 选出中位速度最高的chunk-size；存在多次样本的取值优先于单次样本
+func bestChunkSize(stats []ChunkSizeStat) int {
+	best := -1
+	for i, stat := range stats {
+		if best < 0 {
+			best = i
+			continue
+		}
+		current := stats[best]
+		if (stat.Samples >= 2) != (current.Samples >= 2) {
+			if stat.Samples >= 2 {
+				best = i
+			}
+			continue
+		}
+		if stat.RowsPerSecond > current.RowsPerSecond {
+			best = i
+		}
+	}
+	if best < 0 {
+		return 0
+	}
+	return stats[best].ChunkSize
+}
+
+// percentile 计算已排序切片的分位数（线性插值）
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	pos := p * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+	if lower == upper {
+		return sorted[lower]
+	}
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(pos-float64(lower))
+}
+
+// secondsFor 按速度计算处理指定行数所需秒数
+func secondsFor(rows int64, rowsPerSecond float64) int64 {
+	if rowsPerSecond <= 0 {
+		return 0
+	}
+	return int64(math.Ceil(float64(rows) / rowsPerSecond))
+}
@@ -0,0 +1,152 @@
+package utils
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TableSpace 表空间占用（来自 information_schema.tables）
+type TableSpace struct {
+	Database    string  `json:"database"`
+	Table       string  `json:"table"`
+	Engine      string  `json:"engine"`
+	TableRows   int64   `json:"table_rows"`
+	DataLength  int64   `json:"data_length"`
+	IndexLength int64   `json:"index_length"`
+	DataFree    int64   `json:"data_free"`
+	FreeRatio   float64 `json:"free_ratio"` // data_free 占表空间总量的百分比
+}
+
+// TotalSize 表空间总量（数据 + 索引 + 空闲）
+func (t *TableSpace) TotalSize() int64 {
+	return t.DataLength + t.IndexLength + t.DataFree
+}
+
+// tableSpaceColumns information_schema.tables 查询列
+const tableSpaceColumns = `table_schema, table_name, engine, table_rows, data_length, index_length, data_free`
+
+// ScanFragmentation 扫描指定数据库（为空时扫描全部业务库）中存在空闲空间的表，按可回收空间降序排列
+func ScanFragmentation(conn *DatabaseConnection, databases []string) ([]*TableSpace, error) {
+	db, err := openDatabase(conn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	ctx, cancel := createTimeoutContext(conn.ConnectTimeout)
+	defer cancel()
+
+	query := `SELECT ` + tableSpaceColumns + `
+		FROM information_schema.tables
+		WHERE table_type = 'BASE TABLE' AND data_free > 0`
+	var args []interface{}
+	if len(databases) > 0 {
+		query += " AND table_schema IN (?" + strings.Repeat(", ?", len(databases)-1) + ")"
+		for _, name := range databases {
+			args = append(args, name)
+		}
+	} else {
+		query += " AND table_schema NOT IN ('information_schema', 'performance_schema', 'mysql', 'sys')"
+	}
+
+	tables, err := queryTableSpaces(ctx, db, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("扫描表碎片失败: %v", err)
+	}
+
+	sort.SliceStable(tables, func(i, j int) bool {
+		if tables[i].DataFree != tables[j].DataFree {
+			return tables[i].DataFree > tables[j].DataFree
+		}
+		return tables[i].FreeRatio > tables[j].FreeRatio
+	})
+	return tables, nil
+}
+
+// GetTableSpace 获取单表的空间占用
+func GetTableSpace(conn *DatabaseConnection, database string, table string) (*TableSpace, error) {
+	db, err := openDatabase(conn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	ctx, cancel := createTimeoutContext(conn.ConnectTimeout)
+	defer cancel()
+
+	query := `SELECT ` + tableSpaceColumns + `
+		FROM information_schema.tables
+		WHERE table_schema = ? AND table_name = ?`
+	tables, err := queryTableSpaces(ctx, db, query, database, table)
+	if err != nil {
+		return nil, fmt.Errorf("查询表空间失败: %v", err)
+	}
+	if len(tables) == 0 {
+		return nil, fmt.Errorf("表 %s.%s 不存在", database, table)
+	}
+	return tables[0], nil
+}
+
+// queryTableSpaces 在固定会话上查询表空间信息
+// MySQL 8.0 默认缓存 information_schema 统计信息，会话内关闭缓存以获得重建后的实际大小（5.7 无此变量，忽略错误）
+func queryTableSpaces(ctx context.Context, db *sql.DB, query string, args ...interface{}) ([]*TableSpace, error) {
+	session, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	_, _ = session.ExecContext(ctx, "SET SESSION information_schema_stats_expiry = 0")
+
+	rows, err := session.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []*TableSpace
+	for rows.Next() {
+		var schema, name, engine sql.NullString
+		var tableRows, dataLength, indexLength, dataFree sql.NullInt64
+		if err := rows.Scan(&schema, &name, &engine, &tableRows, &dataLength, &indexLength, &dataFree); err != nil {
+			return nil, err
+		}
+
+		space := &TableSpace{
+			Database:    schema.String,
+			Table:       name.String,
+			Engine:      engine.String,
+			TableRows:   tableRows.Int64,
+			DataLength:  dataLength.Int64,
+			IndexLength: indexLength.Int64,
+			DataFree:    dataFree.Int64,
+		}
+		if total := space.TotalSize(); total > 0 {
+			space.FreeRatio = float64(space.DataFree) / float64(total) * 100
+		}
+		tables = append(tables, space)
+	}
+	return tables, rows.Err()
+}
+
+// FormatBytes 将字节数格式化为可读字符串
+func FormatBytes(size int64) string {
+	const unit = 1024
+	sign := ""
+	if size < 0 {
+		sign = "-"
+		size = -size
+	}
+	if size < unit {
+		return fmt.Sprintf("%s%d B", sign, size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%s%.2f %cB", sign, float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,346 @@
+package utils
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// 索引分析结论类型
+const (
+	IndexFindingDuplicate = "duplicate"        // 与另一索引列完全相同
+	IndexFindingRedundant = "redundant_prefix" // 是另一索引的最左前缀
+	IndexFindingUnused    = "unused"           // 自实例启动以来未被使用
+)
+
+// 索引使用统计来源
+const (
+	IndexUsagePerformanceSchema = "performance_schema"
+	IndexUsageSys               = "sys"
+)
+
+// indexPrefixLength 索引列的前缀长度，如 `name`(10)
+var indexPrefixLength = regexp.MustCompile(`^\S+\s*\((\d+)\)`)
+
+// IndexStat information_schema.statistics 中的索引信息
+type IndexStat struct {
+	Name        string   `json:"name"`
+	Unique      bool     `json:"unique"`
+	Columns     []string `json:"columns"` // 含前缀长度，如 name(10)
+	IndexType   string   `json:"index_type"`
+	Cardinality int64    `json:"cardinality"`
+
+	// 使用统计：读写次数仅 performance_schema 可用时填充，Unused 也可来自 sys 库
+	Reads  *int64 `json:"reads,omitempty"`
+	Writes *int64 `json:"writes,omitempty"`
+	Unused *bool  `json:"unused,omitempty"`
+}
+
+// IndexFinding 索引分析结论
+type IndexFinding struct {
+	Kind      string `json:"kind"`
+	Index     string `json:"index"`
+	CoveredBy string `json:"covered_by,omitempty"`
+	Message   string `json:"message"`
+	DropSQL   string `json:"drop_sql,omitempty"`
+}
+
+// IndexAnalysis 单表索引分析结果
+type IndexAnalysis struct {
+	Database      string         `json:"database"`
+	Table         string         `json:"table"`
+	Indexes       []*IndexStat   `json:"indexes"`
+	Findings      []IndexFinding `json:"findings"`
+	UsageSource   string         `json:"usage_source"`   // performance_schema / sys，空表示不可用
+	UptimeSeconds int64          `json:"uptime_seconds"` // 使用统计自实例启动起累计
+	Warnings      []string       `json:"warnings"`
+}
+
+// AnalyzeIndexes 分析表的重复、冗余与未使用索引
+func AnalyzeIndexes(conn *DatabaseConnection, database, table string) (*IndexAnalysis, error) {
+	db, err := openDatabase(conn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	ctx, cancel := createTimeoutContext(conn.ConnectTimeout)
+	defer cancel()
+
+	indexes, err := loadIndexStats(ctx, db, database, table)
+	if err != nil {
+		return nil, err
+	}
+
+	analysis := &IndexAnalysis{
+		Database: database,
+		Table:    table,
+		Indexes:  indexes,
+		Findings: FindRedundantIndexes(table, indexes),
+	}
+
+	var uptime sql.NullString
+	var name string
+	if err := db.QueryRowContext(ctx, "SHOW GLOBAL STATUS LIKE 'Uptime'").Scan(&name, &uptime); err == nil {
+		fmt.Sscanf(uptime.String, "%d", &analysis.UptimeSeconds)
+	}
+
+	loadIndexUsage(ctx, db, analysis)
+	for _, idx := range analysis.Indexes {
+		if idx.Unused == nil || !*idx.Unused || idx.Name == "PRIMARY" {
+			continue
+		}
+		finding := IndexFinding{
+			Kind:    IndexFindingUnused,
+			Index:   idx.Name,
+			Message: fmt.Sprintf("索引 %s 自实例启动以来未被使用", idx.Name),
+			DropSQL: dropIndexSQL(table, idx.Name),
+		}
+		if idx.Unique {
+			finding.Message += "（唯一索引承担约束作用，删除前请确认业务不依赖其唯一性）"
+		}
+		analysis.Findings = append(analysis.Findings, finding)
+	}
+
+	return analysis, nil
+}
+
+// FindRedundantIndexes 查找重复索引与最左前缀冗余索引
+func FindRedundantIndexes(table string, indexes []*IndexStat) []IndexFinding {
+	var findings []IndexFinding
+	reported := make(map[string]bool)
+
+	for _, a := range indexes {
+		if a.Name == "PRIMARY" || !strings.EqualFold(a.IndexType, "BTREE") {
+			continue
+		}
+		for _, b := range indexes {
+			if a == b || reported[a.Name] || !strings.EqualFold(b.IndexType, "BTREE") {
+				continue
+			}
+
+			switch {
+			case sameColumns(a.Columns, b.Columns):
+				// 完全重复时保留主键/唯一索引；同类索引按名称保留靠前者
+				if a.Unique && !b.Unique {
+					continue
+				}
+				if a.Unique == b.Unique && b.Name != "PRIMARY" && a.Name < b.Name {
+					continue
+				}
+				findings = append(findings, IndexFinding{
+					Kind:      IndexFindingDuplicate,
+					Index:     a.Name,
+					CoveredBy: b.Name,
+					Message:   fmt.Sprintf("索引 %s 与 %s 的列完全相同 (%s)", a.Name, b.Name, strings.Join(a.Columns, ", ")),
+					DropSQL:   dropIndexSQL(table, a.Name),
+				})
+				reported[a.Name] = true
+
+			case !a.Unique && isColumnPrefix(a.Columns, b.Columns):
+				findings = append(findings, IndexFinding{
+					Kind:      IndexFindingRedundant,
+					Index:     a.Name,
+					CoveredBy: b.Name,
+					Message:   fmt.Sprintf("索引 %s (%s) 是 %s (%s) 的最左前缀", a.Name, strings.Join(a.Columns, ", "), b.Name, strings.Join(b.Columns, ", ")),
+					DropSQL:   dropIndexSQL(table, a.Name),
+				})
+				reported[a.Name] = true
+			}
+		}
+	}
+
+	return findings
+}
+
+// IndexCoveredBy 返回已有索引中与给定列重复或以其为最左前缀的索引名
+func IndexCoveredBy(indexes []*IndexStat, columns []string) string {
+	for _, idx := range indexes {
+		if !strings.EqualFold(idx.IndexType, "BTREE") {
+			continue
+		}
+		if sameColumns(columns, idx.Columns) || isColumnPrefix(columns, idx.Columns) {
+			return idx.Name
+		}
+	}
+	return ""
+}
+
+// IndexesMadeRedundant 返回新增给定列的索引后将成为其最左前缀的现有普通索引
+func IndexesMadeRedundant(indexes []*IndexStat, columns []string) []*IndexStat {
+	var result []*IndexStat
+	for _, idx := range indexes {
+		if !idx.Unique && strings.EqualFold(idx.IndexType, "BTREE") && isColumnPrefix(idx.Columns, columns) {
+			result = append(result, idx)
+		}
+	}
+	return result
+}
+
+// loadIndexStats 读取索引列信息
+func loadIndexStats(ctx context.Context, db *sql.DB, database, table string) ([]*IndexStat, error) {
+	query := `
+		SELECT index_name, non_unique, column_name, sub_part, index_type, cardinality
+		FROM information_schema.statistics
+		WHERE table_schema = ? AND table_name = ?
+		ORDER BY index_name, seq_in_index
+	`
+	rows, err := db.QueryContext(ctx, query, database, table)
+	if err != nil {
+		return nil, fmt.Errorf("查询索引信息失败: %v", err)
+	}
+	defer rows.Close()
+
+	var indexes []*IndexStat
+	byName := make(map[string]*IndexStat)
+	for rows.Next() {
+		var (
+			name, column, indexType sql.NullString
+			nonUnique               int
+			subPart, cardinality    sql.NullInt64
+		)
+		if err := rows.Scan(&name, &nonUnique, &column, &subPart, &indexType, &cardinality); err != nil {
+			return nil, fmt.Errorf("读取索引信息失败: %v", err)
+		}
+
+		idx, ok := byName[name.String]
+		if !ok {
+			idx = &IndexStat{
+				Name:      name.String,
+				Unique:    nonUnique == 0,
+				IndexType: indexType.String,
+			}
+			byName[name.String] = idx
+			indexes = append(indexes, idx)
+		}
+		col := strings.ToLower(column.String)
+		if !column.Valid {
+			// 函数索引（MySQL 8.0.13+）没有列名
+			col = "(expression)"
+		}
+		if subPart.Valid {
+			col = fmt.Sprintf("%s(%d)", col, subPart.Int64)
+		}
+		idx.Columns = append(idx.Columns, col)
+		if cardinality.Int64 > idx.Cardinality {
+			idx.Cardinality = cardinality.Int64
+		}
+	}
+
+	// 主键排在最前，其余按名称
+	sort.SliceStable(indexes, func(i, j int) bool {
+		if indexes[i].Name == "PRIMARY" || indexes[j].Name == "PRIMARY" {
+			return indexes[i].Name == "PRIMARY"
+		}
+		return indexes[i].Name < indexes[j].Name
+	})
+	return indexes, rows.Err()
+}
+
+// loadIndexUsage 读取索引使用统计：优先 performance_schema，其次 sys.schema_unused_indexes
+func loadIndexUsage(ctx context.Context, db *sql.DB, analysis *IndexAnalysis) {
+	byName := make(map[string]*IndexStat, len(analysis.Indexes))
+	for _, idx := range analysis.Indexes {
+		byName[idx.Name] = idx
+	}
+
+	query := `
+		SELECT index_name, count_read, count_write
+		FROM performance_schema.table_io_waits_summary_by_index_usage
+		WHERE object_schema = ? AND object_name = ? AND index_name IS NOT NULL
+	`
+	rows, err := db.QueryContext(ctx, query, analysis.Database, analysis.Table)
+	if err == nil {
+		found := false
+		for rows.Next() {
+			var name string
+			var reads, writes int64
+			if err := rows.Scan(&name, &reads, &writes); err != nil {
+				continue
+			}
+			if idx, ok := byName[name]; ok {
+				r, w, unused := reads, writes, reads == 0
+				idx.Reads, idx.Writes, idx.Unused = &r, &w, &unused
+				found = true
+			}
+		}
+		rows.Close()
+		if found {
+			analysis.UsageSource = IndexUsagePerformanceSchema
+			return
+		}
+	}
+
+	rows, err = db.QueryContext(ctx,
+		"SELECT index_name FROM sys.schema_unused_indexes WHERE object_schema = ? AND object_name = ?",
+		analysis.Database, analysis.Table)
+	if err != nil {
+		analysis.Warnings = append(analysis.Warnings, "performance_schema 与 sys 库均不可用，无法提供索引使用统计")
+		return
+	}
+	defer rows.Close()
+
+	unused := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err == nil {
+			unused[name] = true
+		}
+	}
+	for _, idx := range analysis.Indexes {
+		flag := unused[idx.Name]
+		idx.Unused = &flag
+	}
+	analysis.UsageSource = IndexUsageSys
+}
+
+// sameColumns 两组索引列是否完全相同
+func sameColumns(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// isColumnPrefix a 是否为 b 的严格最左前缀
+func isColumnPrefix(a, b []string) bool {
+	if len(a) == 0 || len(a) >= len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// dropIndexSQL 生成删除索引语句
+func dropIndexSQL(table, index string) string {
+	return fmt.Sprintf("ALTER TABLE %s DROP INDEX %s", QuoteIdentifier(table), QuoteIdentifier(index))
+}
+
+// IndexDefinitionColumns 从索引定义中提取与 IndexStat.Columns 同格式的列（小写，含前缀长度）
+func IndexDefinitionColumns(definition string) []string {
+	index := parseIndexElement(definition)
+	if index == nil {
+		return nil
+	}
+	columns := make([]string, 0, len(index.Parts))
+	for i, part := range index.Parts {
+		col := strings.ToLower(index.Columns[i])
+		if m := indexPrefixLength.FindStringSubmatch(part); m != nil {
+			col = fmt.Sprintf("%s(%s)", col, m[1])
+		}
+		columns = append(columns, col)
+	}
+	return columns
+}
@@ -0,0 +1,235 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 审核规则级别
+const (
+	LintSeverityError = "error" // 阻止创建执行
+	LintSeverityWarn  = "warn"  // 仅提示
+)
+
+// 审核规则ID
+const (
+	LintRequirePrimaryKey = "require_primary_key" // 表必须保留主键
+	LintColumnComment     = "column_comment"      // 新增列必须有注释
+	LintMoneyFloat        = "no_float_for_money"  // 金额类字段禁止 FLOAT/DOUBLE
+	LintUtf8mb4Only       = "utf8mb4_only"        // 仅允许 utf8mb4 字符集
+	LintMaxIndexCount     = "max_index_count"     // 单表索引数量上限
+	LintMaxVarcharLength  = "max_varchar_length"  // VARCHAR 最大长度
+	LintIndexNaming       = "index_naming"        // 索引命名规范 idx_ / uk_
+	LintNotNullDefault    = "not_null_default"    // NOT NULL 列必须有默认值
+)
+
+// LintRule 审核规则配置
+type LintRule struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Severity    string `json:"severity"` // error / warn
+	Enabled     bool   `json:"enabled"`
+	Threshold   int    `json:"threshold,omitempty"` // 数量/长度类规则的阈值
+}
+
+// LintViolation 规则违规项
+type LintViolation struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Target   string `json:"target"` // 列名、索引名或表名
+	Message  string `json:"message"`
+}
+
+var (
+	moneyColumnPattern = regexp.MustCompile(`(?i)(amount|price|money|balance|salary|payment|refund|discount|income)|(^|_)(amt|fee|fees|cost|pay|cash|fund)(_|$)`)
+	varcharLength      = regexp.MustCompile(`^varchar\((\d+)\)`)
+	floatTypePattern   = regexp.MustCompile(`^(float|double|real)\b`)
+)
+
+// DefaultLintRules 内置审核规则及默认配置
+func DefaultLintRules() []LintRule {
+	return []LintRule{
+		{ID: LintRequirePrimaryKey, Name: "保留主键", Description: "表必须有主键，pt-online-schema-change 依赖主键或唯一键", Severity: LintSeverityError, Enabled: true},
+		{ID: LintColumnComment, Name: "新增列注释", Description: "新增列必须包含 COMMENT", Severity: LintSeverityWarn, Enabled: true},
+		{ID: LintMoneyFloat, Name: "金额字段类型", Description: "金额类字段禁止使用 FLOAT/DOUBLE，请使用 DECIMAL", Severity: LintSeverityError, Enabled: true},
+		{ID: LintUtf8mb4Only, Name: "字符集", Description: "仅允许 utf8mb4 字符集", Severity: LintSeverityError, Enabled: true},
+		{ID: LintMaxIndexCount, Name: "索引数量", Description: "单表索引数量不超过阈值", Severity: LintSeverityWarn, Enabled: true, Threshold: 8},
+		{ID: LintMaxVarcharLength, Name: "VARCHAR长度", Description: "VARCHAR 长度不超过阈值，超长文本请使用 TEXT", Severity: LintSeverityWarn, Enabled: true, Threshold: 2048},
+		{ID: LintIndexNaming, Name: "索引命名", Description: "普通索引以 idx_ 开头，唯一索引以 uk_ 开头", Severity: LintSeverityWarn, Enabled: true},
+		{ID: LintNotNullDefault, Name: "NOT NULL默认值", Description: "NOT NULL 列必须指定 DEFAULT", Severity: LintSeverityWarn, Enabled: true},
+	}
+}
+
+// linter 单次审核的上下文
+type linter struct {
+	rules      map[string]LintRule
+	violations []LintViolation
+}
+
+// LintAlter 按规则审核 ALTER 语句；alterSQL 为空时仅审核表本身（如碎片整理）
+func LintAlter(table *TableDefinition, alterSQL string, rules []LintRule) ([]LintViolation, error) {
+	l := &linter{rules: make(map[string]LintRule)}
+	for _, rule := range rules {
+		if rule.Enabled {
+			l.rules[rule.ID] = rule
+		}
+	}
+
+	var clauses []*AlterClause
+	if strings.TrimSpace(alterSQL) != "" {
+		cleaned, err := CleanAlterSQL(alterSQL)
+		if err != nil {
+			return nil, err
+		}
+		clauses = ParseAlterClauses(cleaned)
+	}
+
+	hasPrimary := table.PrimaryKey() != nil
+	indexCount := len(table.Indexes)
+	addsIndex := false
+
+	for _, clause := range clauses {
+		switch clause.Kind {
+		case AlterAddColumn, AlterModifyColumn, AlterChangeColumn:
+			name := clause.Name
+			if clause.Kind == AlterChangeColumn {
+				name = clause.NewName
+			}
+			column, err := ParseColumnDefinition(QuoteIdentifier(name) + " " + clause.Definition)
+			if err != nil {
+				continue
+			}
+			l.lintColumn(column, clause.Kind == AlterAddColumn)
+
+		case AlterAddIndex:
+			indexCount++
+			addsIndex = true
+			if index := parseIndexElement(clause.Definition); index != nil {
+				l.lintIndexName(index)
+			}
+
+		case AlterDropIndex:
+			indexCount--
+
+		case AlterAddPrimaryKey:
+			if !hasPrimary {
+				indexCount++
+			}
+			hasPrimary = true
+
+		case AlterDropPrimaryKey:
+			hasPrimary = false
+			indexCount--
+
+		case AlterConvertCharset:
+			if m := charsetPattern.FindStringSubmatch(clause.Definition); m != nil && !isUtf8mb4(m[1]) {
+				l.report(LintUtf8mb4Only, table.Name, "表字符集将转换为 %s，仅允许 utf8mb4", strings.ToLower(m[1]))
+			}
+
+		case AlterTableOption:
+			if (clause.Name == "CHARSET" || clause.Name == "COLLATE") && !isUtf8mb4(clause.Definition) {
+				l.report(LintUtf8mb4Only, table.Name, "表 %s 设置为 %s，仅允许 utf8mb4", clause.Name, clause.Definition)
+			}
+		}
+	}
+
+	if !hasPrimary {
+		l.report(LintRequirePrimaryKey, table.Name, "表 %s 执行后没有主键", table.Name)
+	}
+	if limit := l.threshold(LintMaxIndexCount); addsIndex && limit > 0 && indexCount > limit {
+		l.report(LintMaxIndexCount, table.Name, "执行后索引数量为 %d，超过上限 %d", indexCount, limit)
+	}
+
+	return l.violations, nil
+}
+
+// HasLintErrors 是否存在 error 级别违规
+func HasLintErrors(violations []LintViolation) bool {
+	for _, v := range violations {
+		if v.Severity == LintSeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// report 记录违规（规则未启用时忽略）
+func (l *linter) report(ruleID, target, format string, args ...interface{}) {
+	rule, ok := l.rules[ruleID]
+	if !ok {
+		return
+	}
+	l.violations = append(l.violations, LintViolation{
+		Rule:     ruleID,
+		Severity: rule.Severity,
+		Target:   target,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// threshold 返回已启用规则的阈值，未启用返回 0
+func (l *linter) threshold(ruleID string) int {
+	return l.rules[ruleID].Threshold
+}
+
+// lintColumn 审核单个新增/修改列
+func (l *linter) lintColumn(column *ColumnDefinition, isNew bool) {
+	if isNew && column.Comment == "" {
+		l.report(LintColumnComment, column.Name, "新增列 %s 缺少 COMMENT", column.Name)
+	}
+
+	if floatTypePattern.MatchString(column.Type) && moneyColumnPattern.MatchString(column.Name) {
+		l.report(LintMoneyFloat, column.Name, "列 %s 疑似金额字段，类型 %s 存在精度问题，请使用 DECIMAL", column.Name, column.Type)
+	}
+
+	if column.Charset != "" && !isUtf8mb4(column.Charset) {
+		l.report(LintUtf8mb4Only, column.Name, "列 %s 使用字符集 %s，仅允许 utf8mb4", column.Name, column.Charset)
+	} else if column.Collation != "" && !isUtf8mb4(column.Collation) {
+		l.report(LintUtf8mb4Only, column.Name, "列 %s 使用排序规则 %s，仅允许 utf8mb4", column.Name, column.Collation)
+	}
+
+	if m := varcharLength.FindStringSubmatch(column.Type); m != nil {
+		length, _ := strconv.Atoi(m[1])
+		if limit := l.threshold(LintMaxVarcharLength); limit > 0 && length > limit {
+			l.report(LintMaxVarcharLength, column.Name, "列 %s 长度为 %d，超过上限 %d", column.Name, length, limit)
+		}
+	}
+
+	upper := strings.ToUpper(column.Definition)
+	generated := strings.Contains(upper, " AS (") || strings.Contains(upper, "GENERATED ALWAYS")
+	if !column.Nullable && column.Default == nil && !column.AutoIncrement && !generated {
+		l.report(LintNotNullDefault, column.Name, "列 %s 为 NOT NULL 但未指定 DEFAULT", column.Name)
+	}
+}
+
+// lintIndexName 审核新增索引命名
+func (l *linter) lintIndexName(index *IndexDefinition) {
+	switch index.Kind {
+	case IndexKindPrimary:
+		return
+	case IndexKindUnique:
+		if !strings.HasPrefix(strings.ToLower(index.Name), "uk_") {
+			l.report(LintIndexNaming, displayIndexName(index), "唯一索引 %s 应以 uk_ 开头", displayIndexName(index))
+		}
+	default:
+		if !strings.HasPrefix(strings.ToLower(index.Name), "idx_") {
+			l.report(LintIndexNaming, displayIndexName(index), "索引 %s 应以 idx_ 开头", displayIndexName(index))
+		}
+	}
+}
+
+// displayIndexName 未命名索引的展示名
+func displayIndexName(index *IndexDefinition) string {
+	if index.Name == "" {
+		return "(未命名)"
+	}
+	return index.Name
+}
+
+// isUtf8mb4 字符集或排序规则是否属于 utf8mb4
+func isUtf8mb4(name string) bool {
+	return strings.HasPrefix(strings.ToLower(strings.Trim(name, "'\"")), "utf8mb4")
+}
@@ -0,0 +1,295 @@
+package utils
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// migrationFilePattern 版本化迁移文件名，如 0001_add_col.sql、V2__init.sql、20240101120000-add.sql
+var migrationFilePattern = regexp.MustCompile(`^[Vv]?(\d+)(?:__|[._-])?.*\.sql$`)
+
+// alterTablePattern ALTER TABLE 语句及其表名（可带库名前缀）
+var alterTablePattern = regexp.MustCompile("(?is)^ALTER\\s+(?:ONLINE\\s+|IGNORE\\s+)?TABLE\\s+((?:`[^`]+`|[\\w$]+)(?:\\s*\\.\\s*(?:`[^`]+`|[\\w$]+))?)\\s+(.+)$")
+
+// MigrationStatement 迁移文件中的单条语句
+type MigrationStatement struct {
+	Index     int    `json:"index"`
+	SQL       string `json:"sql"`
+	Table     string `json:"table,omitempty"`  // ALTER 的目标表
+	Schema    string `json:"schema,omitempty"` // 语句中显式指定的库名
+	Supported bool   `json:"supported"`
+	Reason    string `json:"reason,omitempty"` // 不支持的原因
+}
+
+// MigrationFile 解析后的版本化迁移文件
+type MigrationFile struct {
+	Version     string                `json:"version"`
+	Name        string                `json:"name"`
+	Checksum    string                `json:"checksum"`
+	Statements  []*MigrationStatement `json:"statements"`
+	Unsupported int                   `json:"unsupported"` // 不支持的语句数
+}
+
+// ScanMigrationDir 扫描目录下的版本化 .sql 文件（含子目录），按版本号升序返回
+func ScanMigrationDir(dir string) ([]*MigrationFile, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("迁移目录不可访问: %v", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s 不是目录", dir)
+	}
+
+	var files []*MigrationFile
+	versions := make(map[string]string)
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != dir && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !migrationFilePattern.MatchString(info.Name()) {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("读取迁移文件 %s 失败: %v", info.Name(), err)
+		}
+		rel, _ := filepath.Rel(dir, path)
+		file := ParseMigrationFile(rel, content)
+		if prev, exists := versions[file.Version]; exists {
+			return fmt.Errorf("版本 %s 重复: %s 与 %s", file.Version, prev, rel)
+		}
+		versions[file.Version] = rel
+		files = append(files, file)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return CompareMigrationVersions(files[i].Version, files[j].Version) < 0
+	})
+	return files, nil
+}
+
+// ParseMigrationFile 解析单个迁移文件，识别其中的 ALTER TABLE 语句
+func ParseMigrationFile(name string, content []byte) *MigrationFile {
+	sum := sha256.Sum256(content)
+	file := &MigrationFile{
+		Name:     name,
+		Checksum: hex.EncodeToString(sum[:]),
+	}
+	if m := migrationFilePattern.FindStringSubmatch(filepath.Base(name)); m != nil {
+		file.Version = normalizeMigrationVersion(m[1])
+	}
+
+	for i, sql := range SplitSQLStatements(string(content)) {
+		stmt := &MigrationStatement{Index: i, SQL: sql}
+		if m := alterTablePattern.FindStringSubmatch(sql); m != nil {
+			stmt.Schema, stmt.Table = splitQualifiedName(m[1])
+			stmt.Supported = true
+		} else {
+			stmt.Reason = "仅支持 ALTER TABLE 语句"
+			file.Unsupported++
+		}
+		file.Statements = append(file.Statements, stmt)
+	}
+
+	return file
+}
+
+// SplitSQLStatements 去掉注释后按顶层分号拆分语句
+func SplitSQLStatements(content string) []string {
+	var result []string
+	for _, stmt := range splitTopLevel(stripSQLComments(content), ';') {
+		stmt = strings.TrimSpace(stmt)
+		if stmt != "" {
+			result = append(result, stmt)
+		}
+	}
+	return result
+}
+
+// CompareMigrationVersions 按数值比较版本号
+func CompareMigrationVersions(a, b string) int {
+	na, errA := strconv.ParseUint(a, 10, 64)
+	nb, errB := strconv.ParseUint(b, 10, 64)
+	if errA == nil && errB == nil {
+		switch {
+		case na < nb:
+			return -1
+		case na > nb:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a, b)
+}
+
+// 迁移包解压大小限制，防止压缩炸弹占满磁盘
+const (
+	maxMigrationFileSize    = 16 << 20  // 单个文件
+	maxMigrationExtractSize = 256 << 20 // 解压总量
+)
+
+// ExtractTarball 将 tar / tar.gz 包解压到目标目录，仅保留 .sql 文件
+func ExtractTarball(r io.Reader, dest string) (int, error) {
+	reader := bufio.NewReader(r)
+	// 根据魔数判断是否为 gzip 压缩
+	if magic, err := reader.Peek(2); err == nil && bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return 0, fmt.Errorf("解压失败: %v", err)
+		}
+		defer gz.Close()
+		return extractTar(gz, dest)
+	}
+	return extractTar(reader, dest)
+}
+
+// extractTar 解包 tar 流，拒绝越出目标目录的路径
+func extractTar(r io.Reader, dest string) (int, error) {
+	if err := os.MkdirAll(dest, 0o755); err != nil {
+		return 0, fmt.Errorf("创建目录失败: %v", err)
+	}
+	root, err := filepath.Abs(dest)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	var total int64
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return count, fmt.Errorf("读取压缩包失败: %v", err)
+		}
+		if header.Typeflag != tar.TypeReg || !strings.HasSuffix(strings.ToLower(header.Name), ".sql") {
+			continue
+		}
+
+		target := filepath.Join(root, filepath.Clean("/"+header.Name))
+		if !strings.HasPrefix(target, root+string(os.PathSeparator)) {
+			return count, fmt.Errorf("非法的文件路径: %s", header.Name)
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return count, fmt.Errorf("创建目录失败: %v", err)
+		}
+		out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+		if err != nil {
+			return count, fmt.Errorf("写入文件失败: %v", err)
+		}
+		// 多读一个字节以判断是否超限（不信任 header 中的大小）
+		written, err := io.CopyN(out, tr, maxMigrationFileSize+1)
+		out.Close()
+		if err != nil && err != io.EOF {
+			return count, fmt.Errorf("写入文件失败: %v", err)
+		}
+		if written > maxMigrationFileSize {
+			return count, fmt.Errorf("文件 %s 超过 %s 的大小限制", header.Name, FormatBytes(maxMigrationFileSize))
+		}
+		if total += written; total > maxMigrationExtractSize {
+			return count, fmt.Errorf("解压内容超过 %s 的大小限制", FormatBytes(maxMigrationExtractSize))
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// stripSQLComments 去掉 -- 、# 与 /* */ 注释（保留 /*! */ 版本注释内容与字符串）
+func stripSQLComments(s string) string {
+	var b strings.Builder
+	var quote byte
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if quote != 0 {
+			b.WriteByte(c)
+			if c == '\\' && quote != '`' && i+1 < len(s) {
+				i++
+				b.WriteByte(s[i])
+				continue
+			}
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+			b.WriteByte(c)
+		case c == '#' || (c == '-' && strings.HasPrefix(s[i:], "-- ")) || (c == '-' && strings.HasPrefix(s[i:], "--\n")):
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+			b.WriteByte('\n')
+		case c == '/' && strings.HasPrefix(s[i:], "/*"):
+			end := strings.Index(s[i+2:], "*/")
+			if end < 0 {
+				return b.String()
+			}
+			body := s[i+2 : i+2+end]
+			if strings.HasPrefix(body, "!") {
+				b.WriteString(strings.TrimLeft(body[1:], "0123456789"))
+			}
+			b.WriteByte(' ')
+			i += end + 3
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String()
+}
+
+// splitQualifiedName 拆分 db.table 形式的名称
+func splitQualifiedName(name string) (string, string) {
+	first, rest := readIdentifier(name)
+	rest = strings.TrimSpace(rest)
+	if strings.HasPrefix(rest, ".") {
+		second, _ := readIdentifier(strings.TrimSpace(rest[1:]))
+		return first, second
+	}
+	// 未加反引号的 db.table 会被整体读出
+	if !strings.HasPrefix(strings.TrimSpace(name), "`") {
+		if idx := strings.Index(first, "."); idx >= 0 {
+			return first[:idx], unquoteIdentifier(first[idx+1:])
+		}
+	}
+	return "", first
+}
+
+// normalizeMigrationVersion 去掉版本号前导零
+func normalizeMigrationVersion(version string) string {
+	trimmed := strings.TrimLeft(version, "0")
+	if trimmed == "" {
+		return "0"
+	}
+	return trimmed
+}
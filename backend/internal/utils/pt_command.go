@@ -41,6 +41,16 @@ type PTOptions struct {
 	Recursion      int    `json:"recursion"`       // 递归级别
 	BinlogPosition string `json:"binlog_position"` // binlog位置
 	NoCheckAlter   bool   `json:"no_check_alter"`  // 跳过 check-alter 预检
+
+	// 切换保护：切换前等待目标表上的长事务结束
+	CutoverGuard     bool   `json:"cutover_guard"`
+	LongTrxSeconds   int    `json:"long_trx_seconds"`   // 运行超过该秒数的事务视为长事务
+	CutoverTimeout   int    `json:"cutover_timeout"`    // 最长等待秒数
+	CutoverOnTimeout string `json:"cutover_on_timeout"` // 超时后动作：proceed / abort
+
+	// 续跑：记录复制进度到历史表，失败时保留新表与触发器以便 --resume
+	History   bool `json:"history"`
+	Resumable bool `json:"resumable"`
 }
 
 // DDLType DDL操作类型
@@ -133,7 +143,7 @@ func (b *PTCommandBuilder) buildCommand() (string, error) {
 	parts = append(parts, fmt.Sprintf("--password=%s", b.ConnectionConfig.Password))
 
 	// 数据库和表
-	parts = append(parts, fmt.Sprintf("D=%s,t=%s", b.TableInfo.Database, b.TableInfo.Table))
+	parts = append(parts, fmt.Sprintf("D=%s,t=%s%s", b.TableInfo.Database, b.TableInfo.Table, ptTLSDSNOptions(b.ConnectionConfig)))
 
 	// ALTER语句
 	if b.AlterStatement != "" {
@@ -193,8 +203,11 @@ func (b *PTCommandBuilder) buildCommand() (string, error) {
 		parts = append(parts, "--dry-run")
 	}
 
-	if b.Options.DropOldTable {
+	// --no-drop-triggers 隐含保留旧表，旧表由执行完成后统一清理
+	if b.Options.DropOldTable && !b.Options.Resumable {
 		parts = append(parts, "--drop-old-table")
+	} else {
+		parts = append(parts, "--no-drop-old-table")
 	}
 
 	if b.Options.Statistics {
@@ -206,9 +219,31 @@ func (b *PTCommandBuilder) buildCommand() (string, error) {
 		parts = append(parts, "--no-check-alter")
 	}
 
+	if b.Options.CutoverGuard {
+		parts = append(parts, fmt.Sprintf("--plugin=%s", ptGuardPlugin))
+	}
+
+	if b.Options.History {
+		parts = append(parts, "--history", fmt.Sprintf("--history-table=%s", PTHistoryTable))
+	}
+
+	if b.Options.Resumable {
+		parts = append(parts, "--no-drop-new-table", "--no-drop-triggers")
+	}
+
 	// 拼接命令
 	command := strings.Join(parts, " \\\n  ")
 
+	// TLS：先在容器内写入证书与 option file，证书内容通过环境变量传入
+	if b.ConnectionConfig.TLSEnabled() {
+		command = ptTLSPreamble(b.ConnectionConfig) + " && \\\n" + command
+	}
+
+	// 切换保护：写入 pt-osc 插件，插件源码通过环境变量传入
+	if b.Options.CutoverGuard {
+		command = ptCutoverGuardPreamble(b.TableInfo.Database, b.TableInfo.Table, b.Options) + " && \\\n" + command
+	}
+
 	return command, nil
 }
 
@@ -332,8 +367,10 @@ func (b *PTCommandBuilder) validateAlterSyntax(sql string) error {
 		"DROP COLUMN", "DROP INDEX", "DROP KEY",
 		"MODIFY COLUMN", "CHANGE COLUMN", "ALTER COLUMN",
 		"ENGINE=", "AUTO_INCREMENT=", "COMMENT=",
-		"ADD CONSTRAINT", "DROP CONSTRAINT",
+		"ADD CONSTRAINT", "DROP CONSTRAINT", "DROP FOREIGN KEY",
 		"ADD PRIMARY KEY", "DROP PRIMARY KEY",
+		"RENAME COLUMN", "RENAME INDEX", "RENAME KEY",
+		"ROW_FORMAT=", "CHARSET=", "COLLATE=", "KEY_BLOCK_SIZE=",
 	}
 
 	hasValidOperation := false
@@ -403,18 +440,8 @@ func (b *PTCommandBuilder) estimateExecutionTime() string {
 		return "unknown"
 	}
 
-	// 简单估算：假设每秒处理1000行
-	seconds := b.TableInfo.Rows / 1000
-
-	if seconds < 60 {
-		return fmt.Sprintf("%d秒", seconds)
-	} else if seconds < 3600 {
-		return fmt.Sprintf("%d分钟", seconds/60)
-	} else {
-		hours := seconds / 3600
-		minutes := (seconds % 3600) / 60
-		return fmt.Sprintf("%d小时%d分钟", hours, minutes)
-	}
+	// 简单估算：假设每秒处理1000行（有历史数据时由 EstimateExecution 覆盖）
+	return FormatDuration(b.TableInfo.Rows / DefaultRowsPerSecond)
 }
 
 // GetRecommendedChunkSize 获取推荐的chunk-size
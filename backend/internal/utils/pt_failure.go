@@ -0,0 +1,205 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// pt-osc 失败分类
+const (
+	FailureMissingPrimaryKey = "missing_primary_key" // 缺少主键或唯一索引
+	FailureExistingTriggers  = "existing_triggers"   // 表上已有触发器
+	FailureChildForeignKeys  = "child_foreign_keys"  // 存在引用该表的外键
+	FailureLockWaitTimeout   = "lock_wait_timeout"   // 锁等待超时 / 死锁
+	FailureCriticalLoad      = "critical_load"       // 超过 --critical-load
+	FailureReplicaLag        = "replica_lag"         // 从库延迟等待超时
+	FailureAccessDenied      = "access_denied"       // 权限不足
+	FailureDiskFull          = "disk_full"           // 磁盘空间不足
+	FailureDuplicateKey      = "duplicate_key"       // 新表唯一键冲突
+	FailureUnknownColumn     = "unknown_column"      // 列不存在
+	FailureContainer         = "container_error"     // 执行容器创建或启动失败
+	FailureUnknown           = "unknown"             // 未识别
+)
+
+// FailureClassification pt-osc 失败分类结果
+type FailureClassification struct {
+	Category    string `json:"category"`
+	Title       string `json:"title"`
+	MatchedLine string `json:"matched_line"` // 命中规则的日志行
+	Hint        string `json:"hint"`         // 处理建议
+}
+
+// failureRule 日志匹配规则（同一行命中多条时取靠前的规则）
+type failureRule struct {
+	category string
+	title    string
+	hint     string
+	patterns []*regexp.Regexp
+}
+
+var failureRules = []failureRule{
+	{
+		category: FailureMissingPrimaryKey,
+		title:    "缺少主键或唯一索引",
+		hint:     "pt-osc 依赖主键或非空唯一索引创建 DELETE 触发器并分块复制。请先为表添加主键（或非空唯一索引）后再执行。",
+		patterns: compileFailurePatterns(
+			`does not have a PRIMARY KEY or a unique index`,
+			`Cannot chunk the original table`,
+		),
+	},
+	{
+		category: FailureExistingTriggers,
+		title:    "表上已有触发器",
+		hint:     "目标表已存在触发器（或上次中断残留的 pt_osc_* 触发器）。残留触发器可通过重试（从头执行）自动清理；业务触发器需在 MySQL 5.7+ 使用 --preserve-triggers，或先临时移除。",
+		patterns: compileFailurePatterns(
+			`has triggers`,
+			`Trigger already exists`,
+			`already exists on table .*pt_osc`,
+		),
+	},
+	{
+		category: FailureChildForeignKeys,
+		title:    "存在引用该表的外键",
+		hint:     "有子表通过外键引用目标表，切换后外键将指向旧表。请在其他参数中指定 --alter-foreign-keys-method（auto / rebuild_constraints / drop_swap），或先移除子表外键。",
+		patterns: compileFailurePatterns(
+			`foreign keys that reference the table`,
+			`did not specify --alter-foreign-keys-method`,
+		),
+	},
+	{
+		category: FailureLockWaitTimeout,
+		title:    "锁等待超时",
+		hint:     "复制或切换时等待行锁/元数据锁超时。请检查目标表上的长事务或阻塞会话，适当调大 lock_wait_timeout，或在业务低峰期执行并开启切换保护。",
+		patterns: compileFailurePatterns(
+			`Lock wait timeout exceeded`,
+			`Deadlock found when trying to get lock`,
+			`Waiting for table metadata lock`,
+		),
+	},
+	{
+		category: FailureCriticalLoad,
+		title:    "实例负载超过临界值",
+		hint:     "实例负载超过 --critical-load，pt-osc 主动中止。请在负载较低时重试，或适当调高临界负载阈值（例如 Threads_running）。",
+		patterns: compileFailurePatterns(
+			`exceeds its critical threshold`,
+		),
+	},
+	{
+		category: FailureReplicaLag,
+		title:    "从库延迟过高",
+		hint:     "从库延迟持续超过 --max-lag，复制被长时间暂停。请排查从库复制状态与延迟原因，或调大最大延迟、通过 --check-slave-lag 指定需检查的从库。",
+		patterns: compileFailurePatterns(
+			`(?:replica|slave) lag is .* waiting`,
+			`timeout waiting for (?:replica|slave)`,
+			`(?:replica|slave) is stopped`,
+			`replication .*stopped`,
+		),
+	},
+	{
+		category: FailureAccessDenied,
+		title:    "权限不足",
+		hint:     "执行账号权限不足。pt-osc 需要目标库的 ALTER、CREATE、DROP、INSERT、UPDATE、DELETE、SELECT、TRIGGER、LOCK TABLES 权限，以及 PROCESS、REPLICATION SLAVE（检查从库时）权限。",
+		patterns: compileFailurePatterns(
+			`Access denied for user`,
+			`command denied to user`,
+			`you need .*privilege`,
+		),
+	},
+	{
+		category: FailureDiskFull,
+		title:    "磁盘空间不足",
+		hint:     "新表需要与原表相当的磁盘空间（外加 binlog 与临时文件）。请清理磁盘或扩容后重试，可先删除保留的旧表释放空间。",
+		patterns: compileFailurePatterns(
+			`The table .* is full`,
+			`No space left on device`,
+			`Errcode: 28`,
+			`Disk full`,
+		),
+	},
+	{
+		category: FailureDuplicateKey,
+		title:    "新表唯一键冲突",
+		hint:     "新增的唯一索引与现有数据冲突，pt-osc 使用 INSERT IGNORE 复制时会丢弃重复行。请先清理重复数据；确认可接受时才使用 --no-check-unique-key-change。",
+		patterns: compileFailurePatterns(
+			`Duplicate entry .* for key`,
+			`You are trying to add an unique key`,
+		),
+	},
+	{
+		category: FailureUnknownColumn,
+		title:    "列不存在",
+		hint:     "ALTER 语句引用的列在表中不存在。请核对当前表结构（可能已被其他变更修改）后修正语句。",
+		patterns: compileFailurePatterns(
+			`Unknown column`,
+			`Key column .* doesn't exist`,
+		),
+	},
+	{
+		category: FailureContainer,
+		title:    "执行容器异常",
+		hint:     "pt-osc 执行容器创建、启动或等待失败，通常为 Docker 服务或镜像暂时不可用。请检查 Docker 服务状态与镜像后重试。",
+		patterns: compileFailurePatterns(
+			`创建容器失败`,
+			`启动容器失败`,
+			`等待容器完成失败`,
+		),
+	},
+}
+
+// compileFailurePatterns 编译忽略大小写的匹配规则
+func compileFailurePatterns(patterns ...string) []*regexp.Regexp {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		compiled = append(compiled, regexp.MustCompile("(?i)"+pattern))
+	}
+	return compiled
+}
+
+// ClassifyPTFailure 匹配 pt-osc 输出，返回失败分类；均未命中时为 unknown
+// 错误通常出现在输出末尾，自后向前查找第一行命中规则的日志
+func ClassifyPTFailure(output string) *FailureClassification {
+	lines := strings.Split(output, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if line == "" {
+			continue
+		}
+		for _, rule := range failureRules {
+			for _, pattern := range rule.patterns {
+				if pattern.MatchString(line) {
+					return &FailureClassification{
+						Category:    rule.category,
+						Title:       rule.title,
+						MatchedLine: line,
+						Hint:        rule.hint,
+					}
+				}
+			}
+		}
+	}
+	return &FailureClassification{
+		Category:    FailureUnknown,
+		Title:       "未识别的错误",
+		MatchedLine: lastNonEmptyLine(lines),
+		Hint:        "未能自动识别失败原因，请查看完整执行日志。",
+	}
+}
+
+// FailureCategories 全部失败分类（用于筛选）
+func FailureCategories() []FailureClassification {
+	categories := make([]FailureClassification, 0, len(failureRules)+1)
+	for _, rule := range failureRules {
+		categories = append(categories, FailureClassification{Category: rule.category, Title: rule.title, Hint: rule.hint})
+	}
+	return append(categories, FailureClassification{Category: FailureUnknown, Title: "未识别的错误"})
+}
+
+// lastNonEmptyLine 最后一行非空输出
+func lastNonEmptyLine(lines []string) string {
+	for i := len(lines) - 1; i >= 0; i-- {
+		if line := strings.TrimSpace(lines[i]); line != "" {
+			return line
+		}
+	}
+	return ""
+}
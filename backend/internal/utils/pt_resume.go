@@ -0,0 +1,242 @@
+package utils
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// PTHistoryTable pt-osc --history 写入的历史表
+const PTHistoryTable = "percona.pt_osc_history"
+
+// resumeOptionPattern 命令中已有的 --resume 选项（含前面的续行）
+var resumeOptionPattern = regexp.MustCompile(`(?:\s*\\\n\s*|\s+)--resume=\d+`)
+
+// ResumeState pt-osc 任务的续跑条件
+type ResumeState struct {
+	JobID         int64    `json:"job_id"`
+	Done          bool     `json:"done"`      // 历史记录显示已完成
+	NewTable      string   `json:"new_table"` // 复制中的新表
+	NewTableFound bool     `json:"new_table_found"`
+	LowerBoundary string   `json:"lower_boundary"` // 最后完成分块的边界
+	UpperBoundary string   `json:"upper_boundary"`
+	Triggers      []string `json:"triggers"` // 原表上残留的 pt-osc 触发器
+	Resumable     bool     `json:"resumable"`
+	Reason        string   `json:"reason,omitempty"` // 不可续跑的原因
+}
+
+// WithResume 为命令追加 --resume（已有的 --resume 先移除）
+func WithResume(command string, jobID int64) string {
+	return WithoutResume(command) + fmt.Sprintf(" \\\n  --resume=%d", jobID)
+}
+
+// WithoutResume 移除命令中的 --resume
+func WithoutResume(command string) string {
+	return resumeOptionPattern.ReplaceAllString(command, "")
+}
+
+// LatestPTJobID 查询目标表最近一次 pt-osc 任务的 job_id
+func LatestPTJobID(conn *DatabaseConnection, database, table string) (int64, error) {
+	db, err := openDatabase(conn)
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	ctx, cancel := createTimeoutContext(conn.ConnectTimeout)
+	defer cancel()
+
+	var jobID int64
+	err = db.QueryRowContext(ctx,
+		"SELECT job_id FROM "+PTHistoryTable+" WHERE db = ? AND tbl = ? ORDER BY job_id DESC LIMIT 1",
+		database, table).Scan(&jobID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("未找到 %s.%s 的 pt-osc 历史记录", database, table)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("查询 pt-osc 历史记录失败: %v", err)
+	}
+	return jobID, nil
+}
+
+// CheckResumeState 检查 pt-osc 任务能否续跑：历史记录未完成，且新表与三个触发器仍然存在
+func CheckResumeState(conn *DatabaseConnection, database, table string, jobID int64) (*ResumeState, error) {
+	db, err := openDatabase(conn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	ctx, cancel := createTimeoutContext(conn.ConnectTimeout)
+	defer cancel()
+
+	state := &ResumeState{JobID: jobID, Triggers: []string{}}
+	var newTable, lower, upper sql.NullString
+	var done string
+	err = db.QueryRowContext(ctx,
+		"SELECT new_table_name, lower_boundary, upper_boundary, done FROM "+PTHistoryTable+" WHERE job_id = ? AND db = ? AND tbl = ?",
+		jobID, database, table).Scan(&newTable, &lower, &upper, &done)
+	if errors.Is(err, sql.ErrNoRows) {
+		state.Reason = fmt.Sprintf("pt-osc 历史记录中不存在任务 %d", jobID)
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询 pt-osc 历史记录失败: %v", err)
+	}
+	state.NewTable = newTable.String
+	state.LowerBoundary = lower.String
+	state.UpperBoundary = upper.String
+	state.Done = strings.EqualFold(done, "yes")
+
+	if state.NewTable != "" {
+		var count int
+		if err := db.QueryRowContext(ctx,
+			"SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = ? AND table_name = ?",
+			database, state.NewTable).Scan(&count); err != nil {
+			return nil, fmt.Errorf("查询新表失败: %v", err)
+		}
+		state.NewTableFound = count > 0
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT trigger_name FROM information_schema.triggers
+		WHERE event_object_schema = ? AND event_object_table = ? AND trigger_name LIKE 'pt\_osc\_%'
+		ORDER BY trigger_name`, database, table)
+	if err != nil {
+		return nil, fmt.Errorf("查询触发器失败: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		state.Triggers = append(state.Triggers, name)
+	}
+
+	switch {
+	case state.Done:
+		state.Reason = "该任务的数据复制已完成，无需续跑"
+	case !state.NewTableFound:
+		state.Reason = fmt.Sprintf("新表 %s 已不存在", state.NewTable)
+	case !hasTriggerSuffixes(state.Triggers, "_ins", "_upd", "_del"):
+		state.Reason = "原表上的 pt-osc 触发器不完整，续跑期间的写入无法同步到新表"
+	default:
+		state.Resumable = true
+	}
+	return state, nil
+}
+
+// DropResumeArtifacts 清理中断任务残留的触发器与新表（重新执行前调用）
+// 只删除写入该任务新表的 pt-osc 触发器，返回实际删除的触发器
+func DropResumeArtifacts(conn *DatabaseConnection, database, table string, state *ResumeState) ([]string, error) {
+	if state.NewTable == "" {
+		return nil, nil
+	}
+
+	db, err := openDatabase(conn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	ctx, cancel := createTimeoutContext(conn.ConnectTimeout)
+	defer cancel()
+
+	// 触发器体中引用了本任务新表的才属于本任务
+	rows, err := db.QueryContext(ctx, `
+		SELECT trigger_name FROM information_schema.triggers
+		WHERE event_object_schema = ? AND event_object_table = ? AND trigger_name LIKE 'pt\_osc\_%'
+		  AND INSTR(action_statement, ?) > 0
+		ORDER BY trigger_name`, database, table, QuoteIdentifier(database)+"."+QuoteIdentifier(state.NewTable))
+	if err != nil {
+		return nil, fmt.Errorf("查询触发器失败: %v", err)
+	}
+	var owned []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		owned = append(owned, name)
+	}
+	rows.Close()
+
+	// 先删除触发器，避免删表后原表写入失败
+	var dropped []string
+	for _, trigger := range owned {
+		query := fmt.Sprintf("DROP TRIGGER IF EXISTS %s.%s", QuoteIdentifier(database), QuoteIdentifier(trigger))
+		if _, err := db.ExecContext(ctx, query); err != nil {
+			return dropped, fmt.Errorf("删除触发器 %s 失败: %v", trigger, err)
+		}
+		dropped = append(dropped, trigger)
+	}
+	if state.NewTableFound {
+		query := fmt.Sprintf("DROP TABLE IF EXISTS %s.%s", QuoteIdentifier(database), QuoteIdentifier(state.NewTable))
+		if _, err := db.ExecContext(ctx, query); err != nil {
+			return dropped, fmt.Errorf("删除新表 %s 失败: %v", state.NewTable, err)
+		}
+	}
+	return dropped, nil
+}
+
+// DropPTTriggers 删除表上残留的 pt-osc 触发器，返回实际删除的触发器
+// 用于续跑模式（--no-drop-triggers）成功切换后清理旧表上的触发器
+func DropPTTriggers(conn *DatabaseConnection, database, table string) ([]string, error) {
+	db, err := openDatabase(conn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	ctx, cancel := createTimeoutContext(conn.ConnectTimeout)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT trigger_name FROM information_schema.triggers
+		WHERE event_object_schema = ? AND event_object_table = ? AND trigger_name LIKE 'pt\_osc\_%'
+		ORDER BY trigger_name`, database, table)
+	if err != nil {
+		return nil, fmt.Errorf("查询触发器失败: %v", err)
+	}
+	var triggers []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		triggers = append(triggers, name)
+	}
+	rows.Close()
+
+	var dropped []string
+	for _, trigger := range triggers {
+		query := fmt.Sprintf("DROP TRIGGER IF EXISTS %s.%s", QuoteIdentifier(database), QuoteIdentifier(trigger))
+		if _, err := db.ExecContext(ctx, query); err != nil {
+			return dropped, fmt.Errorf("删除触发器 %s 失败: %v", trigger, err)
+		}
+		dropped = append(dropped, trigger)
+	}
+	return dropped, nil
+}
+
+// hasTriggerSuffixes 触发器名是否覆盖全部后缀
+func hasTriggerSuffixes(triggers []string, suffixes ...string) bool {
+	for _, suffix := range suffixes {
+		found := false
+		for _, name := range triggers {
+			if strings.HasSuffix(name, suffix) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,194 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RollbackPlan 回滚方案
+type RollbackPlan struct {
+	Revertible   bool     `json:"revertible"`   // 是否可一键回滚
+	RollbackDDL  string   `json:"rollback_ddl"` // 逆向 ALTER 语句
+	Irreversible []string `json:"irreversible"` // 无法回滚的子句及原因
+	Warnings     []string `json:"warnings"`     // 回滚时需注意的事项
+}
+
+// GenerateRollbackDDL 根据执行前表结构与 ALTER 子句生成逆向 ALTER 语句
+func GenerateRollbackDDL(before *TableDefinition, table, alterSQL string) (*RollbackPlan, error) {
+	cleaned, err := CleanAlterSQL(alterSQL)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &RollbackPlan{}
+	clauses := ParseAlterClauses(cleaned)
+
+	// 用于推算未命名索引的自动名称
+	indexNames := map[string]bool{}
+	for _, idx := range before.Indexes {
+		indexNames[strings.ToLower(idx.Name)] = true
+	}
+
+	var inverse []string
+	for _, clause := range clauses {
+		stmt, reason := inverseClause(before, clause, indexNames)
+		if reason != "" {
+			plan.Irreversible = append(plan.Irreversible, fmt.Sprintf("%s: %s", clause.Raw, reason))
+			continue
+		}
+		if stmt == "" {
+			continue
+		}
+		// 逆向子句需能通过 pt-osc 命令构建的校验，否则回滚时无法创建执行
+		if _, err := CleanAlterSQL(stmt); err != nil {
+			plan.Irreversible = append(plan.Irreversible, fmt.Sprintf("%s: 逆向子句 %s 无法用于 pt-osc（%v）", clause.Raw, stmt, err))
+			continue
+		}
+		inverse = append(inverse, stmt)
+
+		if clause.Kind == AlterModifyColumn || clause.Kind == AlterChangeColumn {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("回滚列 %s 的定义时，执行后写入的数据可能因类型/长度收窄而被截断或导致失败", clause.Name))
+		}
+	}
+
+	// 逆序执行，保证依赖关系（例如先删索引再删列）
+	for i, j := 0, len(inverse)-1; i < j; i, j = i+1, j-1 {
+		inverse[i], inverse[j] = inverse[j], inverse[i]
+	}
+
+	if len(inverse) > 0 {
+		plan.RollbackDDL = fmt.Sprintf("ALTER TABLE %s %s", QuoteIdentifier(table), strings.Join(inverse, ", "))
+		if _, err := CleanAlterSQL(plan.RollbackDDL); err != nil {
+			plan.Irreversible = append(plan.Irreversible, fmt.Sprintf("逆向语句无法用于 pt-osc: %v", err))
+		}
+	}
+	plan.Revertible = len(plan.Irreversible) == 0 && plan.RollbackDDL != ""
+
+	return plan, nil
+}
+
+// inverseClause 生成单个子句的逆操作；无法回滚时返回原因
+func inverseClause(before *TableDefinition, clause *AlterClause, indexNames map[string]bool) (string, string) {
+	switch clause.Kind {
+	case AlterAddColumn:
+		return "DROP COLUMN " + QuoteIdentifier(clause.Name), ""
+
+	case AlterDropColumn:
+		return "", "删除列会丢失数据，无法自动回滚"
+
+	case AlterModifyColumn:
+		column := before.FindColumn(clause.Name)
+		if column == nil {
+			return "", "执行前表结构中不存在该列"
+		}
+		return strings.TrimSpace(fmt.Sprintf("MODIFY COLUMN %s %s %s", QuoteIdentifier(column.Name), column.Definition, before.ColumnPosition(column.Name))), ""
+
+	case AlterChangeColumn:
+		column := before.FindColumn(clause.Name)
+		if column == nil {
+			return "", "执行前表结构中不存在该列"
+		}
+		return strings.TrimSpace(fmt.Sprintf("CHANGE COLUMN %s %s %s %s", QuoteIdentifier(clause.NewName), QuoteIdentifier(column.Name), column.Definition, before.ColumnPosition(column.Name))), ""
+
+	case AlterColumnDefault:
+		column := before.FindColumn(clause.Name)
+		if column == nil {
+			return "", "执行前表结构中不存在该列"
+		}
+		if column.Default == nil {
+			return fmt.Sprintf("ALTER COLUMN %s DROP DEFAULT", QuoteIdentifier(column.Name)), ""
+		}
+		return fmt.Sprintf("ALTER COLUMN %s SET DEFAULT %s", QuoteIdentifier(column.Name), *column.Default), ""
+
+	case AlterRenameColumn:
+		return fmt.Sprintf("RENAME COLUMN %s TO %s", QuoteIdentifier(clause.NewName), QuoteIdentifier(clause.Name)), ""
+
+	case AlterAddIndex:
+		name := clause.Name
+		if name == "" {
+			name = autoIndexName(clause.Definition, indexNames)
+			if name == "" {
+				return "", "无法推断未命名索引的名称"
+			}
+		}
+		indexNames[strings.ToLower(name)] = true
+		return "DROP INDEX " + QuoteIdentifier(name), ""
+
+	case AlterDropIndex:
+		index := before.FindIndex(clause.Name)
+		if index == nil {
+			return "", "执行前表结构中不存在该索引"
+		}
+		return "ADD " + index.Definition, ""
+
+	case AlterRenameIndex:
+		return fmt.Sprintf("RENAME INDEX %s TO %s", QuoteIdentifier(clause.NewName), QuoteIdentifier(clause.Name)), ""
+
+	case AlterAddPrimaryKey:
+		if pk := before.PrimaryKey(); pk != nil {
+			return "DROP PRIMARY KEY, ADD " + pk.Definition, ""
+		}
+		return "", "pt-online-schema-change 要求表存在主键或唯一键，无法删除新增的主键"
+
+	case AlterDropPrimaryKey:
+		if pk := before.PrimaryKey(); pk != nil {
+			return "ADD " + pk.Definition, ""
+		}
+		return "", "执行前表结构中不存在主键"
+
+	case AlterAddForeignKey:
+		if clause.Name == "" {
+			return "", "未命名外键无法确定名称"
+		}
+		return "DROP FOREIGN KEY " + QuoteIdentifier(clause.Name), ""
+
+	case AlterDropForeignKey:
+		for _, constraint := range before.Constraints {
+			name, _ := readIdentifier(trimPrefixFold(strings.TrimSpace(constraint), "CONSTRAINT"))
+			if strings.EqualFold(name, clause.Name) {
+				return "ADD " + constraint, ""
+			}
+		}
+		return "", "执行前表结构中不存在该外键"
+
+	case AlterTableOption:
+		if clause.Name == "AUTO_INCREMENT" {
+			return "", ""
+		}
+		value, ok := before.Options[clause.Name]
+		if !ok {
+			return "", "执行前表结构中未设置该表选项"
+		}
+		if clause.Name == "COMMENT" {
+			return fmt.Sprintf("COMMENT='%s'", value), ""
+		}
+		if clause.Name == "ENGINE" && strings.EqualFold(value, clause.Definition) {
+			// 重建表（碎片整理），无需回滚
+			return "", ""
+		}
+		return fmt.Sprintf("%s=%s", clause.Name, value), ""
+
+	case AlterConvertCharset:
+		return "", "转换字符集会改写所有字符列，无法自动回滚"
+	}
+
+	return "", "无法识别的子句"
+}
+
+// autoIndexName 按 MySQL 规则推断未命名索引的名称（首列名，冲突时追加 _2、_3 ...）
+func autoIndexName(definition string, existing map[string]bool) string {
+	index := parseIndexElement(definition)
+	if index == nil || len(index.Columns) == 0 {
+		return ""
+	}
+	name := index.Columns[0]
+	if !existing[strings.ToLower(name)] {
+		return name
+	}
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s_%d", name, i)
+		if !existing[strings.ToLower(candidate)] {
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// 结构差异类型
+const (
+	ChangeAdd    = "add"
+	ChangeDrop   = "drop"
+	ChangeModify = "modify"
+)
+
+// SchemaChange 单项结构差异
+type SchemaChange struct {
+	Action string `json:"action"` // add / drop / modify
+	Object string `json:"object"` // column / index / foreign_key / option / partition
+	Name   string `json:"name"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// TableDiff 两个表定义之间的差异与收敛所需的 ALTER
+type TableDiff struct {
+	Changes  []SchemaChange `json:"changes"`
+	Clauses  []string       `json:"clauses"`   // ALTER 子句（逗号分隔部分）
+	AlterSQL string         `json:"alter_sql"` // 完整 ALTER 语句，无差异时为空
+	Warnings []string       `json:"warnings"`
+}
+
+var (
+	intDisplayWidth = regexp.MustCompile(`^(tinyint|smallint|mediumint|int|integer|bigint)\(\d+\)`)
+	whitespaceRun   = regexp.MustCompile(`\s+`)
+)
+
+// DiffTableDefinitions 比较当前表结构与目标表结构，生成将 current 变为 target 的最小 ALTER
+func DiffTableDefinitions(current, target *TableDefinition, table string) *TableDiff {
+	diff := &TableDiff{}
+
+	// 先删索引、外键，再改列，最后加索引，避免依赖冲突
+	var dropKeys, columnClauses, addKeys, optionClauses []string
+
+	// 外键
+	currentFKs := foreignKeyMap(current.Constraints)
+	targetFKs := foreignKeyMap(target.Constraints)
+	for _, name := range sortedKeys(currentFKs) {
+		def := currentFKs[name]
+		if targetDef, ok := targetFKs[name]; !ok || normalizeDefinition(targetDef) != normalizeDefinition(def) {
+			dropKeys = append(dropKeys, "DROP FOREIGN KEY "+QuoteIdentifier(name))
+			if !ok {
+				diff.Changes = append(diff.Changes, SchemaChange{Action: ChangeDrop, Object: "foreign_key", Name: name, Before: def})
+			}
+		}
+	}
+
+	// 索引
+	for _, idx := range current.Indexes {
+		targetIdx := target.FindIndex(idx.Name)
+		if targetIdx != nil && indexSignature(targetIdx) == indexSignature(idx) {
+			continue
+		}
+		if idx.Kind == IndexKindPrimary {
+			dropKeys = append(dropKeys, "DROP PRIMARY KEY")
+		} else {
+			dropKeys = append(dropKeys, "DROP INDEX "+QuoteIdentifier(idx.Name))
+		}
+		if targetIdx == nil {
+			diff.Changes = append(diff.Changes, SchemaChange{Action: ChangeDrop, Object: "index", Name: idx.Name, Before: idx.Definition})
+		} else {
+			diff.Changes = append(diff.Changes, SchemaChange{Action: ChangeModify, Object: "index", Name: idx.Name, Before: idx.Definition, After: targetIdx.Definition})
+		}
+	}
+	for _, idx := range target.Indexes {
+		currentIdx := current.FindIndex(idx.Name)
+		if currentIdx != nil && indexSignature(currentIdx) == indexSignature(idx) {
+			continue
+		}
+		addKeys = append(addKeys, "ADD "+idx.Definition)
+		if currentIdx == nil {
+			diff.Changes = append(diff.Changes, SchemaChange{Action: ChangeAdd, Object: "index", Name: idx.Name, After: idx.Definition})
+		}
+	}
+
+	// 列
+	for _, col := range current.Columns {
+		if target.FindColumn(col.Name) == nil {
+			columnClauses = append(columnClauses, "DROP COLUMN "+QuoteIdentifier(col.Name))
+			diff.Changes = append(diff.Changes, SchemaChange{Action: ChangeDrop, Object: "column", Name: col.Name, Before: col.Definition})
+			diff.Warnings = append(diff.Warnings, fmt.Sprintf("列 %s 将被删除，如为重命名请手动改用 CHANGE COLUMN 以保留数据", col.Name))
+		}
+	}
+	for _, col := range target.Columns {
+		currentCol := current.FindColumn(col.Name)
+		if currentCol == nil {
+			columnClauses = append(columnClauses, strings.TrimSpace(fmt.Sprintf("ADD COLUMN %s %s %s",
+				QuoteIdentifier(col.Name), col.Definition, target.ColumnPosition(col.Name))))
+			diff.Changes = append(diff.Changes, SchemaChange{Action: ChangeAdd, Object: "column", Name: col.Name, After: col.Definition})
+			continue
+		}
+		if columnSignature(currentCol, current, col) != columnSignature(col, target, col) {
+			columnClauses = append(columnClauses, fmt.Sprintf("MODIFY COLUMN %s %s", QuoteIdentifier(col.Name), col.Definition))
+			diff.Changes = append(diff.Changes, SchemaChange{Action: ChangeModify, Object: "column", Name: col.Name, Before: currentCol.Definition, After: col.Definition})
+		}
+	}
+
+	// 新增外键放在最后
+	for _, name := range sortedKeys(targetFKs) {
+		def := targetFKs[name]
+		if currentDef, ok := currentFKs[name]; ok && normalizeDefinition(currentDef) == normalizeDefinition(def) {
+			continue
+		}
+		addKeys = append(addKeys, "ADD "+def)
+		action := ChangeAdd
+		if _, ok := currentFKs[name]; ok {
+			action = ChangeModify
+		}
+		diff.Changes = append(diff.Changes, SchemaChange{Action: action, Object: "foreign_key", Name: name, Before: currentFKs[name], After: def})
+	}
+
+	// 表选项（仅比较目标中显式声明的选项）
+	for _, key := range []string{"ENGINE", "CHARSET", "COLLATE", "ROW_FORMAT", "COMMENT"} {
+		want, ok := target.Options[key]
+		if !ok {
+			continue
+		}
+		have := current.Options[key]
+		if strings.EqualFold(have, want) {
+			continue
+		}
+		switch key {
+		case "COMMENT":
+			optionClauses = append(optionClauses, fmt.Sprintf("COMMENT='%s'", want))
+		case "CHARSET":
+			optionClauses = append(optionClauses, "DEFAULT CHARSET="+want)
+			diff.Warnings = append(diff.Warnings, "修改表默认字符集不会转换已有列，如需转换请使用 CONVERT TO CHARACTER SET")
+		default:
+			optionClauses = append(optionClauses, fmt.Sprintf("%s=%s", key, want))
+		}
+		diff.Changes = append(diff.Changes, SchemaChange{Action: ChangeModify, Object: "option", Name: key, Before: have, After: want})
+	}
+
+	diff.Clauses = append(append(append(dropKeys, columnClauses...), addKeys...), optionClauses...)
+
+	// 分区变更不能与其他子句以逗号连接
+	partitionClause := ""
+	if normalizeDefinition(current.Partition) != normalizeDefinition(target.Partition) {
+		if target.Partition == "" {
+			partitionClause = "REMOVE PARTITIONING"
+		} else {
+			partitionClause = target.Partition
+		}
+		diff.Changes = append(diff.Changes, SchemaChange{Action: ChangeModify, Object: "partition", Name: "PARTITION", Before: current.Partition, After: target.Partition})
+		diff.Warnings = append(diff.Warnings, "分区变更需要重建全表，请确认 pt-online-schema-change 支持该分区语法")
+	}
+
+	if len(diff.Clauses) > 0 || partitionClause != "" {
+		sql := "ALTER TABLE " + QuoteIdentifier(table)
+		if len(diff.Clauses) > 0 {
+			sql += " " + strings.Join(diff.Clauses, ", ")
+		}
+		if partitionClause != "" {
+			sql += " " + partitionClause
+		}
+		diff.AlterSQL = sql
+	}
+
+	return diff
+}
+
+// columnSignature 列定义的规范化签名（忽略整数显示宽度、默认 NULL 等表述差异）
+// 字符集与排序规则仅在目标列显式声明时参与比较
+func columnSignature(col *ColumnDefinition, table *TableDefinition, declared *ColumnDefinition) string {
+	colType := intDisplayWidth.ReplaceAllString(col.Type, "$1")
+
+	def := "NULL"
+	if col.Default != nil {
+		def = strings.Trim(*col.Default, "'")
+		if strings.EqualFold(*col.Default, "NULL") {
+			def = "NULL"
+		}
+	}
+	if !col.Nullable && col.Default == nil {
+		def = ""
+	}
+
+	// 未显式声明的字符集/排序规则继承表默认值
+	charset, collation := "", ""
+	if declared.Charset != "" {
+		charset = col.Charset
+		if charset == "" && isCharacterType(colType) {
+			charset = table.Options["CHARSET"]
+		}
+	}
+	if declared.Collation != "" {
+		collation = col.Collation
+		if collation == "" && isCharacterType(colType) {
+			collation = table.Options["COLLATE"]
+		}
+	}
+
+	return strings.Join([]string{
+		colType,
+		fmt.Sprint(col.Nullable),
+		def,
+		col.Comment,
+		fmt.Sprint(col.AutoIncrement),
+		charset,
+		collation,
+		strings.ToLower(generatedExpression(col.Definition)),
+	}, "|")
+}
+
+// generatedExpression 提取 ON UPDATE / GENERATED 等附加表达式
+func generatedExpression(definition string) string {
+	upper := strings.ToUpper(definition)
+	for _, keyword := range []string{"ON UPDATE ", "GENERATED ALWAYS AS ", " AS ("} {
+		if idx := strings.Index(upper, keyword); idx >= 0 {
+			return normalizeDefinition(definition[idx:])
+		}
+	}
+	return ""
+}
+
+// isCharacterType 是否为字符类型
+func isCharacterType(colType string) bool {
+	for _, prefix := range []string{"char", "varchar", "tinytext", "text", "mediumtext", "longtext", "enum", "set"} {
+		if strings.HasPrefix(colType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// indexSignature 索引的规范化签名
+func indexSignature(idx *IndexDefinition) string {
+	parts := make([]string, len(idx.Parts))
+	for i, p := range idx.Parts {
+		parts[i] = normalizeDefinition(p)
+	}
+	return idx.Kind + "(" + strings.Join(parts, ",") + ")"
+}
+
+// foreignKeyMap 按约束名索引外键定义
+func foreignKeyMap(constraints []string) map[string]string {
+	result := map[string]string{}
+	for _, c := range constraints {
+		if !strings.Contains(strings.ToUpper(c), "FOREIGN KEY") {
+			continue
+		}
+		name, _ := readIdentifier(trimPrefixFold(strings.TrimSpace(c), "CONSTRAINT"))
+		result[name] = c
+	}
+	return result
+}
+
+// sortedKeys 返回排序后的键
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// normalizeDefinition 规范化定义文本：去反引号、压缩空白、转小写
+func normalizeDefinition(s string) string {
+	s = strings.ReplaceAll(s, "`", "")
+	s = whitespaceRun.ReplaceAllString(strings.TrimSpace(s), " ")
+	s = strings.ReplaceAll(s, "( ", "(")
+	s = strings.ReplaceAll(s, " )", ")")
+	s = strings.ReplaceAll(s, ", ", ",")
+	return strings.ToLower(s)
+}
@@ -0,0 +1,287 @@
+package utils
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// loadNearRatio 负载达到阈值的该比例时视为接近阈值
+const loadNearRatio = 0.8
+
+// innodbRowCounters InnoDB 行操作计数器（按两次采样差值换算为每秒速率）
+var innodbRowCounters = []string{"Innodb_rows_read", "Innodb_rows_inserted", "Innodb_rows_updated", "Innodb_rows_deleted"}
+
+// ServerLoad 目标实例的一次负载采样
+type ServerLoad struct {
+	SampledAt         time.Time `json:"sampled_at"`
+	ThreadsRunning    int64     `json:"threads_running"`
+	ThreadsConnected  int64     `json:"threads_connected"`
+	HistoryListLength *int64    `json:"history_list_length,omitempty"` // information_schema.innodb_metrics 不可用时为空
+	ReplicationLag    *int64    `json:"replication_lag,omitempty"`     // 非从库或复制中断时为空
+
+	// InnoDB 行操作速率（行/秒），首次采样无前值时为0
+	RowsReadPerSec     float64 `json:"rows_read_per_sec"`
+	RowsInsertedPerSec float64 `json:"rows_inserted_per_sec"`
+	RowsUpdatedPerSec  float64 `json:"rows_updated_per_sec"`
+	RowsDeletedPerSec  float64 `json:"rows_deleted_per_sec"`
+
+	// 阈值中引用的状态变量当前值（如 Threads_running）
+	Status map[string]int64 `json:"status"`
+}
+
+// LoadThreshold pt-osc --max-load / --critical-load 中的单个阈值
+type LoadThreshold struct {
+	Variable string `json:"variable"`
+	Value    int64  `json:"value"`
+}
+
+// LoadWarning 负载接近或超过阈值的提示
+type LoadWarning struct {
+	Variable  string `json:"variable"`
+	Current   int64  `json:"current"`
+	Threshold int64  `json:"threshold"`
+	Kind      string `json:"kind"` // max_load / critical_load
+	Exceeded  bool   `json:"exceeded"`
+	Message   string `json:"message"`
+}
+
+// ParseLoadThresholds 解析 pt-osc 负载阈值，如 "Threads_running=25,Threads_connected:400"
+// 未给出数值的变量由 pt-osc 按启动时的值自动推算，这里忽略
+func ParseLoadThresholds(spec string) []LoadThreshold {
+	var thresholds []LoadThreshold
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		sep := strings.IndexAny(item, "=:")
+		if sep <= 0 {
+			continue
+		}
+		value, err := strconv.ParseInt(strings.TrimSpace(item[sep+1:]), 10, 64)
+		if err != nil {
+			continue
+		}
+		thresholds = append(thresholds, LoadThreshold{
+			Variable: strings.TrimSpace(item[:sep]),
+			Value:    value,
+		})
+	}
+	return thresholds
+}
+
+// CheckLoadThresholds 对比当前负载与阈值，返回接近（>=80%）或超过阈值的项
+func CheckLoadThresholds(load *ServerLoad, maxLoad, criticalLoad []LoadThreshold) []LoadWarning {
+	var warnings []LoadWarning
+	check := func(kind string, thresholds []LoadThreshold) {
+		for _, threshold := range thresholds {
+			current, ok := load.Status[strings.ToLower(threshold.Variable)]
+			if !ok || threshold.Value <= 0 || float64(current) < float64(threshold.Value)*loadNearRatio {
+				continue
+			}
+			warning := LoadWarning{
+				Variable:  threshold.Variable,
+				Current:   current,
+				Threshold: threshold.Value,
+				Kind:      kind,
+				Exceeded:  current >= threshold.Value,
+			}
+			if warning.Exceeded {
+				warning.Message = fmt.Sprintf("%s 当前为 %d，已达到 %s 阈值 %d", threshold.Variable, current, kind, threshold.Value)
+			} else {
+				warning.Message = fmt.Sprintf("%s 当前为 %d，接近 %s 阈值 %d", threshold.Variable, current, kind, threshold.Value)
+			}
+			warnings = append(warnings, warning)
+		}
+	}
+	check("critical_load", criticalLoad)
+	check("max_load", maxLoad)
+	return warnings
+}
+
+// LoadMonitor 独立的负载监控连接（与 pt-osc 的连接分离）
+type LoadMonitor struct {
+	conn      *DatabaseConnection
+	db        *sql.DB
+	variables []string
+
+	previous     map[string]int64
+	previousTime time.Time
+}
+
+// NewLoadMonitor 打开负载监控连接；extraVariables 为需要额外采集的状态变量（如阈值中引用的变量）
+func NewLoadMonitor(conn *DatabaseConnection, extraVariables []string) (*LoadMonitor, error) {
+	db, err := openDatabase(conn)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+
+	seen := map[string]bool{}
+	var variables []string
+	for _, name := range append([]string{"Threads_running", "Threads_connected"}, append(innodbRowCounters, extraVariables...)...) {
+		if key := strings.ToLower(name); !seen[key] {
+			seen[key] = true
+			variables = append(variables, name)
+		}
+	}
+	sort.Strings(variables)
+
+	return &LoadMonitor{conn: conn, db: db, variables: variables}, nil
+}
+
+// Close 关闭监控连接
+func (m *LoadMonitor) Close() error {
+	return m.db.Close()
+}
+
+// Sample 采集一次负载
+func (m *LoadMonitor) Sample(ctx context.Context) (*ServerLoad, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(maxInt(m.conn.ConnectTimeout, 5))*time.Second)
+	defer cancel()
+
+	status, err := m.globalStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	load := &ServerLoad{
+		SampledAt:        now,
+		ThreadsRunning:   status["threads_running"],
+		ThreadsConnected: status["threads_connected"],
+		Status:           status,
+	}
+
+	// 行操作速率：与上次采样的计数差
+	if m.previous != nil {
+		if seconds := now.Sub(m.previousTime).Seconds(); seconds > 0 {
+			rate := func(name string) float64 {
+				delta := status[name] - m.previous[name]
+				if delta < 0 {
+					return 0
+				}
+				return float64(delta) / seconds
+			}
+			load.RowsReadPerSec = rate("innodb_rows_read")
+			load.RowsInsertedPerSec = rate("innodb_rows_inserted")
+			load.RowsUpdatedPerSec = rate("innodb_rows_updated")
+			load.RowsDeletedPerSec = rate("innodb_rows_deleted")
+		}
+	}
+	m.previous = status
+	m.previousTime = now
+
+	var historyLength int64
+	err = m.db.QueryRowContext(ctx,
+		"SELECT count FROM information_schema.innodb_metrics WHERE name = 'trx_rseg_history_len'").Scan(&historyLength)
+	if err == nil {
+		load.HistoryListLength = &historyLength
+	}
+
+	load.ReplicationLag = m.replicationLag(ctx)
+	return load, nil
+}
+
+// globalStatus 读取所需的全局状态变量（键为小写变量名）
+func (m *LoadMonitor) globalStatus(ctx context.Context) (map[string]int64, error) {
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(m.variables)), ", ")
+	args := make([]interface{}, 0, len(m.variables))
+	for _, name := range m.variables {
+		args = append(args, name)
+	}
+
+	rows, err := m.db.QueryContext(ctx, "SHOW GLOBAL STATUS WHERE Variable_name IN ("+placeholders+")", args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询实例状态失败: %v", err)
+	}
+	defer rows.Close()
+
+	status := make(map[string]int64, len(m.variables))
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			return nil, err
+		}
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			status[strings.ToLower(name)] = n
+		}
+	}
+	return status, rows.Err()
+}
+
+// replicationLag 读取复制延迟（兼容 SHOW REPLICA STATUS 与旧版 SHOW SLAVE STATUS）
+func (m *LoadMonitor) replicationLag(ctx context.Context) *int64 {
+	for _, query := range []string{"SHOW REPLICA STATUS", "SHOW SLAVE STATUS"} {
+		rows, err := m.db.QueryContext(ctx, query)
+		if err != nil {
+			continue
+		}
+		// 查询成功即不再回退；非从库时结果为空
+		lag := scanReplicationLag(rows)
+		rows.Close()
+		return lag
+	}
+	return nil
+}
+
+// scanReplicationLag 从复制状态结果中取 Seconds_Behind_Source / Seconds_Behind_Master
+func scanReplicationLag(rows *sql.Rows) *int64 {
+	columns, err := rows.Columns()
+	if err != nil || !rows.Next() {
+		return nil
+	}
+
+	values := make([]sql.RawBytes, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return nil
+	}
+
+	for i, column := range columns {
+		if column != "Seconds_Behind_Source" && column != "Seconds_Behind_Master" {
+			continue
+		}
+		// 复制线程未运行时为 NULL
+		if lag, err := strconv.ParseInt(string(values[i]), 10, 64); err == nil {
+			return &lag
+		}
+		return nil
+	}
+	return nil
+}
+
+// SampleServerLoad 使用临时连接采集一次负载（用于执行前检查）
+func SampleServerLoad(conn *DatabaseConnection, extraVariables []string) (*ServerLoad, error) {
+	monitor, err := NewLoadMonitor(conn, extraVariables)
+	if err != nil {
+		return nil, err
+	}
+	defer monitor.Close()
+	return monitor.Sample(context.Background())
+}
+
+// ThresholdVariables 阈值中引用的状态变量名
+func ThresholdVariables(groups ...[]LoadThreshold) []string {
+	var names []string
+	for _, thresholds := range groups {
+		for _, threshold := range thresholds {
+			names = append(names, threshold.Variable)
+		}
+	}
+	return names
+}
+
+// maxInt 返回较大值
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
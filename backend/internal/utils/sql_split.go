@@ -0,0 +1,140 @@
+package utils
+
+import "strings"
+
+// splitTopLevel 按顶层分隔符拆分SQL片段（忽略括号、引号、反引号内部的分隔符）
+func splitTopLevel(s string, sep byte) []string {
+	var parts []string
+	depth := 0
+	var quote byte
+	start := 0
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if quote != 0 {
+			if c == '\\' && quote != '`' && i+1 < len(s) {
+				i++
+				continue
+			}
+			if c == quote {
+				// 连续两个引号表示转义
+				if i+1 < len(s) && s[i+1] == quote {
+					i++
+					continue
+				}
+				quote = 0
+			}
+			continue
+		}
+
+		switch c {
+		case '\'', '"', '`':
+			quote = c
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+		case sep:
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+
+	if tail := strings.TrimSpace(s[start:]); tail != "" {
+		parts = append(parts, tail)
+	}
+	return parts
+}
+
+// matchParen 返回与 s[open] 处左括号匹配的右括号下标，未找到返回 -1
+func matchParen(s string, open int) int {
+	depth := 0
+	var quote byte
+	for i := open; i < len(s); i++ {
+		c := s[i]
+		if quote != 0 {
+			if c == '\\' && quote != '`' && i+1 < len(s) {
+				i++
+				continue
+			}
+			if c == quote {
+				if i+1 < len(s) && s[i+1] == quote {
+					i++
+					continue
+				}
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '\'', '"', '`':
+			quote = c
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// readIdentifier 读取开头的标识符（支持反引号），返回标识符与剩余部分
+func readIdentifier(s string) (string, string) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", ""
+	}
+	if s[0] == '`' {
+		for i := 1; i < len(s); i++ {
+			if s[i] == '`' {
+				if i+1 < len(s) && s[i+1] == '`' {
+					i++
+					continue
+				}
+				return strings.ReplaceAll(s[1:i], "``", "`"), strings.TrimSpace(s[i+1:])
+			}
+		}
+		return strings.Trim(s, "`"), ""
+	}
+	end := strings.IndexAny(s, " \t\r\n(,")
+	if end < 0 {
+		return s, ""
+	}
+	return s[:end], strings.TrimSpace(s[end:])
+}
+
+// unquoteIdentifier 去除标识符两侧的反引号
+func unquoteIdentifier(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && s[0] == '`' && s[len(s)-1] == '`' {
+		return strings.ReplaceAll(s[1:len(s)-1], "``", "`")
+	}
+	return s
+}
+
+// hasPrefixFold 忽略大小写判断前缀（要求前缀后为空白或结束，避免误匹配标识符）
+func hasPrefixFold(s, prefix string) bool {
+	if len(s) < len(prefix) || !strings.EqualFold(s[:len(prefix)], prefix) {
+		return false
+	}
+	if len(s) == len(prefix) {
+		return true
+	}
+	next := s[len(prefix)]
+	return next == ' ' || next == '\t' || next == '\n' || next == '\r' || next == '(' || next == '=' || next == '`'
+}
+
+// trimPrefixFold 忽略大小写去除前缀
+func trimPrefixFold(s, prefix string) string {
+	if hasPrefixFold(s, prefix) {
+		return strings.TrimSpace(s[len(prefix):])
+	}
+	return s
+}
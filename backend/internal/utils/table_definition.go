@@ -0,0 +1,302 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// 索引类型
+const (
+	IndexKindPrimary  = "PRIMARY"
+	IndexKindUnique   = "UNIQUE"
+	IndexKindIndex    = "INDEX"
+	IndexKindFulltext = "FULLTEXT"
+	IndexKindSpatial  = "SPATIAL"
+)
+
+// TableDefinition 解析后的建表语句
+type TableDefinition struct {
+	Name        string              `json:"name"`
+	Columns     []*ColumnDefinition `json:"columns"`
+	Indexes     []*IndexDefinition  `json:"indexes"`
+	Constraints []string            `json:"constraints"` // 外键、CHECK 等原样保留
+	Options     map[string]string   `json:"options"`     // ENGINE、CHARSET、COLLATE、COMMENT 等（键为大写）
+	Partition   string              `json:"partition"`   // PARTITION BY ... 子句
+}
+
+// ColumnDefinition 列定义
+type ColumnDefinition struct {
+	Name          string  `json:"name"`
+	Type          string  `json:"type"`       // 如 varchar(64)、bigint unsigned
+	Definition    string  `json:"definition"` // 列名之后的完整定义
+	Nullable      bool    `json:"nullable"`
+	Default       *string `json:"default"`
+	Comment       string  `json:"comment"`
+	Charset       string  `json:"charset"`
+	Collation     string  `json:"collation"`
+	AutoIncrement bool    `json:"auto_increment"`
+}
+
+// IndexDefinition 索引定义
+type IndexDefinition struct {
+	Name       string   `json:"name"`
+	Kind       string   `json:"kind"`       // PRIMARY/UNIQUE/INDEX/FULLTEXT/SPATIAL
+	Columns    []string `json:"columns"`    // 列名（不含前缀长度）
+	Parts      []string `json:"parts"`      // 原始索引列（可能含前缀长度、排序）
+	Definition string   `json:"definition"` // 完整定义文本，如 UNIQUE KEY `uk_a` (`a`)
+}
+
+var (
+	createTablePrefix = regexp.MustCompile(`(?is)^\s*CREATE\s+(?:TEMPORARY\s+)?TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?`)
+	typePattern       = regexp.MustCompile(`(?i)^([a-z]+(?:\s*\([^)]*\))?(?:\s+(?:unsigned|signed|zerofill))*)`)
+	defaultPattern    = regexp.MustCompile(`(?is)\bDEFAULT\s+('(?:[^'\\]|\\.|'')*'|\([^)]*\)|[^\s,]+(?:\(\))?)`)
+	commentPattern    = regexp.MustCompile(`(?is)\bCOMMENT\s+'((?:[^'\\]|\\.|'')*)'`)
+	charsetPattern    = regexp.MustCompile(`(?i)\b(?:CHARACTER\s+SET|CHARSET)\s+(\w+)`)
+	collatePattern    = regexp.MustCompile(`(?i)\bCOLLATE\s+(\w+)`)
+	notNullPattern    = regexp.MustCompile(`(?i)\bNOT\s+NULL\b`)
+	autoIncPattern    = regexp.MustCompile(`(?i)\bAUTO_INCREMENT\b`)
+	tableOptionRegex  = regexp.MustCompile(`(?is)(DEFAULT\s+)?(ENGINE|CHARACTER\s+SET|CHARSET|COLLATE|COMMENT|AUTO_INCREMENT|ROW_FORMAT|KEY_BLOCK_SIZE)\s*=?\s*('(?:[^'\\]|\\.|'')*'|[^\s]+)`)
+	versionComment    = regexp.MustCompile(`(?s)/\*!\d*\s*(.*?)\s*\*/`)
+)
+
+// ParseCreateTable 解析 CREATE TABLE 语句
+func ParseCreateTable(createSQL string) (*TableDefinition, error) {
+	sql := strings.TrimSpace(createSQL)
+	sql = strings.TrimSuffix(sql, ";")
+
+	loc := createTablePrefix.FindStringIndex(sql)
+	if loc == nil {
+		return nil, fmt.Errorf("不是有效的CREATE TABLE语句")
+	}
+	rest := sql[loc[1]:]
+
+	name, rest := readIdentifier(rest)
+	// 去掉库名前缀 db.table
+	if strings.HasPrefix(rest, ".") {
+		name, rest = readIdentifier(rest[1:])
+	} else if idx := strings.Index(name, "."); idx >= 0 && !strings.HasPrefix(strings.TrimSpace(sql[loc[1]:]), "`") {
+		name = name[idx+1:]
+	}
+
+	open := strings.Index(rest, "(")
+	if open < 0 {
+		return nil, fmt.Errorf("CREATE TABLE语句缺少列定义")
+	}
+	closeIdx := matchParen(rest, open)
+	if closeIdx < 0 {
+		return nil, fmt.Errorf("CREATE TABLE语句括号不匹配")
+	}
+
+	def := &TableDefinition{
+		Name:    name,
+		Options: map[string]string{},
+	}
+
+	for _, element := range splitTopLevel(rest[open+1:closeIdx], ',') {
+		if element == "" {
+			continue
+		}
+		if index := parseIndexElement(element); index != nil {
+			def.Indexes = append(def.Indexes, index)
+			continue
+		}
+		if isConstraintElement(element) {
+			def.Constraints = append(def.Constraints, element)
+			continue
+		}
+		column, err := ParseColumnDefinition(element)
+		if err != nil {
+			return nil, err
+		}
+		def.Columns = append(def.Columns, column)
+	}
+
+	parseTableOptions(strings.TrimSpace(rest[closeIdx+1:]), def)
+
+	return def, nil
+}
+
+// ParseColumnDefinition 解析单个列定义（`name` type ...）
+func ParseColumnDefinition(element string) (*ColumnDefinition, error) {
+	name, definition := readIdentifier(element)
+	if name == "" || definition == "" {
+		return nil, fmt.Errorf("无法解析列定义: %s", element)
+	}
+
+	column := &ColumnDefinition{
+		Name:       name,
+		Definition: definition,
+		Nullable:   true,
+	}
+
+	if m := typePattern.FindStringSubmatch(definition); m != nil {
+		column.Type = strings.ToLower(strings.Join(strings.Fields(m[1]), " "))
+		column.Type = strings.ReplaceAll(column.Type, " (", "(")
+	}
+	if notNullPattern.MatchString(definition) {
+		column.Nullable = false
+	}
+	if m := defaultPattern.FindStringSubmatch(definition); m != nil {
+		value := m[1]
+		column.Default = &value
+	}
+	if m := commentPattern.FindStringSubmatch(definition); m != nil {
+		column.Comment = m[1]
+	}
+	if m := charsetPattern.FindStringSubmatch(definition); m != nil {
+		column.Charset = strings.ToLower(m[1])
+	}
+	if m := collatePattern.FindStringSubmatch(definition); m != nil {
+		column.Collation = strings.ToLower(m[1])
+	}
+	column.AutoIncrement = autoIncPattern.MatchString(definition)
+
+	return column, nil
+}
+
+// parseIndexElement 解析索引定义，非索引元素返回 nil
+func parseIndexElement(element string) *IndexDefinition {
+	s := strings.TrimSpace(element)
+
+	// CONSTRAINT [symbol] PRIMARY KEY / UNIQUE
+	if hasPrefixFold(s, "CONSTRAINT") {
+		rest := trimPrefixFold(s, "CONSTRAINT")
+		if !hasPrefixFold(rest, "PRIMARY") && !hasPrefixFold(rest, "UNIQUE") && !hasPrefixFold(rest, "FOREIGN") && !hasPrefixFold(rest, "CHECK") {
+			_, rest = readIdentifier(rest)
+		}
+		if hasPrefixFold(rest, "PRIMARY") || hasPrefixFold(rest, "UNIQUE") {
+			s = rest
+		} else {
+			return nil
+		}
+	}
+
+	var kind string
+	switch {
+	case hasPrefixFold(s, "PRIMARY"):
+		kind = IndexKindPrimary
+		s = trimPrefixFold(trimPrefixFold(s, "PRIMARY"), "KEY")
+	case hasPrefixFold(s, "UNIQUE"):
+		kind = IndexKindUnique
+		s = trimPrefixFold(s, "UNIQUE")
+		s = trimPrefixFold(trimPrefixFold(s, "INDEX"), "KEY")
+	case hasPrefixFold(s, "FULLTEXT"):
+		kind = IndexKindFulltext
+		s = trimPrefixFold(s, "FULLTEXT")
+		s = trimPrefixFold(trimPrefixFold(s, "INDEX"), "KEY")
+	case hasPrefixFold(s, "SPATIAL"):
+		kind = IndexKindSpatial
+		s = trimPrefixFold(s, "SPATIAL")
+		s = trimPrefixFold(trimPrefixFold(s, "INDEX"), "KEY")
+	case hasPrefixFold(s, "INDEX"), hasPrefixFold(s, "KEY"):
+		kind = IndexKindIndex
+		s = trimPrefixFold(trimPrefixFold(s, "INDEX"), "KEY")
+	default:
+		return nil
+	}
+
+	index := &IndexDefinition{Kind: kind, Definition: strings.TrimSpace(element)}
+	if kind == IndexKindPrimary {
+		index.Name = "PRIMARY"
+	} else if !strings.HasPrefix(s, "(") {
+		index.Name, s = readIdentifier(s)
+	}
+
+	// USING BTREE 可能出现在列列表之前
+	if hasPrefixFold(s, "USING") {
+		s = trimPrefixFold(s, "USING")
+		_, s = readIdentifier(s)
+	}
+
+	open := strings.Index(s, "(")
+	if open < 0 {
+		return index
+	}
+	closeIdx := matchParen(s, open)
+	if closeIdx < 0 {
+		return index
+	}
+	for _, part := range splitTopLevel(s[open+1:closeIdx], ',') {
+		index.Parts = append(index.Parts, part)
+		name, _ := readIdentifier(part)
+		index.Columns = append(index.Columns, name)
+	}
+
+	return index
+}
+
+// isConstraintElement 判断是否为外键/CHECK等约束元素
+func isConstraintElement(element string) bool {
+	s := strings.TrimSpace(element)
+	return hasPrefixFold(s, "CONSTRAINT") || hasPrefixFold(s, "FOREIGN") || hasPrefixFold(s, "CHECK")
+}
+
+// parseTableOptions 解析表选项与分区子句
+func parseTableOptions(tail string, def *TableDefinition) {
+	// 版本注释中的分区定义 /*!50100 PARTITION BY ... */
+	tail = versionComment.ReplaceAllString(tail, "$1")
+
+	upper := strings.ToUpper(tail)
+	if idx := strings.Index(upper, "PARTITION BY"); idx >= 0 {
+		def.Partition = strings.TrimSpace(tail[idx:])
+		tail = tail[:idx]
+	}
+
+	for _, m := range tableOptionRegex.FindAllStringSubmatch(tail, -1) {
+		key := strings.ToUpper(strings.Join(strings.Fields(m[2]), " "))
+		if key == "CHARACTER SET" {
+			key = "CHARSET"
+		}
+		value := m[3]
+		if key == "COMMENT" {
+			value = strings.Trim(value, "'")
+		} else {
+			value = strings.ToLower(value)
+		}
+		def.Options[key] = value
+	}
+}
+
+// FindColumn 按名称查找列（忽略大小写）
+func (t *TableDefinition) FindColumn(name string) *ColumnDefinition {
+	for _, c := range t.Columns {
+		if strings.EqualFold(c.Name, name) {
+			return c
+		}
+	}
+	return nil
+}
+
+// FindIndex 按名称查找索引（忽略大小写）
+func (t *TableDefinition) FindIndex(name string) *IndexDefinition {
+	for _, idx := range t.Indexes {
+		if strings.EqualFold(idx.Name, name) {
+			return idx
+		}
+	}
+	return nil
+}
+
+// PrimaryKey 返回主键定义
+func (t *TableDefinition) PrimaryKey() *IndexDefinition {
+	for _, idx := range t.Indexes {
+		if idx.Kind == IndexKindPrimary {
+			return idx
+		}
+	}
+	return nil
+}
+
+// ColumnPosition 返回列在表中的位置子句（FIRST 或 AFTER `prev`）
+func (t *TableDefinition) ColumnPosition(name string) string {
+	for i, c := range t.Columns {
+		if strings.EqualFold(c.Name, name) {
+			if i == 0 {
+				return "FIRST"
+			}
+			return "AFTER " + QuoteIdentifier(t.Columns[i-1].Name)
+		}
+	}
+	return ""
+}
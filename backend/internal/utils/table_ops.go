@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// TableRename 表重命名对
+type TableRename struct {
+	From string
+	To   string
+}
+
+// openDatabase 打开数据库连接
+func openDatabase(conn *DatabaseConnection) (*sql.DB, error) {
+	dsn, err := buildDSN(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("创建数据库连接失败: %v", err)
+	}
+	return db, nil
+}
+
+// TableExists 检查表是否存在
+func TableExists(conn *DatabaseConnection, database string, table string) (bool, error) {
+	db, err := openDatabase(conn)
+	if err != nil {
+		return false, err
+	}
+	defer db.Close()
+
+	ctx, cancel := createTimeoutContext(conn.ConnectTimeout)
+	defer cancel()
+
+	var count int
+	query := "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = ? AND table_name = ?"
+	if err := db.QueryRowContext(ctx, query, database, table).Scan(&count); err != nil {
+		return false, fmt.Errorf("查询表信息失败: %v", err)
+	}
+	return count > 0, nil
+}
+
+// NextOldTableName 按 pt-online-schema-change 的规则预测本次保留的旧表名：
+// 从 _<table>_old 开始，已存在时前缀追加下划线，取第一个不存在的名称
+func NextOldTableName(conn *DatabaseConnection, database string, table string) (string, error) {
+	name := table + "_old"
+	for i := 0; i < 10; i++ {
+		name = "_" + name
+		if len(name) > 64 {
+			break
+		}
+		exists, err := TableExists(conn, database, name)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("无法为表 %s 确定旧表名", table)
+}
+
+// ParseOldTableName 从 pt-osc --print 输出的 RENAME TABLE 语句中解析旧表名，未找到时返回空串
+func ParseOldTableName(output, database, table string) string {
+	pattern := regexp.MustCompile("RENAME TABLE `" + regexp.QuoteMeta(database) + "`\\.`" + regexp.QuoteMeta(table) +
+		"` TO `" + regexp.QuoteMeta(database) + "`\\.`([^`]+)`")
+	if match := pattern.FindStringSubmatch(output); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// RenameTables 在一条 RENAME TABLE 语句中原子地完成多个重命名
+func RenameTables(conn *DatabaseConnection, database string, renames []TableRename) error {
+	if len(renames) == 0 {
+		return nil
+	}
+
+	db, err := openDatabase(conn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx, cancel := createTimeoutContext(conn.ConnectTimeout)
+	defer cancel()
+
+	pairs := make([]string, 0, len(renames))
+	for _, r := range renames {
+		pairs = append(pairs, fmt.Sprintf("%s.%s TO %s.%s",
+			QuoteIdentifier(database), QuoteIdentifier(r.From),
+			QuoteIdentifier(database), QuoteIdentifier(r.To)))
+	}
+
+	if _, err := db.ExecContext(ctx, "RENAME TABLE "+strings.Join(pairs, ", ")); err != nil {
+		return fmt.Errorf("重命名表失败: %v", err)
+	}
+	return nil
+}
+
+// DropTable 删除表（表不存在时忽略）
+func DropTable(conn *DatabaseConnection, database string, table string) error {
+	db, err := openDatabase(conn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx, cancel := createTimeoutContext(conn.ConnectTimeout)
+	defer cancel()
+
+	query := fmt.Sprintf("DROP TABLE IF EXISTS %s.%s", QuoteIdentifier(database), QuoteIdentifier(table))
+	if _, err := db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("删除表失败: %v", err)
+	}
+	return nil
+}
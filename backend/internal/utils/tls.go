@@ -0,0 +1,219 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// TLS 连接级别（与 MySQL 客户端 --ssl-mode 对应）
+const (
+	SSLModeDisabled       = "disabled"        // 不使用TLS
+	SSLModePreferred      = "preferred"       // 服务端支持时使用TLS，否则回退明文
+	SSLModeRequired       = "required"        // 必须使用TLS，不校验证书
+	SSLModeVerifyCA       = "verify_ca"       // 必须使用TLS，校验证书链
+	SSLModeVerifyIdentity = "verify_identity" // 必须使用TLS，校验证书链与主机名
+)
+
+// pt-osc 容器内 TLS 文件路径
+const (
+	ptTLSDir         = "/tmp/mysqler-tls"
+	ptTLSDefaultFile = ptTLSDir + "/my.cnf"
+	ptTLSKeyFile     = "/tmp/mysqler-client-key.pem" // 私钥在容器启动前经 docker cp 写入
+)
+
+// TLSMode 返回生效的TLS级别（兼容旧版 UseSSL 开关）
+func (c *DatabaseConnection) TLSMode() string {
+	if c.SSLMode != "" {
+		return c.SSLMode
+	}
+	if c.UseSSL {
+		// 旧版 UseSSL 对应驱动的 tls=true，即完整校验
+		return SSLModeVerifyIdentity
+	}
+	return SSLModeDisabled
+}
+
+// TLSEnabled 是否启用TLS
+func (c *DatabaseConnection) TLSEnabled() bool {
+	return c.TLSMode() != SSLModeDisabled
+}
+
+// hasCustomTLSMaterial 是否配置了自定义CA或客户端证书
+func (c *DatabaseConnection) hasCustomTLSMaterial() bool {
+	return c.SSLCA != "" || c.SSLCert != "" || c.SSLKey != "" || c.SSLServerName != ""
+}
+
+// registerTLSConfig 向 go-sql-driver 注册命名TLS配置，返回配置名
+func registerTLSConfig(conn *DatabaseConnection) (string, error) {
+	mode := conn.TLSMode()
+	switch mode {
+	case SSLModeDisabled:
+		return "", nil
+	case SSLModePreferred:
+		if !conn.hasCustomTLSMaterial() {
+			return "preferred", nil
+		}
+	case SSLModeVerifyIdentity:
+		if !conn.hasCustomTLSMaterial() {
+			return "true", nil
+		}
+	}
+
+	tlsConfig, err := buildTLSConfig(conn)
+	if err != nil {
+		return "", err
+	}
+
+	// 以配置内容的哈希命名，相同配置复用同一个注册项
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		mode, conn.Host, conn.SSLServerName, conn.SSLCA, conn.SSLCert, conn.SSLKey,
+	}, "\x00")))
+	name := "mysqler-" + hex.EncodeToString(sum[:8])
+
+	if err := mysql.RegisterTLSConfig(name, tlsConfig); err != nil {
+		return "", fmt.Errorf("注册TLS配置失败: %v", err)
+	}
+	return name, nil
+}
+
+// buildTLSConfig 根据连接配置构建 tls.Config
+func buildTLSConfig(conn *DatabaseConnection) (*tls.Config, error) {
+	mode := conn.TLSMode()
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: conn.SSLServerName,
+	}
+	if tlsConfig.ServerName == "" {
+		tlsConfig.ServerName = conn.Host
+	}
+
+	var rootCAs *x509.CertPool
+	if conn.SSLCA != "" {
+		rootCAs = x509.NewCertPool()
+		if !rootCAs.AppendCertsFromPEM([]byte(conn.SSLCA)) {
+			return nil, fmt.Errorf("CA证书格式无效")
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+
+	if conn.SSLCert != "" || conn.SSLKey != "" {
+		if conn.SSLCert == "" || conn.SSLKey == "" {
+			return nil, fmt.Errorf("客户端证书与私钥必须同时提供")
+		}
+		cert, err := tls.X509KeyPair([]byte(conn.SSLCert), []byte(conn.SSLKey))
+		if err != nil {
+			return nil, fmt.Errorf("客户端证书或私钥无效: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	switch mode {
+	case SSLModePreferred, SSLModeRequired:
+		tlsConfig.InsecureSkipVerify = true
+	case SSLModeVerifyCA:
+		// 仅校验证书链，不校验主机名
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			return verifyCertificateChain(rawCerts, rootCAs)
+		}
+	case SSLModeVerifyIdentity:
+		// 使用标准校验（证书链 + ServerName）
+	default:
+		return nil, fmt.Errorf("不支持的TLS级别: %s", mode)
+	}
+
+	return tlsConfig, nil
+}
+
+// verifyCertificateChain 校验服务端证书链（不校验主机名）
+func verifyCertificateChain(rawCerts [][]byte, roots *x509.CertPool) error {
+	if len(rawCerts) == 0 {
+		return fmt.Errorf("服务端未提供证书")
+	}
+
+	certs := make([]*x509.Certificate, 0, len(rawCerts))
+	for _, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return fmt.Errorf("解析服务端证书失败: %v", err)
+		}
+		certs = append(certs, cert)
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+	})
+	return err
+}
+
+// PTTLSEnvironment 返回 pt-osc 容器所需的TLS环境变量（仅公开的证书，私钥见 PTTLSFiles）
+func PTTLSEnvironment(conn *DatabaseConnection) map[string]string {
+	env := map[string]string{}
+	if conn == nil || !conn.TLSEnabled() {
+		return env
+	}
+	if conn.SSLCA != "" {
+		env["MYSQLER_SSL_CA"] = conn.SSLCA
+	}
+	if conn.SSLCert != "" {
+		env["MYSQLER_SSL_CERT"] = conn.SSLCert
+	}
+	return env
+}
+
+// PTTLSFiles 返回启动前写入 pt-osc 容器的TLS文件（私钥不经命令行与环境变量传递）
+func PTTLSFiles(conn *DatabaseConnection) map[string]string {
+	files := map[string]string{}
+	if conn == nil || !conn.TLSEnabled() || conn.SSLKey == "" {
+		return files
+	}
+	files[ptTLSKeyFile] = conn.SSLKey
+	return files
+}
+
+// ptTLSPreamble 生成容器内写入证书与 option file 的前置脚本
+func ptTLSPreamble(conn *DatabaseConnection) string {
+	lines := []string{"[client]", "ssl-mode=" + strings.ToUpper(conn.TLSMode())}
+
+	var parts []string
+	parts = append(parts, fmt.Sprintf("mkdir -p %s", ptTLSDir))
+	if conn.SSLCA != "" {
+		parts = append(parts, fmt.Sprintf(`printf '%%s\n' "$MYSQLER_SSL_CA" > %s/ca.pem`, ptTLSDir))
+		lines = append(lines, fmt.Sprintf("ssl-ca=%s/ca.pem", ptTLSDir))
+	}
+	if conn.SSLCert != "" {
+		parts = append(parts, fmt.Sprintf(`printf '%%s\n' "$MYSQLER_SSL_CERT" > %s/client-cert.pem`, ptTLSDir))
+		lines = append(lines, fmt.Sprintf("ssl-cert=%s/client-cert.pem", ptTLSDir))
+	}
+	if conn.SSLKey != "" {
+		lines = append(lines, "ssl-key="+ptTLSKeyFile)
+	}
+	parts = append(parts, fmt.Sprintf(`printf '%s\n' > %s`, strings.Join(lines, `\n`), ptTLSDefaultFile))
+
+	return strings.Join(parts, " && ")
+}
+
+// ptTLSDSNOptions 返回 pt-osc DSN 中的TLS选项（s=mysql_ssl，F=option file）
+// preferred 不强制TLS，由 option file 中的 ssl-mode 决定是否回退明文
+func ptTLSDSNOptions(conn *DatabaseConnection) string {
+	if conn == nil || !conn.TLSEnabled() {
+		return ""
+	}
+	if conn.TLSMode() == SSLModePreferred {
+		return fmt.Sprintf(",F=%s", ptTLSDefaultFile)
+	}
+	return fmt.Sprintf(",F=%s,s=1", ptTLSDefaultFile)
+}
@@ -0,0 +1,266 @@
+package utils
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 切换保护动作（等待超时后）
+const (
+	CutoverTimeoutProceed = "proceed" // 超时后照常切换
+	CutoverTimeoutAbort   = "abort"   // 超时后中止执行
+)
+
+// pt-osc 容器内切换保护插件路径
+const (
+	ptGuardDir    = "/tmp/mysqler-guard"
+	ptGuardPlugin = ptGuardDir + "/cutover_guard.pl"
+)
+
+// CutoverGuardLogPrefix 切换保护插件输出日志的前缀
+const CutoverGuardLogPrefix = "MySQLer cut-over guard:"
+
+// mdlInstrument 元数据锁统计所需的 performance_schema instrument
+const mdlInstrument = "wait/lock/metadata/sql/mdl"
+
+// BlockingTransaction 可能阻塞切换的长事务
+type BlockingTransaction struct {
+	ThreadID     int64     `json:"thread_id"`
+	User         string    `json:"user"`
+	Host         string    `json:"host"`
+	Command      string    `json:"command"`
+	State        string    `json:"state"`
+	Query        string    `json:"query"`
+	TrxStarted   time.Time `json:"trx_started"`
+	TrxSeconds   int64     `json:"trx_seconds"`
+	RowsLocked   int64     `json:"rows_locked"`
+	RowsModified int64     `json:"rows_modified"`
+	HoldsTable   *bool     `json:"holds_table"` // 是否持有目标表元数据锁，metadata_locks 不可用时为空
+}
+
+// TransactionGuardReport 目标表的长事务检查结果
+type TransactionGuardReport struct {
+	CheckedAt              time.Time             `json:"checked_at"`
+	Database               string                `json:"database"`
+	Table                  string                `json:"table"`
+	ThresholdSeconds       int                   `json:"threshold_seconds"`
+	MetadataLocksAvailable bool                  `json:"metadata_locks_available"`
+	Transactions           []BlockingTransaction `json:"transactions"`
+	WaitingSessions        int                   `json:"waiting_sessions"` // 正在等待该表元数据锁的会话数
+}
+
+// Blocked 是否存在可能阻塞切换的长事务
+func (r *TransactionGuardReport) Blocked() bool {
+	return len(r.Transactions) > 0
+}
+
+// CheckTransactionGuard 使用临时连接检查目标表上的长事务
+func CheckTransactionGuard(conn *DatabaseConnection, database, table string, minSeconds int) (*TransactionGuardReport, error) {
+	db, err := openDatabase(conn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	ctx, cancel := createTimeoutContext(conn.ConnectTimeout)
+	defer cancel()
+
+	return findBlockingTransactions(ctx, db, database, table, minSeconds)
+}
+
+// TransactionGuard 在负载监控连接上检查目标表上的长事务
+func (m *LoadMonitor) TransactionGuard(ctx context.Context, database, table string, minSeconds int) (*TransactionGuardReport, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(maxInt(m.conn.ConnectTimeout, 5))*time.Second)
+	defer cancel()
+	return findBlockingTransactions(ctx, m.db, database, table, minSeconds)
+}
+
+// findBlockingTransactions 查询运行超过 minSeconds 的事务，并结合 metadata_locks 判断是否涉及目标表
+// metadata_locks 不可用（如 5.7 未开启 mdl instrument）时返回全部长事务
+func findBlockingTransactions(ctx context.Context, db *sql.DB, database, table string, minSeconds int) (*TransactionGuardReport, error) {
+	report := &TransactionGuardReport{
+		CheckedAt:        time.Now(),
+		Database:         database,
+		Table:            table,
+		ThresholdSeconds: minSeconds,
+		Transactions:     []BlockingTransaction{},
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT trx.trx_mysql_thread_id, IFNULL(p.user, ''), IFNULL(p.host, ''), IFNULL(p.command, ''),
+			IFNULL(p.state, ''), IFNULL(p.info, ''), trx.trx_started,
+			TIMESTAMPDIFF(SECOND, trx.trx_started, NOW()), trx.trx_rows_locked, trx.trx_rows_modified
+		FROM information_schema.innodb_trx trx
+		LEFT JOIN information_schema.processlist p ON p.id = trx.trx_mysql_thread_id
+		WHERE trx.trx_started <= NOW() - INTERVAL ? SECOND AND trx.trx_mysql_thread_id <> CONNECTION_ID()
+		ORDER BY trx.trx_started`, minSeconds)
+	if err != nil {
+		return nil, fmt.Errorf("查询长事务失败: %v", err)
+	}
+	var candidates []BlockingTransaction
+	for rows.Next() {
+		var trx BlockingTransaction
+		if err := rows.Scan(&trx.ThreadID, &trx.User, &trx.Host, &trx.Command, &trx.State, &trx.Query,
+			&trx.TrxStarted, &trx.TrxSeconds, &trx.RowsLocked, &trx.RowsModified); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		candidates = append(candidates, trx)
+	}
+	rows.Close()
+
+	holders, available := metadataLockHolders(ctx, db, database, table)
+	report.MetadataLocksAvailable = available
+	for _, trx := range candidates {
+		if available {
+			holds := holders[trx.ThreadID]
+			if !holds {
+				continue
+			}
+			trx.HoldsTable = &holds
+		}
+		report.Transactions = append(report.Transactions, trx)
+	}
+
+	// 已在排队等待元数据锁的会话（切换被阻塞时会持续堆积）
+	var waiting int
+	err = db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM information_schema.processlist
+		WHERE state = 'Waiting for table metadata lock' AND info LIKE ?`, "%"+table+"%").Scan(&waiting)
+	if err == nil {
+		report.WaitingSessions = waiting
+	}
+
+	return report, nil
+}
+
+// metadataLockHolders 查询持有或等待目标表元数据锁的会话ID
+func metadataLockHolders(ctx context.Context, db *sql.DB, database, table string) (map[int64]bool, bool) {
+	var enabled string
+	err := db.QueryRowContext(ctx,
+		"SELECT enabled FROM performance_schema.setup_instruments WHERE name = ?", mdlInstrument).Scan(&enabled)
+	if err != nil || !strings.EqualFold(enabled, "YES") {
+		return nil, false
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT t.processlist_id
+		FROM performance_schema.metadata_locks ml
+		JOIN performance_schema.threads t ON t.thread_id = ml.owner_thread_id
+		WHERE ml.object_type = 'TABLE' AND ml.object_schema = ? AND ml.object_name = ? AND t.processlist_id IS NOT NULL`,
+		database, table)
+	if err != nil {
+		return nil, false
+	}
+	defer rows.Close()
+
+	holders := map[int64]bool{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err == nil {
+			holders[id] = true
+		}
+	}
+	return holders, true
+}
+
+// cutoverGuardPluginSource pt-osc 插件：切换前等待目标表上的长事务结束
+// 参数通过环境变量传入：MYSQLER_GUARD_DB / MYSQLER_GUARD_TABLE / MYSQLER_LONG_TRX_SECONDS /
+// MYSQLER_CUTOVER_TIMEOUT / MYSQLER_CUTOVER_ON_TIMEOUT
+const cutoverGuardPluginSource = `package pt_online_schema_change_plugin;
+
+use strict;
+use warnings;
+
+sub new {
+   my ( $class, %args ) = @_;
+   my $self = { %args };
+   return bless $self, $class;
+}
+
+sub before_swap_tables {
+   my ( $self, %args ) = @_;
+   my $dbh        = $self->{cxn}->dbh();
+   my $db         = $ENV{MYSQLER_GUARD_DB};
+   my $tbl        = $ENV{MYSQLER_GUARD_TABLE};
+   my $min_secs   = $ENV{MYSQLER_LONG_TRX_SECONDS} || 60;
+   my $timeout    = $ENV{MYSQLER_CUTOVER_TIMEOUT} || 600;
+   my $on_timeout = $ENV{MYSQLER_CUTOVER_ON_TIMEOUT} || 'proceed';
+   my $deadline   = time + $timeout;
+
+   while ( 1 ) {
+      my $blockers = _blockers($dbh, $db, $tbl, $min_secs);
+      if ( !@$blockers ) {
+         print "MySQLer cut-over guard: no long transactions on $db.$tbl, swapping tables\n";
+         return;
+      }
+      my $desc = join(', ', map { "thread $_->[0] ($_->[1]s)" } @$blockers);
+      if ( time >= $deadline ) {
+         die "MySQLer cut-over guard: timed out after ${timeout}s waiting for long transactions: $desc\n"
+            if $on_timeout eq 'abort';
+         print "MySQLer cut-over guard: timed out after ${timeout}s, swapping tables anyway: $desc\n";
+         return;
+      }
+      print "MySQLer cut-over guard: waiting for long transactions on $db.$tbl: $desc\n";
+      sleep 5;
+   }
+}
+
+sub _blockers {
+   my ( $dbh, $db, $tbl, $min_secs ) = @_;
+   my $trx = $dbh->selectall_arrayref(
+      "SELECT trx_mysql_thread_id, TIMESTAMPDIFF(SECOND, trx_started, NOW()) "
+    . "FROM information_schema.innodb_trx "
+    . "WHERE trx_started <= NOW() - INTERVAL ? SECOND AND trx_mysql_thread_id <> CONNECTION_ID()",
+      undef, $min_secs);
+   return [] unless $trx && @$trx;
+
+   my ($enabled) = eval {
+      $dbh->selectrow_array(
+         "SELECT enabled FROM performance_schema.setup_instruments WHERE name = 'wait/lock/metadata/sql/mdl'");
+   };
+   return $trx unless $enabled && $enabled eq 'YES';
+
+   my $holders = eval {
+      $dbh->selectcol_arrayref(
+         "SELECT t.processlist_id FROM performance_schema.metadata_locks ml "
+       . "JOIN performance_schema.threads t ON t.thread_id = ml.owner_thread_id "
+       . "WHERE ml.object_type = 'TABLE' AND ml.object_schema = ? AND ml.object_name = ?",
+         undef, $db, $tbl);
+   };
+   return $trx unless $holders;
+
+   my %holding = map { $_ => 1 } grep { defined } @$holders;
+   return [ grep { $holding{$_->[0]} } @$trx ];
+}
+
+1;
+`
+
+// PTCutoverGuardEnvironment 切换保护插件源码通过环境变量传入容器
+func PTCutoverGuardEnvironment() map[string]string {
+	return map[string]string{"MYSQLER_CUTOVER_PLUGIN": cutoverGuardPluginSource}
+}
+
+// ptCutoverGuardPreamble 生成容器内写入插件并导出参数的前置脚本
+func ptCutoverGuardPreamble(database, table string, options *PTOptions) string {
+	onTimeout := CutoverTimeoutProceed
+	if options.CutoverOnTimeout == CutoverTimeoutAbort {
+		onTimeout = CutoverTimeoutAbort
+	}
+	parts := []string{
+		fmt.Sprintf("mkdir -p %s", ptGuardDir),
+		fmt.Sprintf(`printf '%%s\n' "$MYSQLER_CUTOVER_PLUGIN" > %s`, ptGuardPlugin),
+		fmt.Sprintf("export MYSQLER_GUARD_DB=%s MYSQLER_GUARD_TABLE=%s MYSQLER_LONG_TRX_SECONDS=%d MYSQLER_CUTOVER_TIMEOUT=%d MYSQLER_CUTOVER_ON_TIMEOUT=%s",
+			shellQuote(database), shellQuote(table), options.LongTrxSeconds, options.CutoverTimeout, onTimeout),
+	}
+	return strings.Join(parts, " && ")
+}
+
+// shellQuote 用单引号包裹参数（内部的单引号按 shell 规则转义）
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,495 @@
+package utils
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// 校验项类型
+const (
+	VerifySchema   = "schema"    // 表结构包含预期变更
+	VerifyRowCount = "row_count" // 新旧表行数一致
+	VerifyChecksum = "checksum"  // 新旧表分块校验和一致
+)
+
+// maxChecksumMismatches 校验和比较最多记录的不一致分块数
+const maxChecksumMismatches = 10
+
+// typeAliases 列类型同义写法（SHOW CREATE TABLE 输出的是右侧形式）
+var typeAliases = map[string]string{
+	"integer": "int",
+	"bool":    "tinyint",
+	"boolean": "tinyint",
+	"dec":     "decimal",
+	"numeric": "decimal",
+	"real":    "double",
+}
+
+// typeBase 列类型开头的类型名
+var typeBase = regexp.MustCompile(`^[a-z]+`)
+
+// VerificationCheck 单项校验结果
+type VerificationCheck struct {
+	Kind    string `json:"kind"`
+	Target  string `json:"target"` // ALTER 子句或表名
+	Passed  bool   `json:"passed"`
+	Skipped bool   `json:"skipped,omitempty"` // 无法校验，不计入警告
+	Message string `json:"message"`
+}
+
+// VerifySchemaChanges 检查执行后的表结构是否包含 ALTER 中的各项变更
+func VerifySchemaChanges(before, after *TableDefinition, alterSQL string) ([]VerificationCheck, error) {
+	cleaned, err := CleanAlterSQL(alterSQL)
+	if err != nil {
+		return nil, err
+	}
+
+	var checks []VerificationCheck
+	for _, clause := range ParseAlterClauses(cleaned) {
+		check := VerificationCheck{Kind: VerifySchema, Target: clause.Raw, Passed: true}
+		if message, skipped := verifyClause(before, after, clause); skipped {
+			check.Skipped = true
+			check.Message = message
+		} else if message != "" {
+			check.Passed = false
+			check.Message = message
+		} else {
+			check.Message = "已生效"
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+// verifyClause 校验单个子句；返回不一致原因，无法校验时 skipped 为 true
+func verifyClause(before, after *TableDefinition, clause *AlterClause) (string, bool) {
+	switch clause.Kind {
+	case AlterAddColumn, AlterModifyColumn:
+		return verifyColumn(after, clause.Name, clause.Definition), false
+
+	case AlterChangeColumn:
+		if !strings.EqualFold(clause.Name, clause.NewName) && after.FindColumn(clause.Name) != nil {
+			return fmt.Sprintf("列 %s 仍然存在", clause.Name), false
+		}
+		return verifyColumn(after, clause.NewName, clause.Definition), false
+
+	case AlterDropColumn:
+		if after.FindColumn(clause.Name) != nil {
+			return fmt.Sprintf("列 %s 仍然存在", clause.Name), false
+		}
+
+	case AlterRenameColumn:
+		if after.FindColumn(clause.NewName) == nil {
+			return fmt.Sprintf("列 %s 不存在", clause.NewName), false
+		}
+		if after.FindColumn(clause.Name) != nil {
+			return fmt.Sprintf("列 %s 仍然存在", clause.Name), false
+		}
+
+	case AlterColumnDefault:
+		if after.FindColumn(clause.Name) == nil {
+			return fmt.Sprintf("列 %s 不存在", clause.Name), false
+		}
+
+	case AlterAddIndex:
+		return verifyIndex(before, after, clause), false
+
+	case AlterAddPrimaryKey:
+		expected := parseIndexElement(clause.Definition)
+		pk := after.PrimaryKey()
+		if pk == nil {
+			return "主键不存在", false
+		}
+		if expected != nil && !sameColumnNames(pk.Columns, expected.Columns) {
+			return fmt.Sprintf("主键列为 (%s)，预期 (%s)", strings.Join(pk.Columns, ","), strings.Join(expected.Columns, ",")), false
+		}
+
+	case AlterDropIndex:
+		if after.FindIndex(clause.Name) != nil {
+			return fmt.Sprintf("索引 %s 仍然存在", clause.Name), false
+		}
+
+	case AlterDropPrimaryKey:
+		if after.PrimaryKey() != nil {
+			return "主键仍然存在", false
+		}
+
+	case AlterRenameIndex:
+		if after.FindIndex(clause.NewName) == nil {
+			return fmt.Sprintf("索引 %s 不存在", clause.NewName), false
+		}
+		if after.FindIndex(clause.Name) != nil {
+			return fmt.Sprintf("索引 %s 仍然存在", clause.Name), false
+		}
+
+	case AlterAddForeignKey:
+		if clause.Name == "" {
+			return "未命名外键无法校验", true
+		}
+		if _, ok := foreignKeyMap(after.Constraints)[clause.Name]; !ok {
+			return fmt.Sprintf("外键 %s 不存在", clause.Name), false
+		}
+
+	case AlterDropForeignKey:
+		if _, ok := foreignKeyMap(after.Constraints)[clause.Name]; ok {
+			return fmt.Sprintf("外键 %s 仍然存在", clause.Name), false
+		}
+
+	case AlterTableOption:
+		// AUTO_INCREMENT 随数据变化，不做比较
+		if clause.Name == "AUTO_INCREMENT" {
+			return "AUTO_INCREMENT 不做校验", true
+		}
+		expected := strings.ToLower(strings.Trim(clause.Definition, "'"))
+		actual := strings.ToLower(after.Options[clause.Name])
+		if actual != expected {
+			return fmt.Sprintf("表选项 %s 为 %q，预期 %q", clause.Name, actual, expected), false
+		}
+
+	default:
+		return "该子句暂不支持自动校验", true
+	}
+	return "", false
+}
+
+// verifyColumn 校验列存在且类型、可空性与声明一致
+func verifyColumn(after *TableDefinition, name, definition string) string {
+	actual := after.FindColumn(name)
+	if actual == nil {
+		return fmt.Sprintf("列 %s 不存在", name)
+	}
+	declared, err := ParseColumnDefinition(QuoteIdentifier(name) + " " + definition)
+	if err != nil || declared.Type == "" {
+		return ""
+	}
+	if expected, got := normalizeColumnType(declared.Type), normalizeColumnType(actual.Type); expected != got {
+		return fmt.Sprintf("列 %s 类型为 %s，预期 %s", name, actual.Type, declared.Type)
+	}
+	if declared.Nullable != actual.Nullable {
+		return fmt.Sprintf("列 %s 可空性与预期不一致", name)
+	}
+	return ""
+}
+
+// verifyIndex 校验新增索引存在且列一致；未命名索引按列匹配执行前不存在的索引
+func verifyIndex(before, after *TableDefinition, clause *AlterClause) string {
+	expected := parseIndexElement(clause.Definition)
+	if clause.Name != "" {
+		index := after.FindIndex(clause.Name)
+		if index == nil {
+			return fmt.Sprintf("索引 %s 不存在", clause.Name)
+		}
+		if expected != nil && !sameColumnNames(index.Columns, expected.Columns) {
+			return fmt.Sprintf("索引 %s 列为 (%s)，预期 (%s)", clause.Name, strings.Join(index.Columns, ","), strings.Join(expected.Columns, ","))
+		}
+		return ""
+	}
+
+	if expected == nil {
+		return ""
+	}
+	for _, index := range after.Indexes {
+		if before != nil && before.FindIndex(index.Name) != nil {
+			continue
+		}
+		if sameColumnNames(index.Columns, expected.Columns) {
+			return ""
+		}
+	}
+	return fmt.Sprintf("未找到列为 (%s) 的新索引", strings.Join(expected.Columns, ","))
+}
+
+// normalizeColumnType 规范化列类型：统一同义写法、补全默认长度并忽略整数显示宽度
+func normalizeColumnType(colType string) string {
+	colType = strings.Join(strings.Fields(strings.ToLower(colType)), " ")
+	base := typeBase.FindString(colType)
+	rest := colType[len(base):]
+	if alias, ok := typeAliases[base]; ok {
+		base = alias
+	}
+	switch {
+	case base == "decimal" && !strings.HasPrefix(rest, "("):
+		rest = "(10,0)" + rest
+	case base == "char" && !strings.HasPrefix(rest, "("):
+		rest = "(1)" + rest
+	}
+	return intDisplayWidth.ReplaceAllString(base+strings.ReplaceAll(rest, ", ", ","), "$1")
+}
+
+// sameColumnNames 列名列表是否一致（忽略大小写）
+func sameColumnNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !strings.EqualFold(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// CompareRowCounts 在同一一致性快照中统计新旧表行数
+// pk 非空时新表只统计旧表主键范围内的行，切换后新增的行单独说明、不计为差异
+func CompareRowCounts(conn *DatabaseConnection, database, oldTable, newTable string, pk []string) (*VerificationCheck, error) {
+	db, err := openDatabase(conn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	oldRef := QuoteIdentifier(database) + "." + QuoteIdentifier(oldTable)
+	newRef := QuoteIdentifier(database) + "." + QuoteIdentifier(newTable)
+
+	var oldRows, newRows, laterRows int64
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+oldRef).Scan(&oldRows); err != nil {
+		return nil, fmt.Errorf("统计旧表行数失败: %v", err)
+	}
+
+	// 旧表切换后不再写入，其最大主键即切换时的范围上界
+	var maxKey []interface{}
+	if len(pk) > 0 {
+		if maxKey, err = lastKey(ctx, tx, oldRef, pk); err != nil {
+			return nil, fmt.Errorf("读取旧表最大主键失败: %v", err)
+		}
+	}
+
+	switch {
+	case len(pk) == 0:
+		err = tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+newRef).Scan(&newRows)
+	case maxKey == nil:
+		err = tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+newRef).Scan(&laterRows)
+	default:
+		var total int64
+		query := fmt.Sprintf("SELECT COUNT(*), COALESCE(SUM(%s > %s), 0) FROM %s", pkTuple(pk), pkPlaceholders(len(pk)), newRef)
+		err = tx.QueryRowContext(ctx, query, maxKey...).Scan(&total, &laterRows)
+		newRows = total - laterRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("统计新表行数失败: %v", err)
+	}
+
+	check := &VerificationCheck{
+		Kind:    VerifyRowCount,
+		Target:  fmt.Sprintf("%s / %s", oldTable, newTable),
+		Passed:  oldRows == newRows,
+		Message: fmt.Sprintf("旧表 %d 行，新表 %d 行", oldRows, newRows),
+	}
+	if len(pk) > 0 {
+		check.Message = fmt.Sprintf("旧表 %d 行，新表主键范围内 %d 行", oldRows, newRows)
+		if laterRows > 0 {
+			check.Message += fmt.Sprintf("；切换后新增 %d 行，不计入比较", laterRows)
+		}
+	}
+	if !check.Passed {
+		check.Message += "（切换后的更新与删除也会造成差异）"
+	}
+	return check, nil
+}
+
+// CompareChecksums 按主键分块比较新旧表的校验和
+// columns 为参与比较的列（应排除类型发生变化的列），pk 为主键列；
+// 只比较旧表主键范围，切换后新增的行单独说明、不计为差异
+func CompareChecksums(conn *DatabaseConnection, database, oldTable, newTable string, columns, pk []string, chunkSize int) (*VerificationCheck, error) {
+	if len(pk) == 0 || len(columns) == 0 {
+		return &VerificationCheck{Kind: VerifyChecksum, Target: oldTable, Passed: true, Skipped: true, Message: "表没有主键或可比较的列，跳过校验和比较"}, nil
+	}
+	if chunkSize <= 0 {
+		chunkSize = 10000
+	}
+
+	db, err := openDatabase(conn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	ctx := context.Background()
+
+	quote := func(names []string) string {
+		quoted := make([]string, len(names))
+		for i, name := range names {
+			quoted[i] = QuoteIdentifier(name)
+		}
+		return strings.Join(quoted, ", ")
+	}
+	pkList := pkTuple(pk)
+	placeholders := pkPlaceholders(len(pk))
+	oldRef := QuoteIdentifier(database) + "." + QuoteIdentifier(oldTable)
+	newRef := QuoteIdentifier(database) + "." + QuoteIdentifier(newTable)
+
+	// 逐行计算 CRC32 后异或聚合；ISNULL 标记区分 NULL 与空串
+	nullFlags := make([]string, len(columns))
+	for i, column := range columns {
+		nullFlags[i] = "ISNULL(" + QuoteIdentifier(column) + ")"
+	}
+	rowHash := fmt.Sprintf("CRC32(CONCAT_WS('#', %s, CONCAT(%s)))", quote(columns), strings.Join(nullFlags, ", "))
+
+	checksum := func(table, where string, args []interface{}) (int64, string, error) {
+		var count int64
+		var sum sql.NullString
+		query := fmt.Sprintf("SELECT COUNT(*), BIT_XOR(%s) FROM %s WHERE %s", rowHash, table, where)
+		err := db.QueryRowContext(ctx, query, args...).Scan(&count, &sum)
+		return count, sum.String, err
+	}
+
+	check := &VerificationCheck{Kind: VerifyChecksum, Target: fmt.Sprintf("%s / %s", oldTable, newTable), Passed: true}
+
+	// 最后一个分块以旧表最大主键为上界，避免计入切换后新增的行
+	maxKey, err := lastKey(ctx, db, oldRef, pk)
+	if err != nil {
+		return nil, fmt.Errorf("读取旧表最大主键失败: %v", err)
+	}
+	if maxKey == nil {
+		check.Skipped = true
+		check.Message = "旧表为空，跳过校验和比较"
+		return check, nil
+	}
+
+	var lower []interface{}
+	var chunks, mismatched int
+	var details []string
+	for {
+		// 旧表切换后不再写入，以其主键确定分块上界
+		boundaryWhere, boundaryArgs := "1 = 1", []interface{}{}
+		if lower != nil {
+			boundaryWhere, boundaryArgs = pkList+" > "+placeholders, lower
+		}
+		upper, err := chunkBoundary(ctx, db, fmt.Sprintf("SELECT %s FROM %s WHERE %s ORDER BY %s LIMIT 1 OFFSET %d",
+			quote(pk), oldRef, boundaryWhere, quote(pk), chunkSize-1), boundaryArgs, len(pk))
+		if err != nil {
+			return nil, fmt.Errorf("计算分块边界失败: %v", err)
+		}
+		last := upper == nil
+		if last {
+			upper = maxKey
+		}
+
+		where, args := boundaryWhere, append([]interface{}{}, boundaryArgs...)
+		if upper != nil {
+			where += " AND " + pkList + " <= " + placeholders
+			args = append(args, upper...)
+		}
+
+		oldCount, oldSum, err := checksum(oldRef, where, args)
+		if err != nil {
+			return nil, fmt.Errorf("计算旧表校验和失败: %v", err)
+		}
+		newCount, newSum, err := checksum(newRef, where, args)
+		if err != nil {
+			return nil, fmt.Errorf("计算新表校验和失败: %v", err)
+		}
+		chunks++
+		if oldCount != newCount || oldSum != newSum {
+			mismatched++
+			if len(details) < maxChecksumMismatches {
+				details = append(details, fmt.Sprintf("%s ~ %s（旧表 %d 行，新表 %d 行）",
+					formatBoundary(lower, "起始"), formatBoundary(upper, "末尾"), oldCount, newCount))
+			}
+		}
+
+		if last {
+			break
+		}
+		lower = upper
+	}
+
+	var laterRows int64
+	if err := db.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s > %s", newRef, pkList, placeholders), maxKey...).Scan(&laterRows); err != nil {
+		return nil, fmt.Errorf("统计切换后新增行数失败: %v", err)
+	}
+
+	check.Passed = mismatched == 0
+	check.Message = fmt.Sprintf("共比较 %d 个分块", chunks)
+	if mismatched > 0 {
+		check.Message += fmt.Sprintf("，%d 个分块不一致: %s", mismatched, strings.Join(details, "; "))
+	}
+	if laterRows > 0 {
+		check.Message += fmt.Sprintf("；切换后新增 %d 行，不计入比较", laterRows)
+	}
+	return check, nil
+}
+
+// rowQueryer 可执行查询的连接或事务
+type rowQueryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// lastKey 读取表中最大的主键值，表为空时返回 nil
+func lastKey(ctx context.Context, db rowQueryer, table string, pk []string) ([]interface{}, error) {
+	order := make([]string, len(pk))
+	for i, column := range pk {
+		order[i] = QuoteIdentifier(column) + " DESC"
+	}
+	return chunkBoundary(ctx, db, fmt.Sprintf("SELECT %s FROM %s ORDER BY %s LIMIT 1",
+		pkColumns(pk), table, strings.Join(order, ", ")), nil, len(pk))
+}
+
+// pkColumns 逗号分隔的主键列
+func pkColumns(pk []string) string {
+	quoted := make([]string, len(pk))
+	for i, column := range pk {
+		quoted[i] = QuoteIdentifier(column)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// pkTuple 主键列的行构造表达式
+func pkTuple(pk []string) string {
+	return "(" + pkColumns(pk) + ")"
+}
+
+// pkPlaceholders 与主键列数一致的行构造占位符
+func pkPlaceholders(width int) string {
+	return "(?" + strings.Repeat(", ?", width-1) + ")"
+}
+
+// chunkBoundary 读取分块上界的主键值，没有更多行时返回 nil
+func chunkBoundary(ctx context.Context, db rowQueryer, query string, args []interface{}, width int) ([]interface{}, error) {
+	values := make([]sql.RawBytes, width)
+	dest := make([]interface{}, width)
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	boundary := make([]interface{}, width)
+	for i, value := range values {
+		boundary[i] = string(value)
+	}
+	return boundary, nil
+}
+
+// formatBoundary 分块边界的可读形式
+func formatBoundary(boundary []interface{}, open string) string {
+	if boundary == nil {
+		return open
+	}
+	parts := make([]string, len(boundary))
+	for i, value := range boundary {
+		parts[i] = fmt.Sprint(value)
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}